@@ -12,18 +12,23 @@ import (
 	aws_auth "github.com/dapr/components-contrib/authentication/aws"
 
 	"github.com/aws/aws-sdk-go/service/s3/s3manager"
+	"github.com/aws/aws-sdk-go/service/s3/s3manager/s3manageriface"
 	"github.com/google/uuid"
 
 	"github.com/aws/aws-sdk-go/aws"
 	"github.com/dapr/components-contrib/bindings"
+	"github.com/dapr/components-contrib/componenterrors"
 	"github.com/dapr/dapr/pkg/logger"
 )
 
 // AWSS3 is a binding for an AWS S3 storage bucket
 type AWSS3 struct {
 	metadata *s3Metadata
-	uploader *s3manager.Uploader
+	uploader s3manageriface.UploaderAPI
 	logger   logger.Logger
+
+	// newUploader creates the uploader in Init, tests replace it with a fake
+	newUploader func(metadata *s3Metadata) (s3manageriface.UploaderAPI, error)
 }
 
 type s3Metadata struct {
@@ -36,7 +41,7 @@ type s3Metadata struct {
 
 // NewAWSS3 returns a new AWSS3 instance
 func NewAWSS3(logger logger.Logger) *AWSS3 {
-	return &AWSS3{logger: logger}
+	return &AWSS3{logger: logger, newUploader: getUploader}
 }
 
 // Init does metadata parsing and connection creation
@@ -45,7 +50,7 @@ func (s *AWSS3) Init(metadata bindings.Metadata) error {
 	if err != nil {
 		return err
 	}
-	uploader, err := s.getClient(m)
+	uploader, err := s.newUploader(m)
 	if err != nil {
 		return err
 	}
@@ -73,7 +78,7 @@ func (s *AWSS3) Invoke(req *bindings.InvokeRequest) (*bindings.InvokeResponse, e
 		Key:    aws.String(key),
 		Body:   r,
 	})
-	return nil, err
+	return nil, componenterrors.FromAWS(err)
 }
 
 func (s *AWSS3) parseMetadata(metadata bindings.Metadata) (*s3Metadata, error) {
@@ -90,7 +95,7 @@ func (s *AWSS3) parseMetadata(metadata bindings.Metadata) (*s3Metadata, error) {
 	return &m, nil
 }
 
-func (s *AWSS3) getClient(metadata *s3Metadata) (*s3manager.Uploader, error) {
+func getUploader(metadata *s3Metadata) (s3manageriface.UploaderAPI, error) {
 	sess, err := aws_auth.GetClient(metadata.AccessKey, metadata.SecretKey, metadata.Region, metadata.Endpoint)
 	if err != nil {
 		return nil, err
@@ -6,9 +6,15 @@
 package s3
 
 import (
+	"errors"
 	"testing"
 
+	"github.com/aws/aws-sdk-go/aws/awserr"
+	"github.com/aws/aws-sdk-go/service/s3/s3manager/s3manageriface"
 	"github.com/dapr/components-contrib/bindings"
+	"github.com/dapr/components-contrib/componenterrors"
+	fakes "github.com/dapr/components-contrib/testing"
+	"github.com/dapr/dapr/pkg/logger"
 	"github.com/stretchr/testify/assert"
 )
 
@@ -24,3 +30,42 @@ func TestParseMetadata(t *testing.T) {
 	assert.Equal(t, "test", meta.Bucket)
 	assert.Equal(t, "endpoint", meta.Endpoint)
 }
+
+func TestInvoke(t *testing.T) {
+	uploader := fakes.NewS3Uploader("test")
+	s := NewAWSS3(logger.NewLogger("test"))
+	s.newUploader = func(metadata *s3Metadata) (s3manageriface.UploaderAPI, error) {
+		assert.Equal(t, "region", metadata.Region)
+		return uploader, nil
+	}
+	err := s.Init(bindings.Metadata{Properties: map[string]string{"region": "region", "bucket": "test"}})
+	assert.Nil(t, err)
+
+	_, err = s.Invoke(&bindings.InvokeRequest{Data: []byte("data"), Metadata: map[string]string{"key": "object"}})
+	assert.Nil(t, err)
+	b, ok := uploader.Object("test", "object")
+	assert.True(t, ok)
+	assert.Equal(t, "data", string(b))
+
+	t.Run("objects without a key get a generated key", func(t *testing.T) {
+		_, err := s.Invoke(&bindings.InvokeRequest{Data: []byte("data")})
+		assert.Nil(t, err)
+		assert.Len(t, uploader.Objects("test"), 2)
+	})
+
+	t.Run("upload errors are returned", func(t *testing.T) {
+		uploader.FailNext("Upload", errors.New("unavailable"))
+		_, err := s.Invoke(&bindings.InvokeRequest{Data: []byte("data")})
+		assert.EqualError(t, err, "unavailable")
+	})
+
+	t.Run("upload errors are classified", func(t *testing.T) {
+		uploader.FailNext("Upload", awserr.New("SlowDown", "reduce your request rate", nil))
+		_, err := s.Invoke(&bindings.InvokeRequest{Data: []byte("data")})
+		assert.True(t, componenterrors.IsThrottled(err))
+
+		s.metadata.Bucket = "missing"
+		_, err = s.Invoke(&bindings.InvokeRequest{Data: []byte("data")})
+		assert.True(t, componenterrors.IsPermanent(err))
+	})
+}
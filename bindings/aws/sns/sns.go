@@ -14,15 +14,20 @@ import (
 	"github.com/dapr/dapr/pkg/logger"
 
 	"github.com/aws/aws-sdk-go/service/sns"
+	"github.com/aws/aws-sdk-go/service/sns/snsiface"
 	"github.com/dapr/components-contrib/bindings"
+	"github.com/dapr/components-contrib/componenterrors"
 )
 
 // AWSSNS is an AWS SNS binding
 type AWSSNS struct {
-	client   *sns.SNS
+	client   snsiface.SNSAPI
 	topicARN string
 
 	logger logger.Logger
+
+	// newClient creates the client in Init, tests replace it with a fake
+	newClient func(metadata *snsMetadata) (snsiface.SNSAPI, error)
 }
 
 type snsMetadata struct {
@@ -40,7 +45,7 @@ type dataPayload struct {
 
 // NewAWSSNS creates a new AWSSNS binding instance
 func NewAWSSNS(logger logger.Logger) *AWSSNS {
-	return &AWSSNS{logger: logger}
+	return &AWSSNS{logger: logger, newClient: getClient}
 }
 
 // Init does metadata parsing
@@ -49,7 +54,7 @@ func (a *AWSSNS) Init(metadata bindings.Metadata) error {
 	if err != nil {
 		return err
 	}
-	client, err := a.getClient(m)
+	client, err := a.newClient(m)
 	if err != nil {
 		return err
 	}
@@ -72,7 +77,7 @@ func (a *AWSSNS) parseMetadata(metadata bindings.Metadata) (*snsMetadata, error)
 	return &m, nil
 }
 
-func (a *AWSSNS) getClient(metadata *snsMetadata) (*sns.SNS, error) {
+func getClient(metadata *snsMetadata) (snsiface.SNSAPI, error) {
 	sess, err := aws_auth.GetClient(metadata.AccessKey, metadata.SecretKey, metadata.Region, metadata.Endpoint)
 	if err != nil {
 		return nil, err
@@ -103,7 +108,7 @@ func (a *AWSSNS) Invoke(req *bindings.InvokeRequest) (*bindings.InvokeResponse,
 
 	_, err = a.client.Publish(input)
 	if err != nil {
-		return nil, err
+		return nil, componenterrors.FromAWS(err)
 	}
 	return nil, nil
 }
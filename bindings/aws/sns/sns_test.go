@@ -8,7 +8,12 @@ package sns
 import (
 	"testing"
 
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/service/sns/snsiface"
 	"github.com/dapr/components-contrib/bindings"
+	"github.com/dapr/components-contrib/componenterrors"
+	fakes "github.com/dapr/components-contrib/testing"
+	"github.com/dapr/dapr/pkg/logger"
 	"github.com/stretchr/testify/assert"
 )
 
@@ -24,3 +29,32 @@ func TestParseMetadata(t *testing.T) {
 	assert.Equal(t, "a", snsM.SecretKey)
 	assert.Equal(t, "a", snsM.Endpoint)
 }
+
+func TestInvoke(t *testing.T) {
+	client := fakes.NewSNS("arn:topic")
+	s := NewAWSSNS(logger.NewLogger("test"))
+	s.newClient = func(metadata *snsMetadata) (snsiface.SNSAPI, error) {
+		return client, nil
+	}
+	err := s.Init(bindings.Metadata{Properties: map[string]string{"topicArn": "arn:topic"}})
+	assert.Nil(t, err)
+
+	_, err = s.Invoke(&bindings.InvokeRequest{Data: []byte(`{"message":"hello","subject":"greeting"}`)})
+	assert.Nil(t, err)
+	published := client.Published("arn:topic")
+	assert.Len(t, published, 1)
+	assert.Equal(t, "hello", aws.StringValue(published[0].Message))
+	assert.Equal(t, "greeting", aws.StringValue(published[0].Subject))
+
+	t.Run("invalid payloads aren't published", func(t *testing.T) {
+		_, err := s.Invoke(&bindings.InvokeRequest{Data: []byte("hello")})
+		assert.NotNil(t, err)
+		assert.Len(t, client.Published("arn:topic"), 1)
+	})
+
+	t.Run("unknown topics fail", func(t *testing.T) {
+		s.topicARN = "arn:other"
+		_, err := s.Invoke(&bindings.InvokeRequest{Data: []byte(`{"message":"hello"}`)})
+		assert.True(t, componenterrors.IsPermanent(err))
+	})
+}
@@ -11,17 +11,22 @@ import (
 
 	"github.com/aws/aws-sdk-go/aws"
 	"github.com/aws/aws-sdk-go/service/sqs"
+	"github.com/aws/aws-sdk-go/service/sqs/sqsiface"
 	aws_auth "github.com/dapr/components-contrib/authentication/aws"
 	"github.com/dapr/components-contrib/bindings"
+	"github.com/dapr/components-contrib/componenterrors"
 	"github.com/dapr/dapr/pkg/logger"
 )
 
 // AWSSQS allows receiving and sending data to/from AWS SQS
 type AWSSQS struct {
-	Client   *sqs.SQS
+	Client   sqsiface.SQSAPI
 	QueueURL *string
 
 	logger logger.Logger
+
+	// newClient creates the client in Init, tests replace it with a fake
+	newClient func(metadata *sqsMetadata) (sqsiface.SQSAPI, error)
 }
 
 type sqsMetadata struct {
@@ -34,7 +39,7 @@ type sqsMetadata struct {
 
 // NewAWSSQS returns a new AWS SQS instance
 func NewAWSSQS(logger logger.Logger) *AWSSQS {
-	return &AWSSQS{}
+	return &AWSSQS{logger: logger, newClient: getClient}
 }
 
 // Init does metadata parsing and connection creation
@@ -44,7 +49,7 @@ func (a *AWSSQS) Init(metadata bindings.Metadata) error {
 		return err
 	}
 
-	client, err := a.getClient(m)
+	client, err := a.newClient(m)
 	if err != nil {
 		return err
 	}
@@ -54,7 +59,7 @@ func (a *AWSSQS) Init(metadata bindings.Metadata) error {
 		QueueName: aws.String(queueName),
 	})
 	if err != nil {
-		return err
+		return componenterrors.FromAWS(err)
 	}
 
 	a.QueueURL = resultURL.QueueUrl
@@ -72,7 +77,7 @@ func (a *AWSSQS) Invoke(req *bindings.InvokeRequest) (*bindings.InvokeResponse,
 		MessageBody: &msgBody,
 		QueueUrl:    a.QueueURL,
 	})
-	return nil, err
+	return nil, componenterrors.FromAWS(err)
 }
 
 func (a *AWSSQS) Read(handler func(*bindings.ReadResponse) error) error {
@@ -90,9 +95,7 @@ func (a *AWSSQS) Read(handler func(*bindings.ReadResponse) error) error {
 		})
 		if err != nil {
 			a.logger.Errorf("Unable to receive message from queue %q, %v.", *a.QueueURL, err)
-		}
-
-		if len(result.Messages) > 0 {
+		} else if len(result.Messages) > 0 {
 			for _, m := range result.Messages {
 				body := m.Body
 				res := bindings.ReadResponse{
@@ -102,10 +105,13 @@ func (a *AWSSQS) Read(handler func(*bindings.ReadResponse) error) error {
 				if err == nil {
 					msgHandle := m.ReceiptHandle
 
-					a.Client.DeleteMessage(&sqs.DeleteMessageInput{
+					_, err = a.Client.DeleteMessage(&sqs.DeleteMessageInput{
 						QueueUrl:      a.QueueURL,
 						ReceiptHandle: msgHandle,
 					})
+					if err != nil {
+						a.logger.Errorf("Unable to delete message %s from queue %q, %v.", *m.MessageId, *a.QueueURL, err)
+					}
 				}
 			}
 		}
@@ -128,7 +134,7 @@ func (a *AWSSQS) parseSQSMetadata(metadata bindings.Metadata) (*sqsMetadata, err
 	return &m, nil
 }
 
-func (a *AWSSQS) getClient(metadata *sqsMetadata) (*sqs.SQS, error) {
+func getClient(metadata *sqsMetadata) (sqsiface.SQSAPI, error) {
 	sess, err := aws_auth.GetClient(metadata.AccessKey, metadata.SecretKey, metadata.Region, metadata.Endpoint)
 	if err != nil {
 		return nil, err
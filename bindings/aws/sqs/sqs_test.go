@@ -6,9 +6,15 @@
 package sqs
 
 import (
+	"errors"
 	"testing"
+	"time"
 
+	"github.com/aws/aws-sdk-go/service/sqs/sqsiface"
 	"github.com/dapr/components-contrib/bindings"
+	"github.com/dapr/components-contrib/componenterrors"
+	fakes "github.com/dapr/components-contrib/testing"
+	"github.com/dapr/dapr/pkg/logger"
 	"github.com/stretchr/testify/assert"
 )
 
@@ -24,3 +30,57 @@ func TestParseMetadata(t *testing.T) {
 	assert.Equal(t, "a", sqsM.SecretKey)
 	assert.Equal(t, "a", sqsM.Endpoint)
 }
+
+func newTestSQS(client *fakes.SQS) *AWSSQS {
+	a := NewAWSSQS(logger.NewLogger("test"))
+	a.newClient = func(metadata *sqsMetadata) (sqsiface.SQSAPI, error) {
+		return client, nil
+	}
+	return a
+}
+
+func TestInit(t *testing.T) {
+	a := newTestSQS(fakes.NewSQS("queue"))
+	assert.Nil(t, a.Init(bindings.Metadata{Properties: map[string]string{"queueName": "queue"}}))
+	assert.Contains(t, *a.QueueURL, "queue")
+
+	a = newTestSQS(fakes.NewSQS("queue"))
+	err := a.Init(bindings.Metadata{Properties: map[string]string{"queueName": "missing"}})
+	assert.True(t, componenterrors.IsPermanent(err))
+}
+
+func TestRead(t *testing.T) {
+	client := fakes.NewSQS("queue")
+	client.VisibilityTimeout = time.Millisecond * 100
+	a := newTestSQS(client)
+	assert.Nil(t, a.Init(bindings.Metadata{Properties: map[string]string{"queueName": "queue"}}))
+
+	_, err := a.Invoke(&bindings.InvokeRequest{Data: []byte("processed")})
+	assert.Nil(t, err)
+	client.Send("queue", "failed")
+	// Receive errors are retried
+	client.FailNext("ReceiveMessage", errors.New("unavailable"))
+
+	received := make(chan string, 10)
+	go a.Read(func(res *bindings.ReadResponse) error { //nolint:errcheck
+		received <- string(res.Data)
+		if string(res.Data) == "failed" {
+			return errors.New("handler error")
+		}
+		return nil
+	})
+
+	deliveries := map[string]int{}
+	timeout := time.After(time.Second * 5)
+	for deliveries["failed"] < 2 {
+		select {
+		case data := <-received:
+			deliveries[data]++
+		case <-timeout:
+			t.Fatal("timeout waiting for messages")
+		}
+	}
+	// Messages are deleted when handled, and redelivered otherwise
+	assert.Equal(t, 1, deliveries["processed"])
+	assert.Equal(t, []string{"failed"}, client.Messages("queue"))
+}
@@ -12,17 +12,28 @@ import (
 
 	"github.com/a8m/documentdb"
 	"github.com/dapr/components-contrib/bindings"
+	"github.com/dapr/components-contrib/componenterrors"
 	"github.com/dapr/dapr/pkg/logger"
 )
 
 // CosmosDB allows performing state operations on collections
 type CosmosDB struct {
-	client       *documentdb.DocumentDB
+	client       documentDB
 	collection   *documentdb.Collection
 	db           *documentdb.Database
 	partitionKey string
 
 	logger logger.Logger
+
+	// newClient creates the client in Init, tests replace it with a fake
+	newClient func(metadata *cosmosDBCredentials) documentDB
+}
+
+// documentDB is the subset of the CosmosDB client used by the binding
+type documentDB interface {
+	QueryDatabases(query *documentdb.Query, opts ...documentdb.CallOption) (documentdb.Databases, error)
+	QueryCollections(db string, query *documentdb.Query, opts ...documentdb.CallOption) ([]documentdb.Collection, error)
+	CreateDocument(coll string, doc interface{}, opts ...documentdb.CallOption) (*documentdb.Response, error)
 }
 
 type cosmosDBCredentials struct {
@@ -35,7 +46,7 @@ type cosmosDBCredentials struct {
 
 // NewCosmosDB returns a new CosmosDB instance
 func NewCosmosDB(logger logger.Logger) *CosmosDB {
-	return &CosmosDB{logger: logger}
+	return &CosmosDB{logger: logger, newClient: newDocumentDB}
 }
 
 // Init performs CosmosDB connection parsing and connecting
@@ -46,11 +57,7 @@ func (c *CosmosDB) Init(metadata bindings.Metadata) error {
 	}
 
 	c.partitionKey = m.PartitionKey
-	client := documentdb.New(m.URL, &documentdb.Config{
-		MasterKey: &documentdb.Key{
-			Key: m.MasterKey,
-		},
-	})
+	client := c.newClient(m)
 
 	dbs, err := client.QueryDatabases(&documentdb.Query{
 		Query: "SELECT * FROM ROOT r WHERE r.id=@id",
@@ -59,7 +66,7 @@ func (c *CosmosDB) Init(metadata bindings.Metadata) error {
 		},
 	})
 	if err != nil {
-		return err
+		return componenterrors.FromAzure(err)
 	} else if len(dbs) == 0 {
 		return fmt.Errorf("database %s for CosmosDB state store not found", m.Database)
 	}
@@ -72,7 +79,7 @@ func (c *CosmosDB) Init(metadata bindings.Metadata) error {
 		},
 	})
 	if err != nil {
-		return err
+		return componenterrors.FromAzure(err)
 	} else if len(colls) == 0 {
 		return fmt.Errorf("collection %s for CosmosDB state store not found", m.Collection)
 	}
@@ -82,6 +89,14 @@ func (c *CosmosDB) Init(metadata bindings.Metadata) error {
 	return nil
 }
 
+func newDocumentDB(metadata *cosmosDBCredentials) documentDB {
+	return documentdb.New(metadata.URL, &documentdb.Config{
+		MasterKey: &documentdb.Key{
+			Key: metadata.MasterKey,
+		},
+	})
+}
+
 func (c *CosmosDB) parseMetadata(metadata bindings.Metadata) (*cosmosDBCredentials, error) {
 	connInfo := metadata.Properties
 	b, err := json.Marshal(connInfo)
@@ -115,14 +130,19 @@ func (c *CosmosDB) Invoke(req *bindings.InvokeRequest) (*bindings.InvokeResponse
 
 	_, err = c.client.CreateDocument(c.collection.Self, obj, documentdb.PartitionKey(val))
 	if err != nil {
-		return nil, err
+		return nil, componenterrors.FromAzure(err)
 	}
 
 	return nil, nil
 }
 
 func (c *CosmosDB) getPartitionKeyValue(key string, obj interface{}) (interface{}, error) {
-	val, err := c.lookup(obj.(map[string]interface{}), strings.Split(key, "."))
+	m, ok := obj.(map[string]interface{})
+	if !ok {
+		return nil, fmt.Errorf("request body isn't a JSON object")
+	}
+
+	val, err := c.lookup(m, strings.Split(key, "."))
 	if err != nil {
 		return nil, fmt.Errorf("missing partitionKey field %s from request body - %s", c.partitionKey, err)
 	}
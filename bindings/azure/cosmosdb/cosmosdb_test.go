@@ -7,9 +7,13 @@ package cosmosdb
 
 import (
 	"encoding/json"
+	"errors"
 	"testing"
 
+	"github.com/a8m/documentdb"
 	"github.com/dapr/components-contrib/bindings"
+	"github.com/dapr/components-contrib/componenterrors"
+	fakes "github.com/dapr/components-contrib/testing"
 	"github.com/dapr/dapr/pkg/logger"
 	"github.com/stretchr/testify/assert"
 )
@@ -66,3 +70,46 @@ func TestPartitionKeyValue(t *testing.T) {
 	_, err = cosmosDB.getPartitionKeyValue("", obj)
 	assert.NotNil(t, err)
 }
+
+func TestInvoke(t *testing.T) {
+	client := fakes.NewCosmosDB()
+	client.AddCollection("db", "orders")
+	cosmosDB := NewCosmosDB(logger.NewLogger("test"))
+	cosmosDB.newClient = func(metadata *cosmosDBCredentials) documentDB {
+		return client
+	}
+
+	m := bindings.Metadata{Properties: map[string]string{"database": "db", "collection": "missing", "partitionKey": "customer.id"}}
+	assert.NotNil(t, cosmosDB.Init(m))
+	m.Properties["collection"] = "orders"
+	assert.Nil(t, cosmosDB.Init(m))
+
+	_, err := cosmosDB.Invoke(&bindings.InvokeRequest{Data: []byte(`{"id":"1","customer":{"id":"c1"}}`)})
+	assert.Nil(t, err)
+	doc, partitionKey, ok := client.Document("db", "orders", "1")
+	assert.True(t, ok)
+	assert.Equal(t, "c1", partitionKey)
+	assert.Equal(t, map[string]interface{}{"id": "c1"}, doc["customer"])
+
+	t.Run("documents without the partition key are rejected", func(t *testing.T) {
+		_, err := cosmosDB.Invoke(&bindings.InvokeRequest{Data: []byte(`{"id":"2"}`)})
+		assert.NotNil(t, err)
+		_, err = cosmosDB.Invoke(&bindings.InvokeRequest{Data: []byte(`["id"]`)})
+		assert.NotNil(t, err)
+	})
+
+	t.Run("client errors are returned", func(t *testing.T) {
+		client.FailNext("CreateDocument", errors.New("unavailable"))
+		_, err := cosmosDB.Invoke(&bindings.InvokeRequest{Data: []byte(`{"id":"3","customer":{"id":"c1"}}`)})
+		assert.EqualError(t, err, "unavailable")
+	})
+
+	t.Run("client errors are classified", func(t *testing.T) {
+		client.FailNext("CreateDocument", &documentdb.RequestError{Code: "TooManyRequests", Message: "request rate is large"})
+		_, err := cosmosDB.Invoke(&bindings.InvokeRequest{Data: []byte(`{"id":"4","customer":{"id":"c1"}}`)})
+		assert.True(t, componenterrors.IsThrottled(err))
+
+		_, err = cosmosDB.Invoke(&bindings.InvokeRequest{Data: []byte(`{"id":"1","customer":{"id":"c1"}}`)})
+		assert.True(t, componenterrors.IsPermanent(err))
+	})
+}
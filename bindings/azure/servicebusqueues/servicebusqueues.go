@@ -12,6 +12,7 @@ import (
 
 	servicebus "github.com/Azure/azure-service-bus-go"
 	"github.com/dapr/components-contrib/bindings"
+	"github.com/dapr/components-contrib/componenterrors"
 	"github.com/dapr/dapr/pkg/logger"
 )
 
@@ -27,9 +28,25 @@ const (
 // AzureServiceBusQueues is an input/output binding reading from and sending events to Azure Service Bus queues
 type AzureServiceBusQueues struct {
 	metadata *serviceBusQueuesMetadata
-	client   *servicebus.Queue
+	client   queue
 
 	logger logger.Logger
+
+	// newQueue creates the client in Init, tests replace it with a fake
+	newQueue func(metadata *serviceBusQueuesMetadata) (queue, error)
+}
+
+// queue is the Service Bus queue client used by the binding
+type queue interface {
+	Send(ctx context.Context, msg *servicebus.Message) error
+	Receive(ctx context.Context, handler servicebus.Handler) error
+	Complete(ctx context.Context, msg *servicebus.Message) error
+	Abandon(ctx context.Context, msg *servicebus.Message) error
+}
+
+// serviceBusQueue settles the messages of a Service Bus queue
+type serviceBusQueue struct {
+	*servicebus.Queue
 }
 
 type serviceBusQueuesMetadata struct {
@@ -40,7 +57,7 @@ type serviceBusQueuesMetadata struct {
 
 // NewAzureServiceBusQueues returns a new AzureServiceBusQueues instance
 func NewAzureServiceBusQueues(logger logger.Logger) *AzureServiceBusQueues {
-	return &AzureServiceBusQueues{logger: logger}
+	return &AzureServiceBusQueues{logger: logger, newQueue: getQueue}
 }
 
 // Init parses connection properties and creates a new Service Bus Queue client
@@ -51,9 +68,19 @@ func (a *AzureServiceBusQueues) Init(metadata bindings.Metadata) error {
 	}
 	a.metadata = meta
 
-	ns, err := servicebus.NewNamespace(servicebus.NamespaceWithConnectionString(a.metadata.ConnectionString))
+	client, err := a.newQueue(meta)
 	if err != nil {
-		return err
+		return componenterrors.FromAzure(err)
+	}
+	a.client = client
+	return nil
+}
+
+// getQueue returns a client of the queue, which is created if it does not exist
+func getQueue(metadata *serviceBusQueuesMetadata) (queue, error) {
+	ns, err := servicebus.NewNamespace(servicebus.NamespaceWithConnectionString(metadata.ConnectionString))
+	if err != nil {
+		return nil, err
 	}
 
 	qm := ns.NewQueueManager()
@@ -62,12 +89,12 @@ func (a *AzureServiceBusQueues) Init(metadata bindings.Metadata) error {
 
 	queues, err := qm.List(ctx)
 	if err != nil {
-		return err
+		return nil, err
 	}
 
 	var entity *servicebus.QueueEntity
 	for _, q := range queues {
-		if q.Name == a.metadata.QueueName {
+		if q.Name == metadata.QueueName {
 			entity = q
 			break
 		}
@@ -75,29 +102,27 @@ func (a *AzureServiceBusQueues) Init(metadata bindings.Metadata) error {
 
 	// Create queue if it does not exist
 	if entity == nil {
-		var ttl time.Duration
-		var ok bool
-		ttl, ok, err = bindings.TryGetTTL(metadata.Properties)
+		entity, err = qm.Put(ctx, metadata.QueueName, servicebus.QueueEntityWithMessageTimeToLive(&metadata.ttl))
 		if err != nil {
-			return err
-		}
-
-		if !ok {
-			ttl = a.metadata.ttl
-		}
-
-		entity, err = qm.Put(ctx, a.metadata.QueueName, servicebus.QueueEntityWithMessageTimeToLive(&ttl))
-		if err != nil {
-			return err
+			return nil, err
 		}
 	}
 
 	client, err := ns.NewQueue(entity.Name)
 	if err != nil {
-		return err
+		return nil, err
 	}
-	a.client = client
-	return nil
+	return serviceBusQueue{client}, nil
+}
+
+// Complete completes a message received from the queue
+func (q serviceBusQueue) Complete(ctx context.Context, msg *servicebus.Message) error {
+	return msg.Complete(ctx)
+}
+
+// Abandon abandons a message received from the queue
+func (q serviceBusQueue) Abandon(ctx context.Context, msg *servicebus.Message) error {
+	return msg.Abandon(ctx)
 }
 
 func (a *AzureServiceBusQueues) parseMetadata(metadata bindings.Metadata) (*serviceBusQueuesMetadata, error) {
@@ -152,7 +177,7 @@ func (a *AzureServiceBusQueues) Invoke(req *bindings.InvokeRequest) (*bindings.I
 		msg.TTL = &ttl
 	}
 
-	return nil, a.client.Send(ctx, msg)
+	return nil, componenterrors.FromAzure(a.client.Send(ctx, msg))
 }
 
 func (a *AzureServiceBusQueues) Read(handler func(*bindings.ReadResponse) error) error {
@@ -162,13 +187,13 @@ func (a *AzureServiceBusQueues) Read(handler func(*bindings.ReadResponse) error)
 			Metadata: map[string]string{id: msg.ID, correlationID: msg.CorrelationID, label: msg.Label},
 		})
 		if err == nil {
-			return msg.Complete(ctx)
+			return a.client.Complete(ctx, msg)
 		}
-		return msg.Abandon(ctx)
+		return a.client.Abandon(ctx, msg)
 	}
 
 	if err := a.client.Receive(context.Background(), sbHandler); err != nil {
-		return err
+		return componenterrors.FromAzure(err)
 	}
 	return nil
 }
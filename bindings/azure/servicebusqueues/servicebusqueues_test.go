@@ -6,12 +6,16 @@
 package servicebusqueues
 
 import (
+	"errors"
 	"testing"
 	"time"
 
 	"github.com/dapr/components-contrib/bindings"
+	"github.com/dapr/components-contrib/componenterrors"
+	fakes "github.com/dapr/components-contrib/testing"
 	"github.com/dapr/dapr/pkg/logger"
 	"github.com/stretchr/testify/assert"
+	"pack.ag/amqp"
 )
 
 func TestParseMetadata(t *testing.T) {
@@ -91,3 +95,50 @@ func TestParseMetadataWithInvalidTTL(t *testing.T) {
 		})
 	}
 }
+
+func TestInvokeAndRead(t *testing.T) {
+	client := fakes.NewServiceBusQueue()
+	a := NewAzureServiceBusQueues(logger.NewLogger("test"))
+	a.newQueue = func(metadata *serviceBusQueuesMetadata) (queue, error) {
+		assert.Equal(t, "queue1", metadata.QueueName)
+		return client, nil
+	}
+	err := a.Init(bindings.Metadata{Properties: map[string]string{"connectionString": "connString", "queueName": "queue1"}})
+	assert.Nil(t, err)
+
+	_, err = a.Invoke(&bindings.InvokeRequest{
+		Data:     []byte("processed"),
+		Metadata: map[string]string{id: "1", correlationID: "c1", bindings.TTLMetadataKey: "60"},
+	})
+	assert.Nil(t, err)
+	sent := client.Messages()
+	assert.Equal(t, "1", sent[0].ID)
+	assert.Equal(t, "c1", sent[0].CorrelationID)
+	assert.Equal(t, time.Minute, *sent[0].TTL)
+
+	_, err = a.Invoke(&bindings.InvokeRequest{Data: []byte("failed"), Metadata: map[string]string{id: "2"}})
+	assert.Nil(t, err)
+
+	client.FailNext("Send", &amqp.Error{Condition: amqp.ErrorMessageSizeExceeded, Description: "message too large"})
+	_, err = a.Invoke(&bindings.InvokeRequest{Data: []byte("large")})
+	assert.True(t, componenterrors.IsPermanent(err))
+
+	received := make(chan *bindings.ReadResponse, 10)
+	go a.Read(func(res *bindings.ReadResponse) error { //nolint:errcheck
+		received <- res
+		if string(res.Data) == "failed" {
+			return errors.New("handler error")
+		}
+		return nil
+	})
+
+	res := <-received
+	assert.Equal(t, "processed", string(res.Data))
+	assert.Equal(t, "c1", res.Metadata[correlationID])
+	// Messages the handler fails on are abandoned and delivered again
+	assert.Equal(t, "failed", string((<-received).Data))
+	assert.Equal(t, "failed", string((<-received).Data))
+
+	assert.Equal(t, "1", client.Completed()[0].ID)
+	assert.Equal(t, "2", client.Abandoned()[0].ID)
+}
@@ -0,0 +1,251 @@
+// ------------------------------------------------------------
+// Copyright (c) Microsoft Corporation.
+// Licensed under the MIT License.
+// ------------------------------------------------------------
+
+// Package conformance checks that bindings behave the way Dapr expects.
+// Component tests call RunOutput or RunInput with a config describing the binding.
+package conformance
+
+import (
+	"errors"
+	"fmt"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/dapr/components-contrib/bindings"
+	"github.com/stretchr/testify/assert"
+)
+
+const defaultTimeout = time.Second * 10
+
+// OutputConfig describes an output binding under test
+type OutputConfig struct {
+	// NewBinding returns a new instance of the binding, the tests initialize it
+	NewBinding func() bindings.OutputBinding
+	// Metadata initializes the binding
+	Metadata map[string]string
+	// InvokeMetadata is sent with every invoke request
+	InvokeMetadata map[string]string
+	// KeyMetadata is the invoke metadata key naming the item an operation acts on.
+	// When set, created items are expected to be returned by get and removed by delete.
+	KeyMetadata string
+}
+
+// InputConfig describes an input binding under test
+type InputConfig struct {
+	// NewBinding returns a new instance of the binding, the tests initialize it
+	NewBinding func() bindings.InputBinding
+	// Metadata initializes the binding
+	Metadata map[string]string
+	// Trigger makes an event available to the binding and returns the data it should be read with
+	Trigger func(t *testing.T) []byte
+	// Redelivery is true when an event is read again after its handler fails
+	Redelivery bool
+	// Timeout bounds the wait for an event to be read
+	Timeout time.Duration
+}
+
+// RunOutput runs the output binding conformance tests
+func RunOutput(t *testing.T, config OutputConfig) {
+	t.Run("operations", func(t *testing.T) {
+		testOperations(t, config)
+	})
+	t.Run("create", func(t *testing.T) {
+		if !supports(newOutput(t, config), bindings.CreateOperation) {
+			t.Skip("the binding doesn't support create")
+		}
+		testCreate(t, config)
+	})
+	t.Run("nil metadata", func(t *testing.T) {
+		testNilMetadata(t, config)
+	})
+	t.Run("round trip", func(t *testing.T) {
+		b := newOutput(t, config)
+		if config.KeyMetadata == "" || !supports(b, bindings.CreateOperation) || !supports(b, bindings.GetOperation) {
+			t.Skip("the binding doesn't store items by key")
+		}
+		testRoundTrip(t, config, b)
+	})
+}
+
+// RunInput runs the input binding conformance tests
+func RunInput(t *testing.T, config InputConfig) {
+	if config.Timeout == 0 {
+		config.Timeout = defaultTimeout
+	}
+
+	t.Run("read", func(t *testing.T) {
+		testRead(t, config)
+	})
+	t.Run("handler error", func(t *testing.T) {
+		testHandlerError(t, config)
+	})
+}
+
+func newOutput(t *testing.T, config OutputConfig) bindings.OutputBinding {
+	b := config.NewBinding()
+	if err := b.Init(bindings.Metadata{Name: "conformance", Properties: copyMap(config.Metadata)}); err != nil {
+		t.Fatalf("init failed: %s", err)
+	}
+	return b
+}
+
+func supports(b bindings.OutputBinding, operation bindings.OperationKind) bool {
+	for _, o := range b.Operations() {
+		if o == operation {
+			return true
+		}
+	}
+	return false
+}
+
+// invokeRequest returns a request with the invoke metadata of config and extra
+func invokeRequest(config OutputConfig, operation bindings.OperationKind, data []byte, extra map[string]string) *bindings.InvokeRequest {
+	metadata := copyMap(config.InvokeMetadata)
+	for k, v := range extra {
+		metadata[k] = v
+	}
+	return &bindings.InvokeRequest{Operation: operation, Data: data, Metadata: metadata}
+}
+
+func testOperations(t *testing.T, config OutputConfig) {
+	operations := newOutput(t, config).Operations()
+	assert.NotEmpty(t, operations, "a binding must support at least one operation")
+
+	seen := map[bindings.OperationKind]bool{}
+	for _, o := range operations {
+		assert.False(t, seen[o], "operation %s is listed twice", o)
+		seen[o] = true
+	}
+}
+
+func testCreate(t *testing.T, config OutputConfig) {
+	b := newOutput(t, config)
+	req := invokeRequest(config, bindings.CreateOperation, []byte(`{"conformance":true}`), nil)
+	metadata := copyMap(req.Metadata)
+
+	res, err := b.Invoke(req)
+	assert.Nil(t, err)
+	assert.NotNil(t, res, "a successful invoke must return a response")
+	// The request belongs to the caller, who can reuse its metadata
+	assert.Equal(t, metadata, req.Metadata, "invoke must not modify the request metadata")
+}
+
+func testNilMetadata(t *testing.T, config OutputConfig) {
+	b := newOutput(t, config)
+	if len(config.InvokeMetadata) > 0 || len(b.Operations()) == 0 {
+		t.Skip("the binding requires invoke metadata")
+	}
+
+	defer func() {
+		if r := recover(); r != nil {
+			t.Fatalf("invoke panicked on a request without metadata: %v", r)
+		}
+	}()
+	b.Invoke(&bindings.InvokeRequest{Operation: b.Operations()[0], Data: []byte("conformance")}) //nolint:errcheck
+}
+
+func testRoundTrip(t *testing.T, config OutputConfig, b bindings.OutputBinding) {
+	key := fmt.Sprintf("conformance-%d", time.Now().UnixNano())
+	keyMetadata := map[string]string{config.KeyMetadata: key}
+	data := []byte(`{"conformance":"round trip"}`)
+
+	_, err := b.Invoke(invokeRequest(config, bindings.CreateOperation, data, keyMetadata))
+	assert.Nil(t, err)
+
+	res, err := b.Invoke(invokeRequest(config, bindings.GetOperation, nil, keyMetadata))
+	if assert.Nil(t, err) && assert.NotNil(t, res) {
+		assert.Equal(t, data, res.Data)
+	}
+
+	if !supports(b, bindings.DeleteOperation) {
+		return
+	}
+	res, err = b.Invoke(invokeRequest(config, bindings.DeleteOperation, nil, keyMetadata))
+	assert.Nil(t, err)
+	assert.NotNil(t, res, "a successful invoke must return a response")
+
+	_, err = b.Invoke(invokeRequest(config, bindings.GetOperation, nil, keyMetadata))
+	assert.NotNil(t, err, "get must fail for a deleted item")
+}
+
+func newInput(t *testing.T, config InputConfig) bindings.InputBinding {
+	b := config.NewBinding()
+	if err := b.Init(bindings.Metadata{Name: "conformance", Properties: copyMap(config.Metadata)}); err != nil {
+		t.Fatalf("init failed: %s", err)
+	}
+	return b
+}
+
+// read starts reading from the binding and sends the data of each event to the returned channel.
+// The handler result for an event is returned by result. Read blocks in most bindings, so
+// it can outlive the test and its error isn't checked.
+func read(b bindings.InputBinding, result func(res *bindings.ReadResponse) error) <-chan []byte {
+	events := make(chan []byte, 10)
+	go b.Read(func(res *bindings.ReadResponse) error { //nolint:errcheck
+		select {
+		case events <- res.Data:
+		default:
+		}
+		return result(res)
+	})
+	return events
+}
+
+func testRead(t *testing.T, config InputConfig) {
+	b := newInput(t, config)
+	data := config.Trigger(t)
+	events := read(b, func(*bindings.ReadResponse) error { return nil })
+
+	select {
+	case event := <-events:
+		assert.Equal(t, data, event)
+	case <-time.After(config.Timeout):
+		t.Fatal("timed out waiting for an event")
+	}
+}
+
+func testHandlerError(t *testing.T, config InputConfig) {
+	b := newInput(t, config)
+	data := config.Trigger(t)
+
+	var lock sync.Mutex
+	attempts := 0
+	events := read(b, func(*bindings.ReadResponse) error {
+		lock.Lock()
+		defer lock.Unlock()
+
+		attempts++
+		if attempts == 1 {
+			return errors.New("conformance: failing first event")
+		}
+		return nil
+	})
+
+	select {
+	case event := <-events:
+		assert.Equal(t, data, event)
+	case <-time.After(config.Timeout):
+		t.Fatal("timed out waiting for an event")
+	}
+
+	if !config.Redelivery {
+		return
+	}
+	select {
+	case event := <-events:
+		assert.Equal(t, data, event, "a failed event must be read again")
+	case <-time.After(config.Timeout):
+		t.Fatal("timed out waiting for the failed event to be read again")
+	}
+}
+
+func copyMap(m map[string]string) map[string]string {
+	result := make(map[string]string, len(m))
+	for k, v := range m {
+		result[k] = v
+	}
+	return result
+}
@@ -3,16 +3,28 @@
 // Licensed under the MIT License.
 // ------------------------------------------------------------
 
+// Package http is a binding that reads from and posts to an HTTP endpoint.
+//
+// Metadata: url is the endpoint of the binding.
+//
+// As an input binding, the body of a GET of url is the data of the triggered event.
+//
+// As an output binding, the create operation posts the request data to url as JSON and
+// returns the body of the response as the response data. Responses with a status of 400 or
+// above are returned as errors holding the status and the body, classified with
+// componenterrors.FromHTTPStatus.
 package http
 
 import (
 	"bytes"
 	"encoding/json"
+	"fmt"
 	"io/ioutil"
 	"net/http"
 	"time"
 
 	"github.com/dapr/components-contrib/bindings"
+	"github.com/dapr/components-contrib/componenterrors"
 	"github.com/dapr/dapr/pkg/logger"
 )
 
@@ -85,14 +97,22 @@ func (h *HTTPSource) Operations() []bindings.OperationKind {
 	return []bindings.OperationKind{bindings.CreateOperation}
 }
 
+// Invoke posts the request data to the URL and returns the body of the response as the response data.
+// A status of 400 or above is returned as an error.
 func (h *HTTPSource) Invoke(req *bindings.InvokeRequest) (*bindings.InvokeResponse, error) {
 	client := http.Client{Timeout: time.Second * 5}
 	resp, err := client.Post(h.metadata.URL, "application/json; charset=utf-8", bytes.NewBuffer(req.Data))
 	if err != nil {
-		return nil, err
+		return nil, componenterrors.Classify(err)
 	}
-	if resp != nil && resp.Body != nil {
-		resp.Body.Close()
+	defer resp.Body.Close()
+
+	b, err := ioutil.ReadAll(resp.Body)
+	if err != nil {
+		return nil, componenterrors.Classify(err)
+	}
+	if resp.StatusCode >= http.StatusBadRequest {
+		return nil, componenterrors.FromHTTPStatus(resp.StatusCode, resp.Header, fmt.Errorf("http binding: %s returned %s: %s", h.metadata.URL, resp.Status, b))
 	}
-	return nil, nil
+	return &bindings.InvokeResponse{Data: b}, nil
 }
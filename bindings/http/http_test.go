@@ -6,9 +6,15 @@
 package http
 
 import (
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
 	"testing"
+	"time"
 
 	"github.com/dapr/components-contrib/bindings"
+	"github.com/dapr/components-contrib/bindings/conformance"
+	"github.com/dapr/components-contrib/componenterrors"
 	"github.com/dapr/dapr/pkg/logger"
 	"github.com/stretchr/testify/assert"
 )
@@ -22,3 +28,56 @@ func TestInit(t *testing.T) {
 	assert.Equal(t, "a", hs.metadata.URL)
 	assert.Equal(t, "a", hs.metadata.Method)
 }
+
+func TestInvokeReturnsResponseBody(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := ioutil.ReadAll(r.Body)
+		w.Write(append([]byte("echo "), body...)) //nolint:errcheck
+	}))
+	defer server.Close()
+
+	hs := NewHTTP(logger.NewLogger("test"))
+	assert.Nil(t, hs.Init(bindings.Metadata{Properties: map[string]string{"url": server.URL}}))
+
+	res, err := hs.Invoke(&bindings.InvokeRequest{Operation: bindings.CreateOperation, Data: []byte("hello")})
+	assert.Nil(t, err)
+	assert.Equal(t, "echo hello", string(res.Data))
+}
+
+func TestInvokeClassifiesErrorStatus(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Retry-After", "2")
+		w.WriteHeader(http.StatusTooManyRequests)
+	}))
+	defer server.Close()
+
+	hs := NewHTTP(logger.NewLogger("test"))
+	assert.Nil(t, hs.Init(bindings.Metadata{Properties: map[string]string{"url": server.URL}}))
+
+	_, err := hs.Invoke(&bindings.InvokeRequest{Operation: bindings.CreateOperation, Data: []byte("hello")})
+	assert.True(t, componenterrors.IsThrottled(err))
+	assert.Equal(t, time.Second*2, componenterrors.RetryAfter(err))
+}
+
+func TestConformance(t *testing.T) {
+	body := []byte(`{"conformance":"event"}`)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write(body) //nolint:errcheck
+	}))
+	defer server.Close()
+
+	t.Run("output", func(t *testing.T) {
+		conformance.RunOutput(t, conformance.OutputConfig{
+			NewBinding: func() bindings.OutputBinding { return NewHTTP(logger.NewLogger("test")) },
+			Metadata:   map[string]string{"url": server.URL},
+		})
+	})
+
+	t.Run("input", func(t *testing.T) {
+		conformance.RunInput(t, conformance.InputConfig{
+			NewBinding: func() bindings.InputBinding { return NewHTTP(logger.NewLogger("test")) },
+			Metadata:   map[string]string{"url": server.URL},
+			Trigger:    func(t *testing.T) []byte { return body },
+		})
+	})
+}
@@ -0,0 +1,205 @@
+// ------------------------------------------------------------
+// Copyright (c) Microsoft Corporation.
+// Licensed under the MIT License.
+// ------------------------------------------------------------
+
+package localstorage
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+
+	"github.com/dapr/components-contrib/bindings"
+	"github.com/dapr/components-contrib/componenterrors"
+	"github.com/dapr/dapr/pkg/logger"
+	"github.com/google/uuid"
+)
+
+const (
+	fileNameMetadataKey = "fileName"
+)
+
+// LocalStorage is a binding for files in a local directory
+type LocalStorage struct {
+	metadata *Metadata
+
+	logger logger.Logger
+}
+
+// Metadata is the local storage binding config
+type Metadata struct {
+	RootPath string `json:"rootPath"`
+}
+
+type createResponse struct {
+	FileName string `json:"fileName"`
+}
+
+// NewLocalStorage returns a new LocalStorage instance
+func NewLocalStorage(logger logger.Logger) *LocalStorage {
+	return &LocalStorage{logger: logger}
+}
+
+// Init performs metadata parsing and creates the root directory
+func (ls *LocalStorage) Init(metadata bindings.Metadata) error {
+	m, err := ls.parseMetadata(metadata)
+	if err != nil {
+		return err
+	}
+	ls.metadata = m
+
+	if err = os.MkdirAll(m.RootPath, 0750); err != nil {
+		return fmt.Errorf("local storage: error creating root path %s: %s", m.RootPath, err)
+	}
+	return nil
+}
+
+func (ls *LocalStorage) parseMetadata(metadata bindings.Metadata) (*Metadata, error) {
+	b, err := json.Marshal(metadata.Properties)
+	if err != nil {
+		return nil, err
+	}
+
+	var m Metadata
+	err = json.Unmarshal(b, &m)
+	if err != nil {
+		return nil, err
+	}
+	if m.RootPath == "" {
+		return nil, errors.New("local storage: missing rootPath")
+	}
+	return &m, nil
+}
+
+// Operations enumerates supported binding operations
+func (ls *LocalStorage) Operations() []bindings.OperationKind {
+	return []bindings.OperationKind{
+		bindings.CreateOperation,
+		bindings.GetOperation,
+		bindings.DeleteOperation,
+		bindings.ListOperation,
+	}
+}
+
+// Invoke acts on the file named by the fileName metadata. Create generates a name when none is given.
+func (ls *LocalStorage) Invoke(req *bindings.InvokeRequest) (*bindings.InvokeResponse, error) {
+	switch req.Operation {
+	case bindings.CreateOperation:
+		return ls.create(req)
+	case bindings.GetOperation:
+		return ls.get(req)
+	case bindings.DeleteOperation:
+		return ls.delete(req)
+	case bindings.ListOperation:
+		return ls.list()
+	default:
+		return nil, componenterrors.Newf(componenterrors.ClassPermanent, "local storage: unsupported operation %s", req.Operation)
+	}
+}
+
+func (ls *LocalStorage) create(req *bindings.InvokeRequest) (*bindings.InvokeResponse, error) {
+	name := req.Metadata[fileNameMetadataKey]
+	if name == "" {
+		name = uuid.New().String()
+	}
+
+	path, err := ls.filePath(name)
+	if err != nil {
+		return nil, err
+	}
+	if err = os.MkdirAll(filepath.Dir(path), 0750); err != nil {
+		return nil, fmt.Errorf("local storage: error creating directory for %s: %s", name, err)
+	}
+	if err = ioutil.WriteFile(path, req.Data, 0600); err != nil {
+		return nil, classifyFileError(fmt.Errorf("local storage: error writing %s: %w", name, err))
+	}
+
+	b, err := json.Marshal(createResponse{FileName: name})
+	if err != nil {
+		return nil, err
+	}
+	return &bindings.InvokeResponse{Data: b}, nil
+}
+
+func (ls *LocalStorage) get(req *bindings.InvokeRequest) (*bindings.InvokeResponse, error) {
+	path, err := ls.requestFilePath(req)
+	if err != nil {
+		return nil, err
+	}
+
+	b, err := ioutil.ReadFile(path)
+	if err != nil {
+		return nil, classifyFileError(fmt.Errorf("local storage: error reading %s: %w", req.Metadata[fileNameMetadataKey], err))
+	}
+	return &bindings.InvokeResponse{Data: b}, nil
+}
+
+func (ls *LocalStorage) delete(req *bindings.InvokeRequest) (*bindings.InvokeResponse, error) {
+	path, err := ls.requestFilePath(req)
+	if err != nil {
+		return nil, err
+	}
+
+	if err = os.Remove(path); err != nil {
+		return nil, classifyFileError(fmt.Errorf("local storage: error deleting %s: %w", req.Metadata[fileNameMetadataKey], err))
+	}
+	return &bindings.InvokeResponse{}, nil
+}
+
+// list returns the names of the files under the root path, relative to it
+func (ls *LocalStorage) list() (*bindings.InvokeResponse, error) {
+	names := []string{}
+	err := filepath.Walk(ls.metadata.RootPath, func(path string, info os.FileInfo, err error) error {
+		if err != nil || info.IsDir() {
+			return err
+		}
+		name, err := filepath.Rel(ls.metadata.RootPath, path)
+		if err != nil {
+			return err
+		}
+		names = append(names, filepath.ToSlash(name))
+		return nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("local storage: error listing files: %s", err)
+	}
+
+	b, err := json.Marshal(names)
+	if err != nil {
+		return nil, err
+	}
+	return &bindings.InvokeResponse{Data: b}, nil
+}
+
+func (ls *LocalStorage) requestFilePath(req *bindings.InvokeRequest) (string, error) {
+	name := req.Metadata[fileNameMetadataKey]
+	if name == "" {
+		return "", componenterrors.Newf(componenterrors.ClassPermanent, "local storage: missing %s metadata", fileNameMetadataKey)
+	}
+	return ls.filePath(name)
+}
+
+// filePath returns the path of a file under the root path. Names can't escape the root path.
+func (ls *LocalStorage) filePath(name string) (string, error) {
+	cleaned := filepath.Clean(string(filepath.Separator) + name)
+	if cleaned == string(filepath.Separator) {
+		return "", componenterrors.Newf(componenterrors.ClassPermanent, "local storage: invalid file name %s", name)
+	}
+	return filepath.Join(ls.metadata.RootPath, cleaned), nil
+}
+
+// classifyFileError marks missing files as permanent and denied access as unauthorized
+func classifyFileError(err error) error {
+	switch {
+	case errors.Is(err, os.ErrNotExist):
+		return componenterrors.Permanent(err)
+	case errors.Is(err, os.ErrPermission):
+		return componenterrors.Unauthorized(err)
+	default:
+		return err
+	}
+}
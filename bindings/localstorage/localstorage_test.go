@@ -0,0 +1,81 @@
+// ------------------------------------------------------------
+// Copyright (c) Microsoft Corporation.
+// Licensed under the MIT License.
+// ------------------------------------------------------------
+
+package localstorage
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/dapr/components-contrib/bindings"
+	"github.com/dapr/components-contrib/bindings/conformance"
+	"github.com/dapr/components-contrib/componenterrors"
+	"github.com/dapr/dapr/pkg/logger"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestInit(t *testing.T) {
+	dir, err := ioutil.TempDir("", "localstorage")
+	assert.Nil(t, err)
+	defer os.RemoveAll(dir)
+
+	ls := NewLocalStorage(logger.NewLogger("test"))
+	err = ls.Init(bindings.Metadata{Properties: map[string]string{"rootPath": filepath.Join(dir, "files")}})
+	assert.Nil(t, err)
+	assert.DirExists(t, filepath.Join(dir, "files"))
+
+	err = ls.Init(bindings.Metadata{Properties: map[string]string{}})
+	assert.NotNil(t, err)
+}
+
+func TestFileNamesStayUnderRootPath(t *testing.T) {
+	dir, err := ioutil.TempDir("", "localstorage")
+	assert.Nil(t, err)
+	defer os.RemoveAll(dir)
+
+	ls := NewLocalStorage(logger.NewLogger("test"))
+	assert.Nil(t, ls.Init(bindings.Metadata{Properties: map[string]string{"rootPath": filepath.Join(dir, "files")}}))
+
+	_, err = ls.Invoke(&bindings.InvokeRequest{
+		Operation: bindings.CreateOperation,
+		Data:      []byte("data"),
+		Metadata:  map[string]string{fileNameMetadataKey: "../../escaped.txt"},
+	})
+	assert.Nil(t, err)
+	assert.FileExists(t, filepath.Join(dir, "files", "escaped.txt"))
+
+	res, err := ls.Invoke(&bindings.InvokeRequest{Operation: bindings.ListOperation})
+	assert.Nil(t, err)
+	assert.JSONEq(t, `["escaped.txt"]`, string(res.Data))
+
+	_, err = ls.Invoke(&bindings.InvokeRequest{Operation: bindings.GetOperation, Metadata: map[string]string{fileNameMetadataKey: ".."}})
+	assert.NotNil(t, err)
+}
+
+func TestMissingFileIsPermanent(t *testing.T) {
+	dir, err := ioutil.TempDir("", "localstorage")
+	assert.Nil(t, err)
+	defer os.RemoveAll(dir)
+
+	ls := NewLocalStorage(logger.NewLogger("test"))
+	assert.Nil(t, ls.Init(bindings.Metadata{Properties: map[string]string{"rootPath": dir}}))
+
+	_, err = ls.Invoke(&bindings.InvokeRequest{Operation: bindings.GetOperation, Metadata: map[string]string{fileNameMetadataKey: "missing.txt"}})
+	assert.True(t, componenterrors.IsPermanent(err))
+}
+
+func TestConformance(t *testing.T) {
+	dir, err := ioutil.TempDir("", "localstorage")
+	assert.Nil(t, err)
+	defer os.RemoveAll(dir)
+
+	conformance.RunOutput(t, conformance.OutputConfig{
+		NewBinding:  func() bindings.OutputBinding { return NewLocalStorage(logger.NewLogger("test")) },
+		Metadata:    map[string]string{"rootPath": dir},
+		KeyMetadata: fileNameMetadataKey,
+	})
+}
@@ -14,6 +14,7 @@ import (
 	"time"
 
 	"github.com/dapr/components-contrib/bindings"
+	"github.com/dapr/components-contrib/componenterrors"
 	"github.com/dapr/dapr/pkg/logger"
 
 	redis "github.com/go-redis/redis/v7"
@@ -127,9 +128,9 @@ func (r *Redis) Invoke(req *bindings.InvokeRequest) (*bindings.InvokeResponse, e
 		key := val
 		_, err := r.client.DoContext(context.Background(), "SET", key, req.Data).Result()
 		if err != nil {
-			return nil, err
+			return nil, componenterrors.FromRedis(err)
 		}
 		return nil, nil
 	}
-	return nil, errors.New("redis binding: missing key on write request metadata")
+	return nil, componenterrors.Permanent(errors.New("redis binding: missing key on write request metadata"))
 }
@@ -0,0 +1,112 @@
+// ------------------------------------------------------------
+// Copyright (c) Microsoft Corporation.
+// Licensed under the MIT License.
+// ------------------------------------------------------------
+
+package componenterrors
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"strconv"
+	"strings"
+	"syscall"
+	"time"
+)
+
+// Classify returns err classified from its type when it isn't already: network
+// and timeout errors are retriable. Other errors are returned unchanged.
+func Classify(err error) error {
+	if err == nil || ClassOf(err) != ClassUnknown {
+		return err
+	}
+	if isTransient(err) {
+		return Retriable(err)
+	}
+	return err
+}
+
+func isTransient(err error) bool {
+	if errors.Is(err, context.DeadlineExceeded) || errors.Is(err, io.EOF) || errors.Is(err, io.ErrUnexpectedEOF) {
+		return true
+	}
+	if errors.Is(err, syscall.ECONNREFUSED) || errors.Is(err, syscall.ECONNRESET) || errors.Is(err, syscall.EPIPE) {
+		return true
+	}
+
+	var netErr net.Error
+	if errors.As(err, &netErr) {
+		return true
+	}
+	return false
+}
+
+// FromHTTPStatus returns an error of the class matching an HTTP response status.
+// Throttled responses use the delay of their Retry-After header.
+// It returns nil for statuses below 400.
+func FromHTTPStatus(status int, header http.Header, err error) error {
+	if status < http.StatusBadRequest {
+		return nil
+	}
+	if err == nil {
+		err = fmt.Errorf("%d %s", status, http.StatusText(status))
+	}
+
+	switch {
+	case status == http.StatusTooManyRequests:
+		return Throttled(err, parseRetryAfter(header.Get("Retry-After")))
+	case status == http.StatusServiceUnavailable && header.Get("Retry-After") != "":
+		return Throttled(err, parseRetryAfter(header.Get("Retry-After")))
+	case status == http.StatusUnauthorized || status == http.StatusForbidden:
+		return Unauthorized(err)
+	case status == http.StatusRequestTimeout || status >= http.StatusInternalServerError:
+		return Retriable(err)
+	default:
+		return Permanent(err)
+	}
+}
+
+// parseRetryAfter parses a Retry-After header in seconds or as an HTTP date
+func parseRetryAfter(val string) time.Duration {
+	if val == "" {
+		return 0
+	}
+	if seconds, err := strconv.Atoi(val); err == nil && seconds > 0 {
+		return time.Duration(seconds) * time.Second
+	}
+	if t, err := http.ParseTime(val); err == nil {
+		if d := time.Until(t); d > 0 {
+			return d
+		}
+	}
+	return 0
+}
+
+// redisError is implemented by the errors Redis servers reply with
+type redisError interface {
+	error
+	RedisError()
+}
+
+// FromRedis classifies the errors of Redis clients by the prefix of server replies.
+// Other errors are classified by Classify.
+func FromRedis(err error) error {
+	var reply redisError
+	if err == nil || ClassOf(err) != ClassUnknown || !errors.As(err, &reply) {
+		return Classify(err)
+	}
+
+	prefix := strings.SplitN(reply.Error(), " ", 2)[0]
+	switch prefix {
+	case "LOADING", "BUSY", "TRYAGAIN", "CLUSTERDOWN", "MASTERDOWN", "READONLY":
+		return Retriable(err)
+	case "NOAUTH", "WRONGPASS", "NOPERM":
+		return Unauthorized(err)
+	default:
+		return Permanent(err)
+	}
+}
@@ -0,0 +1,142 @@
+// ------------------------------------------------------------
+// Copyright (c) Microsoft Corporation.
+// Licensed under the MIT License.
+// ------------------------------------------------------------
+
+// Package componenterrors classifies component errors so callers can decide
+// whether an operation is worth retrying.
+package componenterrors
+
+import (
+	"errors"
+	"fmt"
+	"time"
+)
+
+// Class is how a caller should react to an error
+type Class int
+
+const (
+	// ClassUnknown errors weren't classified by the component
+	ClassUnknown Class = iota
+	// ClassRetriable errors are transient, the operation can succeed when retried
+	ClassRetriable
+	// ClassPermanent errors fail the same way when the operation is retried
+	ClassPermanent
+	// ClassThrottled errors are caused by rate limiting, the operation can be retried after a delay
+	ClassThrottled
+	// ClassUnauthorized errors are caused by missing or invalid credentials
+	ClassUnauthorized
+)
+
+func (c Class) String() string {
+	switch c {
+	case ClassRetriable:
+		return "retriable"
+	case ClassPermanent:
+		return "permanent"
+	case ClassThrottled:
+		return "throttled"
+	case ClassUnauthorized:
+		return "unauthorized"
+	default:
+		return "unknown"
+	}
+}
+
+// Error is an error with a class
+type Error struct {
+	Class Class
+	// RetryAfter is the delay a throttled operation should be retried after, 0 when unknown
+	RetryAfter time.Duration
+	Err        error
+}
+
+func (e *Error) Error() string {
+	return e.Err.Error()
+}
+
+// Unwrap returns the classified error
+func (e *Error) Unwrap() error {
+	return e.Err
+}
+
+// Retriable marks err as transient. It returns nil when err is nil.
+func Retriable(err error) error {
+	return classify(err, ClassRetriable, 0)
+}
+
+// Permanent marks err as failing the same way when retried. It returns nil when err is nil.
+func Permanent(err error) error {
+	return classify(err, ClassPermanent, 0)
+}
+
+// Throttled marks err as caused by rate limiting, the operation can be retried after retryAfter.
+// It returns nil when err is nil.
+func Throttled(err error, retryAfter time.Duration) error {
+	return classify(err, ClassThrottled, retryAfter)
+}
+
+// Unauthorized marks err as caused by missing or invalid credentials. It returns nil when err is nil.
+func Unauthorized(err error) error {
+	return classify(err, ClassUnauthorized, 0)
+}
+
+// Newf returns a new error of class c formatted like fmt.Errorf
+func Newf(c Class, format string, args ...interface{}) error {
+	return &Error{Class: c, Err: fmt.Errorf(format, args...)}
+}
+
+func classify(err error, c Class, retryAfter time.Duration) error {
+	if err == nil {
+		return nil
+	}
+	return &Error{Class: c, RetryAfter: retryAfter, Err: err}
+}
+
+// ClassOf returns the class of the outermost classified error in the chain of err
+func ClassOf(err error) Class {
+	var e *Error
+	if errors.As(err, &e) {
+		return e.Class
+	}
+	return ClassUnknown
+}
+
+// IsRetriable returns true when retrying the operation that failed with err can succeed.
+// Throttled errors are retriable after their delay.
+func IsRetriable(err error) bool {
+	c := ClassOf(err)
+	return c == ClassRetriable || c == ClassThrottled
+}
+
+// IsPermanent returns true when err is a permanent error
+func IsPermanent(err error) bool {
+	return ClassOf(err) == ClassPermanent
+}
+
+// IsThrottled returns true when err is caused by rate limiting
+func IsThrottled(err error) bool {
+	return ClassOf(err) == ClassThrottled
+}
+
+// IsUnauthorized returns true when err is caused by missing or invalid credentials
+func IsUnauthorized(err error) bool {
+	return ClassOf(err) == ClassUnauthorized
+}
+
+// ShouldRetry returns false for the errors retrying can't fix: permanent and unauthorized errors.
+// Unclassified errors are retried.
+func ShouldRetry(err error) bool {
+	c := ClassOf(err)
+	return c != ClassPermanent && c != ClassUnauthorized
+}
+
+// RetryAfter returns the delay a throttled error asks for, 0 when there is none
+func RetryAfter(err error) time.Duration {
+	var e *Error
+	if errors.As(err, &e) && e.Class == ClassThrottled {
+		return e.RetryAfter
+	}
+	return 0
+}
@@ -0,0 +1,128 @@
+// ------------------------------------------------------------
+// Copyright (c) Microsoft Corporation.
+// Licensed under the MIT License.
+// ------------------------------------------------------------
+
+package componenterrors
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/a8m/documentdb"
+	"github.com/aws/aws-sdk-go/aws/awserr"
+	"github.com/stretchr/testify/assert"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"pack.ag/amqp"
+)
+
+func TestClasses(t *testing.T) {
+	base := errors.New("failed")
+
+	assert.Nil(t, Retriable(nil))
+	assert.True(t, IsRetriable(Retriable(base)))
+	assert.True(t, IsPermanent(Permanent(base)))
+	assert.True(t, IsUnauthorized(Unauthorized(base)))
+	assert.Equal(t, ClassUnknown, ClassOf(base))
+
+	throttled := Throttled(base, time.Second*3)
+	assert.True(t, IsThrottled(throttled))
+	assert.True(t, IsRetriable(throttled))
+	assert.Equal(t, time.Second*3, RetryAfter(throttled))
+
+	// Classes survive wrapping and the original error stays reachable
+	wrapped := fmt.Errorf("publishing: %w", Permanent(base))
+	assert.Equal(t, ClassPermanent, ClassOf(wrapped))
+	assert.True(t, errors.Is(wrapped, base))
+	assert.Equal(t, "publishing: failed", wrapped.Error())
+
+	assert.True(t, ShouldRetry(base))
+	assert.True(t, ShouldRetry(throttled))
+	assert.False(t, ShouldRetry(wrapped))
+	assert.False(t, ShouldRetry(Unauthorized(base)))
+}
+
+func TestClassify(t *testing.T) {
+	assert.Nil(t, Classify(nil))
+	assert.True(t, IsRetriable(Classify(context.DeadlineExceeded)))
+	assert.True(t, IsRetriable(Classify(&net.OpError{Op: "dial", Err: errors.New("connection refused")})))
+	assert.Equal(t, ClassUnknown, ClassOf(Classify(errors.New("bad request"))))
+	// Classified errors are kept
+	assert.True(t, IsPermanent(Classify(Permanent(context.DeadlineExceeded))))
+}
+
+func TestFromHTTPStatus(t *testing.T) {
+	assert.Nil(t, FromHTTPStatus(http.StatusOK, nil, nil))
+
+	header := http.Header{}
+	header.Set("Retry-After", "5")
+	err := FromHTTPStatus(http.StatusTooManyRequests, header, nil)
+	assert.True(t, IsThrottled(err))
+	assert.Equal(t, time.Second*5, RetryAfter(err))
+	assert.Equal(t, "429 Too Many Requests", err.Error())
+
+	assert.True(t, IsThrottled(FromHTTPStatus(http.StatusServiceUnavailable, header, nil)))
+	assert.True(t, IsRetriable(FromHTTPStatus(http.StatusServiceUnavailable, http.Header{}, nil)))
+	assert.True(t, IsUnauthorized(FromHTTPStatus(http.StatusForbidden, nil, nil)))
+	assert.True(t, IsPermanent(FromHTTPStatus(http.StatusBadRequest, nil, nil)))
+	assert.True(t, IsPermanent(FromHTTPStatus(http.StatusConflict, nil, nil)))
+}
+
+type fakeRedisError string
+
+func (e fakeRedisError) Error() string { return string(e) }
+func (fakeRedisError) RedisError()     {}
+
+func TestFromRedis(t *testing.T) {
+	assert.True(t, IsRetriable(FromRedis(fakeRedisError("LOADING Redis is loading the dataset in memory"))))
+	assert.True(t, IsUnauthorized(FromRedis(fakeRedisError("NOAUTH Authentication required."))))
+	assert.True(t, IsPermanent(FromRedis(fakeRedisError("WRONGTYPE Operation against a key holding the wrong kind of value"))))
+	assert.True(t, IsRetriable(FromRedis(&net.OpError{Op: "read", Err: errors.New("connection reset")})))
+}
+
+func TestFromAWS(t *testing.T) {
+	assert.True(t, IsThrottled(FromAWS(awserr.New("ThrottlingException", "rate exceeded", nil))))
+	assert.True(t, IsUnauthorized(FromAWS(awserr.New("AccessDenied", "access denied", nil))))
+	assert.True(t, IsRetriable(FromAWS(awserr.New("RequestError", "send request failed", nil))))
+	assert.True(t, IsPermanent(FromAWS(awserr.New("AWS.SimpleQueueService.NonExistentQueue", "no queue", nil))))
+	// Unknown codes are classified by the status of the response
+	failure := awserr.NewRequestFailure(awserr.New("Unknown", "unavailable", nil), http.StatusServiceUnavailable, "id")
+	assert.True(t, IsRetriable(FromAWS(failure)))
+	assert.True(t, IsRetriable(FromAWS(context.DeadlineExceeded)))
+	assert.Nil(t, FromAWS(nil))
+}
+
+func TestFromGRPC(t *testing.T) {
+	assert.True(t, IsRetriable(FromGRPC(status.Error(codes.Unavailable, "unavailable"))))
+	assert.True(t, IsThrottled(FromGRPC(status.Error(codes.ResourceExhausted, "quota exceeded"))))
+	assert.True(t, IsUnauthorized(FromGRPC(status.Error(codes.PermissionDenied, "denied"))))
+	assert.True(t, IsPermanent(FromGRPC(fmt.Errorf("get: %w", status.Error(codes.InvalidArgument, "bad key")))))
+	assert.Equal(t, ClassUnknown, ClassOf(FromGRPC(status.Error(codes.Unknown, "unknown"))))
+	assert.Nil(t, FromGRPC(nil))
+}
+
+func TestFromAzureCode(t *testing.T) {
+	assert.True(t, IsThrottled(FromAzureCode("TooManyRequests", errors.New("request rate is large"))))
+	assert.True(t, IsPermanent(FromAzureCode("PreconditionFailed", errors.New("etag mismatch"))))
+	assert.True(t, IsRetriable(FromAzureCode("503", errors.New("unavailable"))))
+	assert.True(t, IsUnauthorized(FromAzureCode("Forbidden", errors.New("forbidden"))))
+	assert.Equal(t, ClassUnknown, ClassOf(FromAzureCode("Unknown", errors.New("unknown"))))
+}
+
+func TestFromAzure(t *testing.T) {
+	assert.True(t, IsThrottled(FromAzure(&documentdb.RequestError{Code: "TooManyRequests"})))
+	assert.True(t, IsPermanent(FromAzure(&documentdb.RequestError{Code: "Conflict"})))
+	assert.True(t, IsThrottled(FromAzure(&amqp.Error{Condition: "com.microsoft:server-busy"})))
+	assert.True(t, IsUnauthorized(FromAzure(&amqp.Error{Condition: amqp.ErrorUnauthorizedAccess})))
+	assert.True(t, IsPermanent(FromAzure(&amqp.Error{Condition: amqp.ErrorNotFound})))
+	assert.True(t, IsRetriable(FromAzure(amqp.ErrLinkClosed)))
+	assert.True(t, IsRetriable(FromAzure(fmt.Errorf("error code: 500, Details: internal error"))))
+	assert.True(t, IsUnauthorized(FromAzure(fmt.Errorf("error code: 401, Details: unauthorized"))))
+	assert.Equal(t, ClassUnknown, ClassOf(FromAzure(errors.New("unknown"))))
+}
@@ -0,0 +1,155 @@
+// ------------------------------------------------------------
+// Copyright (c) Microsoft Corporation.
+// Licensed under the MIT License.
+// ------------------------------------------------------------
+
+package componenterrors
+
+import (
+	"errors"
+	"net/http"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/a8m/documentdb"
+	"github.com/aws/aws-sdk-go/aws/awserr"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"pack.ag/amqp"
+)
+
+// FromAWS classifies the errors of the AWS SDK by their error code, and by the HTTP
+// status of the response for codes it doesn't know. Other errors are classified by Classify.
+func FromAWS(err error) error {
+	var awsErr awserr.Error
+	if err == nil || ClassOf(err) != ClassUnknown || !errors.As(err, &awsErr) {
+		return Classify(err)
+	}
+
+	switch awsErr.Code() {
+	case "Throttling", "ThrottlingException", "ThrottledException", "RequestThrottled", "RequestThrottledException",
+		"TooManyRequestsException", "ProvisionedThroughputExceededException", "RequestLimitExceeded", "SlowDown":
+		return Throttled(err, 0)
+	case "AccessDenied", "AccessDeniedException", "AuthFailure", "AuthorizationError", "ExpiredToken",
+		"ExpiredTokenException", "InvalidAccessKeyId", "InvalidClientTokenId", "MissingAuthenticationToken",
+		"SignatureDoesNotMatch", "UnrecognizedClientException":
+		return Unauthorized(err)
+	case "RequestError", "ResponseTimeout", "RequestTimeout", "RequestTimeoutException", "ServiceUnavailable",
+		"InternalError", "InternalFailure", "InternalServerError":
+		return Retriable(err)
+	case "RequestCanceled":
+		return err
+	}
+
+	var failure awserr.RequestFailure
+	if errors.As(err, &failure) && failure.StatusCode() >= http.StatusBadRequest {
+		return FromHTTPStatus(failure.StatusCode(), http.Header{}, err)
+	}
+	// Other codes name a problem of the request, such as a missing queue or an invalid parameter
+	return Permanent(err)
+}
+
+// FromGRPC classifies errors carrying a gRPC status, as returned by the Google Cloud
+// clients, by their code. Other errors are classified by Classify.
+func FromGRPC(err error) error {
+	var grpcErr interface{ GRPCStatus() *status.Status }
+	if err == nil || ClassOf(err) != ClassUnknown || !errors.As(err, &grpcErr) {
+		return Classify(err)
+	}
+
+	switch grpcErr.GRPCStatus().Code() {
+	case codes.Unavailable, codes.DeadlineExceeded, codes.Aborted, codes.Internal:
+		return Retriable(err)
+	case codes.ResourceExhausted:
+		return Throttled(err, 0)
+	case codes.Unauthenticated, codes.PermissionDenied:
+		return Unauthorized(err)
+	case codes.InvalidArgument, codes.NotFound, codes.AlreadyExists, codes.FailedPrecondition,
+		codes.OutOfRange, codes.Unimplemented:
+		return Permanent(err)
+	default:
+		return Classify(err)
+	}
+}
+
+// statusByName maps the names of HTTP statuses without spaces, such as TooManyRequests,
+// to their code
+var statusByName = func() map[string]int {
+	names := map[string]int{}
+	for code := http.StatusBadRequest; code < 600; code++ {
+		if text := http.StatusText(code); text != "" {
+			names[strings.NewReplacer(" ", "", "-", "", "'", "").Replace(text)] = code
+		}
+	}
+	// Cosmos DB asks to retry the operation with this code
+	names["RetryWith"] = http.StatusServiceUnavailable
+	return names
+}()
+
+// FromAzureCode classifies err by the error code of an Azure REST API, which is the HTTP
+// status of the response or its name, such as TooManyRequests for Cosmos DB. Errors with
+// other codes are classified by Classify.
+func FromAzureCode(code string, err error) error {
+	if err == nil || ClassOf(err) != ClassUnknown {
+		return err
+	}
+
+	status, ok := statusByName[code]
+	if !ok {
+		status, _ = strconv.Atoi(code)
+	}
+	if status < http.StatusBadRequest {
+		return Classify(err)
+	}
+	return FromHTTPStatus(status, http.Header{}, err)
+}
+
+// managementErrorRegex matches the errors of the Service Bus management API, which
+// only report the HTTP status in their message
+var managementErrorRegex = regexp.MustCompile(`^error code: (\d{3}),`)
+
+// FromAzure classifies the errors of the Azure clients: Cosmos DB errors by their code,
+// Service Bus and Event Hubs AMQP errors by their condition, and their management errors
+// by their HTTP status. Other errors are classified by Classify.
+func FromAzure(err error) error {
+	if err == nil || ClassOf(err) != ClassUnknown {
+		return err
+	}
+
+	var cosmosErr *documentdb.RequestError
+	var amqpErr *amqp.Error
+	var detachErr *amqp.DetachError
+	switch {
+	case errors.As(err, &cosmosErr):
+		return FromAzureCode(cosmosErr.Code, err)
+	case errors.As(err, &amqpErr):
+		return fromAMQPCondition(amqpErr.Condition, err)
+	case errors.As(err, &detachErr), errors.Is(err, amqp.ErrConnClosed), errors.Is(err, amqp.ErrSessionClosed),
+		errors.Is(err, amqp.ErrLinkClosed), errors.Is(err, amqp.ErrTimeout):
+		return Retriable(err)
+	}
+
+	if m := managementErrorRegex.FindStringSubmatch(err.Error()); m != nil {
+		return FromAzureCode(m[1], err)
+	}
+	return Classify(err)
+}
+
+func fromAMQPCondition(condition amqp.ErrorCondition, err error) error {
+	switch condition {
+	case "com.microsoft:server-busy", amqp.ErrorResourceLimitExceeded:
+		return Throttled(err, 0)
+	case amqp.ErrorUnauthorizedAccess:
+		return Unauthorized(err)
+	case "com.microsoft:timeout", amqp.ErrorInternalError, amqp.ErrorConnectionForced, amqp.ErrorDetachForced,
+		amqp.ErrorResourceLocked:
+		return Retriable(err)
+	case amqp.ErrorNotFound, amqp.ErrorNotAllowed, amqp.ErrorInvalidField, amqp.ErrorDecodeError,
+		amqp.ErrorNotImplemented, amqp.ErrorPreconditionFailed, amqp.ErrorMessageSizeExceeded,
+		"com.microsoft:entity-disabled", "com.microsoft:message-lock-lost":
+		return Permanent(err)
+	default:
+		return Classify(err)
+	}
+}
@@ -5,7 +5,27 @@
 
 package exporters
 
+import "context"
+
 // Exporter is the interface for tracing exporter wrappers
 type Exporter interface {
 	Init(daprID string, hostAddress string, metadata Metadata) error
+	// Shutdown flushes pending spans, unregisters the exporter and releases its resources.
+	// It is safe to call Shutdown on an exporter that was never enabled.
+	Shutdown(ctx context.Context) error
+}
+
+// WaitContext runs fn and waits until it returns or ctx is done, whichever happens first
+func WaitContext(ctx context.Context, fn func() error) error {
+	done := make(chan error, 1)
+	go func() {
+		done <- fn()
+	}()
+
+	select {
+	case err := <-done:
+		return err
+	case <-ctx.Done():
+		return ctx.Err()
+	}
 }
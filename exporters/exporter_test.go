@@ -0,0 +1,37 @@
+// ------------------------------------------------------------
+// Copyright (c) Microsoft Corporation.
+// Licensed under the MIT License.
+// ------------------------------------------------------------
+
+package exporters
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestWaitContext(t *testing.T) {
+	t.Run("returns the function error", func(t *testing.T) {
+		err := WaitContext(context.Background(), func() error {
+			return errors.New("flush failed")
+		})
+		assert.EqualError(t, err, "flush failed")
+	})
+
+	t.Run("stops waiting when the context is done", func(t *testing.T) {
+		ctx, cancel := context.WithTimeout(context.Background(), time.Millisecond*10)
+		defer cancel()
+
+		block := make(chan struct{})
+		defer close(block)
+		err := WaitContext(ctx, func() error {
+			<-block
+			return nil
+		})
+		assert.Equal(t, context.DeadlineExceeded, err)
+	})
+}
@@ -0,0 +1,196 @@
+// ------------------------------------------------------------
+// Copyright (c) Microsoft Corporation.
+// Licensed under the MIT License.
+// ------------------------------------------------------------
+
+package jaeger
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"contrib.go.opencensus.io/exporter/jaeger"
+	"github.com/dapr/components-contrib/exporters"
+	"github.com/dapr/dapr/pkg/logger"
+	"go.opencensus.io/trace"
+)
+
+const (
+	// hostIPTag is the process tag Jaeger clients use for the host address
+	hostIPTag = "ip"
+
+	// Defaults
+	defaultFlushInterval = time.Second
+)
+
+// Metadata is the jaeger config
+type jaegerMetadata struct {
+	Enabled           string `json:"enabled"`
+	AgentEndpoint     string `json:"agentEndpoint"`
+	CollectorEndpoint string `json:"collectorEndpoint"`
+	Username          string `json:"username"`
+	Password          string `json:"password"`
+	ProcessTags       string `json:"processTags"`
+	BufferMaxCount    string `json:"bufferMaxCount"`
+	FlushInterval     string `json:"flushInterval"`
+}
+
+// NewJaegerExporter returns a new jaeger exporter instance
+func NewJaegerExporter(logger logger.Logger) *Exporter {
+	return &Exporter{logger: logger}
+}
+
+// Exporter is an OpenCensus jaeger exporter
+type Exporter struct {
+	logger   logger.Logger
+	exporter *jaeger.Exporter
+	stopCh   chan struct{}
+}
+
+// Init creates a new jaeger exporter sending to either an agent or a collector
+func (j *Exporter) Init(daprID string, hostAddress string, metadata exporters.Metadata) error {
+	// Shut down a previous registration so re-initializing doesn't export spans twice
+	if err := j.Shutdown(context.Background()); err != nil {
+		j.logger.Warnf("jaeger exporter: error shutting down previous exporter: %s", err)
+	}
+
+	meta, err := j.getJaegerMetadata(metadata)
+	if err != nil {
+		return err
+	}
+
+	enabled, _ := strconv.ParseBool(meta.Enabled)
+	if !enabled {
+		return nil
+	}
+
+	err = exporters.ApplySampling(metadata)
+	if err != nil {
+		return err
+	}
+
+	options, flushInterval, err := getJaegerOptions(daprID, hostAddress, meta)
+	if err != nil {
+		return err
+	}
+	options.OnError = func(err error) {
+		j.logger.Errorf("jaeger exporter: error uploading spans: %s", err)
+	}
+
+	exporter, err := jaeger.NewExporter(*options)
+	if err != nil {
+		return err
+	}
+
+	j.exporter = exporter
+	j.stopCh = make(chan struct{})
+	go flushPeriodically(exporter, flushInterval, j.stopCh)
+
+	trace.RegisterExporter(exporter)
+	return nil
+}
+
+// Shutdown unregisters the exporter, stops the periodic flush and uploads the buffered spans
+func (j *Exporter) Shutdown(ctx context.Context) error {
+	if j.exporter == nil {
+		return nil
+	}
+
+	trace.UnregisterExporter(j.exporter)
+	close(j.stopCh)
+	exporter := j.exporter
+	j.exporter = nil
+	return exporters.WaitContext(ctx, func() error {
+		exporter.Flush()
+		return nil
+	})
+}
+
+// flushPeriodically uploads buffered spans at the configured interval
+func flushPeriodically(exporter *jaeger.Exporter, interval time.Duration, stopCh <-chan struct{}) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ticker.C:
+			exporter.Flush()
+		case <-stopCh:
+			return
+		}
+	}
+}
+
+func getJaegerOptions(daprID string, hostAddress string, meta *jaegerMetadata) (*jaeger.Options, time.Duration, error) {
+	if meta.AgentEndpoint == "" && meta.CollectorEndpoint == "" {
+		return nil, 0, errors.New("jaeger exporter: either agentEndpoint or collectorEndpoint is required")
+	}
+	if meta.AgentEndpoint != "" && meta.CollectorEndpoint != "" {
+		return nil, 0, errors.New("jaeger exporter: agentEndpoint and collectorEndpoint are mutually exclusive")
+	}
+	if meta.AgentEndpoint != "" && (meta.Username != "" || meta.Password != "") {
+		return nil, 0, errors.New("jaeger exporter: basic auth is only supported with collectorEndpoint")
+	}
+
+	options := &jaeger.Options{
+		AgentEndpoint:     meta.AgentEndpoint,
+		CollectorEndpoint: meta.CollectorEndpoint,
+		Username:          meta.Username,
+		Password:          meta.Password,
+		Process: jaeger.Process{
+			ServiceName: daprID,
+		},
+	}
+
+	if hostAddress != "" {
+		options.Process.Tags = append(options.Process.Tags, jaeger.StringTag(hostIPTag, hostAddress))
+	}
+	for _, pair := range strings.Split(meta.ProcessTags, ",") {
+		if strings.TrimSpace(pair) == "" {
+			continue
+		}
+		kv := strings.SplitN(pair, "=", 2)
+		if len(kv) != 2 || strings.TrimSpace(kv[0]) == "" {
+			return nil, 0, fmt.Errorf("jaeger exporter: invalid process tag %s, expected key=value", pair)
+		}
+		options.Process.Tags = append(options.Process.Tags, jaeger.StringTag(strings.TrimSpace(kv[0]), strings.TrimSpace(kv[1])))
+	}
+
+	if meta.BufferMaxCount != "" {
+		count, err := strconv.Atoi(meta.BufferMaxCount)
+		if err != nil || count <= 0 {
+			return nil, 0, fmt.Errorf("jaeger exporter: bufferMaxCount must be a positive integer, got %s", meta.BufferMaxCount)
+		}
+		options.BufferMaxCount = count
+	}
+
+	flushInterval := defaultFlushInterval
+	if meta.FlushInterval != "" {
+		d, err := time.ParseDuration(meta.FlushInterval)
+		if err != nil || d <= 0 {
+			return nil, 0, fmt.Errorf("jaeger exporter: flushInterval must be a positive duration, got %s", meta.FlushInterval)
+		}
+		flushInterval = d
+	}
+
+	return options, flushInterval, nil
+}
+
+func (j *Exporter) getJaegerMetadata(metadata exporters.Metadata) (*jaegerMetadata, error) {
+	b, err := json.Marshal(metadata.Properties)
+	if err != nil {
+		return nil, err
+	}
+
+	var jaegerMeta jaegerMetadata
+	err = json.Unmarshal(b, &jaegerMeta)
+	if err != nil {
+		return nil, err
+	}
+	return &jaegerMeta, nil
+}
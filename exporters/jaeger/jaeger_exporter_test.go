@@ -0,0 +1,84 @@
+// ------------------------------------------------------------
+// Copyright (c) Microsoft Corporation.
+// Licensed under the MIT License.
+// ------------------------------------------------------------
+
+package jaeger
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/dapr/components-contrib/exporters"
+	"github.com/dapr/dapr/pkg/logger"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParseMetadata(t *testing.T) {
+	m := exporters.Metadata{}
+	m.Properties = map[string]string{"collectorEndpoint": "http://jaeger:14268/api/traces", "username": "user"}
+	exporter := NewJaegerExporter(logger.NewLogger("test"))
+	metadata, err := exporter.getJaegerMetadata(m)
+	assert.Nil(t, err)
+	assert.Equal(t, "http://jaeger:14268/api/traces", metadata.CollectorEndpoint)
+	assert.Equal(t, "user", metadata.Username)
+}
+
+func TestGetJaegerOptions(t *testing.T) {
+	t.Run("collector with basic auth", func(t *testing.T) {
+		options, flushInterval, err := getJaegerOptions("myapp", "10.0.0.1", &jaegerMetadata{
+			CollectorEndpoint: "http://jaeger:14268/api/traces",
+			Username:          "user",
+			Password:          "pass",
+			ProcessTags:       "env=prod, region=eu",
+			BufferMaxCount:    "100",
+			FlushInterval:     "5s",
+		})
+		assert.Nil(t, err)
+		assert.Equal(t, "myapp", options.Process.ServiceName)
+		assert.Len(t, options.Process.Tags, 3)
+		assert.Equal(t, "user", options.Username)
+		assert.Equal(t, 100, options.BufferMaxCount)
+		assert.Equal(t, time.Second*5, flushInterval)
+	})
+
+	t.Run("agent with defaults", func(t *testing.T) {
+		options, flushInterval, err := getJaegerOptions("myapp", "", &jaegerMetadata{AgentEndpoint: "localhost:6831"})
+		assert.Nil(t, err)
+		assert.Equal(t, "localhost:6831", options.AgentEndpoint)
+		assert.Len(t, options.Process.Tags, 0)
+		assert.Equal(t, defaultFlushInterval, flushInterval)
+	})
+
+	invalid := map[string]*jaegerMetadata{
+		"no endpoint":        {},
+		"both endpoints":     {AgentEndpoint: "localhost:6831", CollectorEndpoint: "http://jaeger:14268"},
+		"agent with auth":    {AgentEndpoint: "localhost:6831", Username: "user"},
+		"bad tag":            {AgentEndpoint: "localhost:6831", ProcessTags: "env"},
+		"bad buffer count":   {AgentEndpoint: "localhost:6831", BufferMaxCount: "-1"},
+		"bad flush interval": {AgentEndpoint: "localhost:6831", FlushInterval: "later"},
+	}
+	for name, meta := range invalid {
+		t.Run(name, func(t *testing.T) {
+			_, _, err := getJaegerOptions("myapp", "", meta)
+			assert.NotNil(t, err)
+		})
+	}
+}
+
+func TestShutdown(t *testing.T) {
+	exporter := NewJaegerExporter(logger.NewLogger("test"))
+	assert.Nil(t, exporter.Shutdown(context.Background()))
+
+	err := exporter.Init("myapp", "localhost", exporters.Metadata{Properties: map[string]string{
+		"enabled":       "true",
+		"agentEndpoint": "127.0.0.1:6831",
+	}})
+	assert.Nil(t, err)
+	assert.NotNil(t, exporter.exporter)
+
+	assert.Nil(t, exporter.Shutdown(context.Background()))
+	assert.Nil(t, exporter.exporter)
+	assert.Nil(t, exporter.Shutdown(context.Background()))
+}
@@ -0,0 +1,342 @@
+// ------------------------------------------------------------
+// Copyright (c) Microsoft Corporation.
+// Licensed under the MIT License.
+// ------------------------------------------------------------
+
+package jsonexporter
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/dapr/components-contrib/exporters"
+	"github.com/dapr/dapr/pkg/logger"
+	"go.opencensus.io/trace"
+)
+
+const (
+	// Defaults
+	defaultMaxFiles = 5
+)
+
+// Metadata is the json exporter config
+type jsonMetadata struct {
+	Enabled      string `json:"enabled"`
+	Path         string `json:"path"`
+	MaxSizeBytes string `json:"maxSizeBytes"`
+	MaxSpans     string `json:"maxSpans"`
+	MaxFiles     string `json:"maxFiles"`
+	Pretty       string `json:"pretty"`
+}
+
+type exporterConfig struct {
+	path         string
+	maxSizeBytes int64
+	maxSpans     int
+	maxFiles     int
+	pretty       bool
+}
+
+// NewJSONExporter returns a new json exporter instance
+func NewJSONExporter(logger logger.Logger) *Exporter {
+	return &Exporter{logger: logger}
+}
+
+// Exporter is an OpenCensus exporter that writes spans as JSON lines to stdout or a file
+type Exporter struct {
+	logger logger.Logger
+	config *exporterConfig
+
+	lock  sync.Mutex
+	out   io.Writer
+	file  *os.File
+	size  int64
+	spans int
+}
+
+// Init opens the output and registers the exporter
+func (e *Exporter) Init(daprID string, hostAddress string, metadata exporters.Metadata) error {
+	// Shut down a previous registration so re-initializing doesn't write spans twice
+	if err := e.Shutdown(context.Background()); err != nil {
+		e.logger.Warnf("json exporter: error shutting down previous exporter: %s", err)
+	}
+
+	meta, err := e.getJSONMetadata(metadata)
+	if err != nil {
+		return err
+	}
+
+	enabled, _ := strconv.ParseBool(meta.Enabled)
+	if !enabled {
+		return nil
+	}
+
+	err = exporters.ApplySampling(metadata)
+	if err != nil {
+		return err
+	}
+
+	config, err := parseConfig(meta)
+	if err != nil {
+		return err
+	}
+
+	e.lock.Lock()
+	defer e.lock.Unlock()
+
+	e.config = config
+	if config.path == "" {
+		e.out = os.Stdout
+	} else if err = e.openFile(); err != nil {
+		return err
+	}
+
+	trace.RegisterExporter(e)
+	return nil
+}
+
+// Shutdown unregisters the exporter and closes the output file
+func (e *Exporter) Shutdown(ctx context.Context) error {
+	trace.UnregisterExporter(e)
+
+	e.lock.Lock()
+	defer e.lock.Unlock()
+
+	e.out = nil
+	if e.file == nil {
+		return nil
+	}
+	err := e.file.Close()
+	e.file = nil
+	return err
+}
+
+// ExportSpan writes the span as a JSON line
+func (e *Exporter) ExportSpan(sd *trace.SpanData) {
+	e.lock.Lock()
+	defer e.lock.Unlock()
+
+	if e.out == nil {
+		return
+	}
+
+	var b []byte
+	var err error
+	if e.config.pretty {
+		b, err = json.MarshalIndent(newSpan(sd), "", "  ")
+	} else {
+		b, err = json.Marshal(newSpan(sd))
+	}
+	if err != nil {
+		e.logger.Errorf("json exporter: error marshalling span %s: %s", sd.Name, err)
+		return
+	}
+	b = append(b, '\n')
+
+	if e.file != nil && e.shouldRotate(len(b)) {
+		if err = e.rotate(); err != nil {
+			// The current file stays open, so the span is still written
+			e.logger.Errorf("json exporter: error rotating %s, continuing with the current file: %s", e.config.path, err)
+		}
+	}
+
+	n, err := e.out.Write(b)
+	e.size += int64(n)
+	e.spans++
+	if err != nil {
+		e.logger.Errorf("json exporter: error writing span %s: %s", sd.Name, err)
+	}
+}
+
+// shouldRotate reports whether writing n more bytes would exceed the size or span limit of the current file
+func (e *Exporter) shouldRotate(n int) bool {
+	if e.spans == 0 {
+		return false
+	}
+	if e.config.maxSizeBytes > 0 && e.size+int64(n) > e.config.maxSizeBytes {
+		return true
+	}
+	return e.config.maxSpans > 0 && e.spans >= e.config.maxSpans
+}
+
+// rotate renames path to path.1, path.1 to path.2 and so on, dropping files beyond maxFiles,
+// and opens a new file at path. The current file is only closed once the new one is open,
+// so spans keep being written to it when rotating fails.
+func (e *Exporter) rotate() error {
+	if err := e.shiftFiles(); err != nil {
+		return err
+	}
+
+	previous := e.file
+	if err := e.openFile(); err != nil {
+		return err
+	}
+	if err := previous.Close(); err != nil {
+		e.logger.Warnf("json exporter: error closing rotated file: %s", err)
+	}
+	return nil
+}
+
+// shiftFiles moves path and its rotated files one place up, removing the oldest
+func (e *Exporter) shiftFiles() error {
+	if e.config.maxFiles == 0 {
+		if err := os.Remove(e.config.path); err != nil && !os.IsNotExist(err) {
+			return err
+		}
+		return nil
+	}
+
+	oldest := fmt.Sprintf("%s.%d", e.config.path, e.config.maxFiles)
+	if err := os.Remove(oldest); err != nil && !os.IsNotExist(err) {
+		return err
+	}
+	for i := e.config.maxFiles - 1; i > 0; i-- {
+		from := fmt.Sprintf("%s.%d", e.config.path, i)
+		if err := os.Rename(from, fmt.Sprintf("%s.%d", e.config.path, i+1)); err != nil && !os.IsNotExist(err) {
+			return err
+		}
+	}
+	return os.Rename(e.config.path, e.config.path+".1")
+}
+
+func (e *Exporter) openFile() error {
+	f, err := os.OpenFile(e.config.path, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0644)
+	if err != nil {
+		return fmt.Errorf("json exporter: error opening %s: %s", e.config.path, err)
+	}
+	info, err := f.Stat()
+	if err != nil {
+		f.Close()
+		return fmt.Errorf("json exporter: error opening %s: %s", e.config.path, err)
+	}
+
+	e.file = f
+	e.out = f
+	e.size = info.Size()
+	e.spans = 0
+	return nil
+}
+
+func parseConfig(meta *jsonMetadata) (*exporterConfig, error) {
+	config := &exporterConfig{
+		path:     meta.Path,
+		maxFiles: defaultMaxFiles,
+	}
+
+	if meta.MaxSizeBytes != "" {
+		v, err := strconv.ParseInt(meta.MaxSizeBytes, 10, 64)
+		if err != nil || v <= 0 {
+			return nil, fmt.Errorf("json exporter: maxSizeBytes must be a positive integer, got %s", meta.MaxSizeBytes)
+		}
+		config.maxSizeBytes = v
+	}
+	for _, i := range []struct {
+		name   string
+		value  string
+		min    int
+		target *int
+	}{
+		{"maxSpans", meta.MaxSpans, 1, &config.maxSpans},
+		{"maxFiles", meta.MaxFiles, 0, &config.maxFiles},
+	} {
+		if i.value == "" {
+			continue
+		}
+		v, err := strconv.Atoi(i.value)
+		if err != nil || v < i.min {
+			return nil, fmt.Errorf("json exporter: %s must be an integer of at least %d, got %s", i.name, i.min, i.value)
+		}
+		*i.target = v
+	}
+	if config.path == "" && (config.maxSizeBytes > 0 || config.maxSpans > 0) {
+		return nil, fmt.Errorf("json exporter: rotation requires a path")
+	}
+
+	if meta.Pretty != "" {
+		pretty, err := strconv.ParseBool(meta.Pretty)
+		if err != nil {
+			return nil, fmt.Errorf("json exporter: invalid pretty value %s: %s", meta.Pretty, err)
+		}
+		config.pretty = pretty
+	}
+
+	return config, nil
+}
+
+func (e *Exporter) getJSONMetadata(metadata exporters.Metadata) (*jsonMetadata, error) {
+	b, err := json.Marshal(metadata.Properties)
+	if err != nil {
+		return nil, err
+	}
+
+	var jsonMeta jsonMetadata
+	err = json.Unmarshal(b, &jsonMeta)
+	if err != nil {
+		return nil, err
+	}
+	return &jsonMeta, nil
+}
+
+// span is the JSON representation of a span
+type span struct {
+	TraceID      string                 `json:"traceId"`
+	SpanID       string                 `json:"spanId"`
+	ParentSpanID string                 `json:"parentSpanId,omitempty"`
+	Name         string                 `json:"name"`
+	Kind         string                 `json:"kind"`
+	StartTime    time.Time              `json:"startTime"`
+	EndTime      time.Time              `json:"endTime"`
+	Duration     string                 `json:"duration"`
+	Attributes   map[string]interface{} `json:"attributes,omitempty"`
+	Annotations  []annotation           `json:"annotations,omitempty"`
+	Status       status                 `json:"status"`
+}
+
+type annotation struct {
+	Time       time.Time              `json:"time"`
+	Message    string                 `json:"message"`
+	Attributes map[string]interface{} `json:"attributes,omitempty"`
+}
+
+type status struct {
+	Code    int32  `json:"code"`
+	Message string `json:"message,omitempty"`
+}
+
+func newSpan(sd *trace.SpanData) *span {
+	s := &span{
+		TraceID:    sd.TraceID.String(),
+		SpanID:     sd.SpanID.String(),
+		Name:       sd.Name,
+		Kind:       spanKind(sd.SpanKind),
+		StartTime:  sd.StartTime,
+		EndTime:    sd.EndTime,
+		Duration:   sd.EndTime.Sub(sd.StartTime).String(),
+		Attributes: sd.Attributes,
+		Status:     status{Code: sd.Status.Code, Message: sd.Status.Message},
+	}
+	if sd.ParentSpanID != (trace.SpanID{}) {
+		s.ParentSpanID = sd.ParentSpanID.String()
+	}
+	for _, a := range sd.Annotations {
+		s.Annotations = append(s.Annotations, annotation{Time: a.Time, Message: a.Message, Attributes: a.Attributes})
+	}
+	return s
+}
+
+func spanKind(kind int) string {
+	switch kind {
+	case trace.SpanKindServer:
+		return "server"
+	case trace.SpanKindClient:
+		return "client"
+	default:
+		return "unspecified"
+	}
+}
@@ -0,0 +1,146 @@
+// ------------------------------------------------------------
+// Copyright (c) Microsoft Corporation.
+// Licensed under the MIT License.
+// ------------------------------------------------------------
+
+package jsonexporter
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/dapr/components-contrib/exporters"
+	"github.com/dapr/dapr/pkg/logger"
+	"github.com/stretchr/testify/assert"
+	"go.opencensus.io/trace"
+)
+
+func testSpan(name string) *trace.SpanData {
+	start := time.Date(2020, 3, 1, 10, 0, 0, 0, time.UTC)
+	return &trace.SpanData{
+		SpanContext: trace.SpanContext{
+			TraceID: trace.TraceID{1, 2, 3},
+			SpanID:  trace.SpanID{4, 5, 6},
+		},
+		ParentSpanID: trace.SpanID{7},
+		SpanKind:     trace.SpanKindClient,
+		Name:         name,
+		StartTime:    start,
+		EndTime:      start.Add(time.Millisecond * 15),
+		Attributes:   map[string]interface{}{"db.type": "redis"},
+		Annotations:  []trace.Annotation{{Time: start, Message: "connected"}},
+		Status:       trace.Status{Code: 2, Message: "unknown"},
+	}
+}
+
+func TestParseConfig(t *testing.T) {
+	config, err := parseConfig(&jsonMetadata{Path: "spans.json", MaxSizeBytes: "1024", MaxFiles: "0", Pretty: "true"})
+	assert.Nil(t, err)
+	assert.Equal(t, int64(1024), config.maxSizeBytes)
+	assert.Equal(t, 0, config.maxFiles)
+	assert.True(t, config.pretty)
+
+	invalid := map[string]*jsonMetadata{
+		"size":               {Path: "spans.json", MaxSizeBytes: "0"},
+		"spans":              {Path: "spans.json", MaxSpans: "none"},
+		"files":              {Path: "spans.json", MaxFiles: "-1"},
+		"rotation on stdout": {MaxSpans: "10"},
+		"pretty":             {Pretty: "very"},
+	}
+	for name, meta := range invalid {
+		t.Run(name, func(t *testing.T) {
+			_, err := parseConfig(meta)
+			assert.NotNil(t, err)
+		})
+	}
+}
+
+func TestFormatSpan(t *testing.T) {
+	buf := &bytes.Buffer{}
+	e := &Exporter{logger: logger.NewLogger("test"), config: &exporterConfig{}, out: buf}
+	e.ExportSpan(testSpan("span1"))
+
+	var s map[string]interface{}
+	assert.Nil(t, json.Unmarshal(buf.Bytes(), &s))
+	assert.Equal(t, "01020300000000000000000000000000", s["traceId"])
+	assert.Equal(t, "0700000000000000", s["parentSpanId"])
+	assert.Equal(t, "client", s["kind"])
+	assert.Equal(t, "15ms", s["duration"])
+	assert.Equal(t, "redis", s["attributes"].(map[string]interface{})["db.type"])
+	assert.Equal(t, "connected", s["annotations"].([]interface{})[0].(map[string]interface{})["message"])
+	assert.Equal(t, float64(2), s["status"].(map[string]interface{})["code"])
+	assert.Equal(t, 1, strings.Count(buf.String(), "\n"))
+}
+
+func TestPrettyPrint(t *testing.T) {
+	buf := &bytes.Buffer{}
+	e := &Exporter{logger: logger.NewLogger("test"), config: &exporterConfig{pretty: true}, out: buf}
+	e.ExportSpan(testSpan("span1"))
+	assert.True(t, strings.Count(buf.String(), "\n") > 1)
+}
+
+func TestRotateBySpanCount(t *testing.T) {
+	dir, err := ioutil.TempDir("", "jsonexporter")
+	assert.Nil(t, err)
+	defer os.RemoveAll(dir)
+	path := filepath.Join(dir, "spans.json")
+
+	e := NewJSONExporter(logger.NewLogger("test"))
+	err = e.Init("myapp", "localhost", exporters.Metadata{Properties: map[string]string{
+		"enabled":  "true",
+		"path":     path,
+		"maxSpans": "2",
+		"maxFiles": "1",
+	}})
+	assert.Nil(t, err)
+
+	for i := 0; i < 5; i++ {
+		e.ExportSpan(testSpan("span"))
+	}
+	assert.Nil(t, e.Shutdown(context.Background()))
+
+	lines := func(p string) int {
+		b, err := ioutil.ReadFile(p)
+		assert.Nil(t, err)
+		return strings.Count(string(b), "\n")
+	}
+	assert.Equal(t, 1, lines(path))
+	assert.Equal(t, 2, lines(path+".1"))
+	_, err = os.Stat(path + ".2")
+	assert.True(t, os.IsNotExist(err))
+}
+
+func TestFailedRotationKeepsWriting(t *testing.T) {
+	dir, err := ioutil.TempDir("", "jsonexporter")
+	assert.Nil(t, err)
+	defer os.RemoveAll(dir)
+	path := filepath.Join(dir, "spans.json")
+
+	// A non empty directory in place of the oldest file can't be removed, so rotating fails
+	assert.Nil(t, os.MkdirAll(filepath.Join(path+".1", "keep"), 0755))
+
+	e := NewJSONExporter(logger.NewLogger("test"))
+	err = e.Init("myapp", "localhost", exporters.Metadata{Properties: map[string]string{
+		"enabled":  "true",
+		"path":     path,
+		"maxSpans": "1",
+		"maxFiles": "1",
+	}})
+	assert.Nil(t, err)
+
+	for i := 0; i < 3; i++ {
+		e.ExportSpan(testSpan("span"))
+	}
+	assert.Nil(t, e.Shutdown(context.Background()))
+
+	b, err := ioutil.ReadFile(path)
+	assert.Nil(t, err)
+	assert.Equal(t, 3, strings.Count(string(b), "\n"))
+}
@@ -6,6 +6,7 @@
 package native
 
 import (
+	"context"
 	"encoding/json"
 	"strconv"
 
@@ -28,11 +29,17 @@ func NewNativeExporter(logger logger.Logger) *Exporter {
 
 // Exporter is an OpenCensus native exporter
 type Exporter struct {
-	logger logger.Logger
+	logger   logger.Logger
+	exporter *ocagent.Exporter
 }
 
 // Init creates a new native endpoint and reporter
 func (l *Exporter) Init(daprID string, hostAddress string, metadata exporters.Metadata) error {
+	// Shut down a previous registration so re-initializing doesn't export spans twice
+	if err := l.Shutdown(context.Background()); err != nil {
+		l.logger.Warnf("native exporter: error shutting down previous exporter: %s", err)
+	}
+
 	meta, err := l.getNativeMetadata(metadata)
 	if err != nil {
 		return err
@@ -43,16 +50,33 @@ func (l *Exporter) Init(daprID string, hostAddress string, metadata exporters.Me
 		return nil
 	}
 
+	err = exporters.ApplySampling(metadata)
+	if err != nil {
+		return err
+	}
+
 	exporter, err := ocagent.NewExporter(ocagent.WithInsecure(), ocagent.WithServiceName(daprID), ocagent.WithAddress(meta.AgentEndpoint))
 	if err != nil {
 		return err
 	}
 
+	l.exporter = exporter
 	trace.RegisterExporter(exporter)
-	trace.ApplyConfig(trace.Config{DefaultSampler: trace.AlwaysSample()})
 	return nil
 }
 
+// Shutdown unregisters the exporter, then flushes pending spans and closes the agent connection
+func (l *Exporter) Shutdown(ctx context.Context) error {
+	if l.exporter == nil {
+		return nil
+	}
+
+	exporter := l.exporter
+	l.exporter = nil
+	trace.UnregisterExporter(exporter)
+	return exporters.WaitContext(ctx, exporter.Stop)
+}
+
 func (l *Exporter) getNativeMetadata(metadata exporters.Metadata) (*nativeExporterMetadata, error) {
 	b, err := json.Marshal(metadata.Properties)
 	if err != nil {
@@ -0,0 +1,221 @@
+// ------------------------------------------------------------
+// Copyright (c) Microsoft Corporation.
+// Licensed under the MIT License.
+// ------------------------------------------------------------
+
+package otlp
+
+import (
+	"bytes"
+	"compress/gzip"
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"strings"
+
+	"github.com/golang/protobuf/proto"
+	coltracepb "github.com/open-telemetry/opentelemetry-proto/gen/go/collector/trace/v1"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials"
+	grpcgzip "google.golang.org/grpc/encoding/gzip"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+)
+
+// client sends export requests to an OTLP receiver
+type client interface {
+	export(ctx context.Context, req *coltracepb.ExportTraceServiceRequest) error
+	close() error
+}
+
+func newClient(config *exporterConfig) (client, error) {
+	tlsConfig, err := newTLSConfig(config)
+	if err != nil {
+		return nil, err
+	}
+
+	if config.protocol == protocolHTTP {
+		return newHTTPClient(config, tlsConfig), nil
+	}
+	return newGRPCClient(config, tlsConfig)
+}
+
+func newTLSConfig(config *exporterConfig) (*tls.Config, error) {
+	if config.insecure {
+		return nil, nil
+	}
+
+	tlsConfig := &tls.Config{MinVersion: tls.VersionTLS12}
+	if config.caFile != "" {
+		ca, err := ioutil.ReadFile(config.caFile)
+		if err != nil {
+			return nil, fmt.Errorf("otlp exporter: error reading CA file: %s", err)
+		}
+		pool := x509.NewCertPool()
+		if !pool.AppendCertsFromPEM(ca) {
+			return nil, fmt.Errorf("otlp exporter: no certificates found in CA file %s", config.caFile)
+		}
+		tlsConfig.RootCAs = pool
+	}
+	return tlsConfig, nil
+}
+
+type grpcClient struct {
+	conn        *grpc.ClientConn
+	client      coltracepb.TraceServiceClient
+	headers     metadata.MD
+	callOptions []grpc.CallOption
+}
+
+func newGRPCClient(config *exporterConfig, tlsConfig *tls.Config) (*grpcClient, error) {
+	dialOptions := []grpc.DialOption{}
+	if tlsConfig == nil {
+		dialOptions = append(dialOptions, grpc.WithInsecure())
+	} else {
+		dialOptions = append(dialOptions, grpc.WithTransportCredentials(credentials.NewTLS(tlsConfig)))
+	}
+
+	// The connection is established lazily, so an unavailable receiver
+	// doesn't fail Init but is retried when spans are exported
+	conn, err := grpc.Dial(config.endpoint, dialOptions...)
+	if err != nil {
+		return nil, fmt.Errorf("otlp exporter: error connecting to %s: %s", config.endpoint, err)
+	}
+
+	var callOptions []grpc.CallOption
+	if config.compression == compressionGzip {
+		callOptions = append(callOptions, grpc.UseCompressor(grpcgzip.Name))
+	}
+
+	return &grpcClient{
+		conn:        conn,
+		client:      coltracepb.NewTraceServiceClient(conn),
+		headers:     metadata.New(config.headers),
+		callOptions: callOptions,
+	}, nil
+}
+
+func (c *grpcClient) export(ctx context.Context, req *coltracepb.ExportTraceServiceRequest) error {
+	if len(c.headers) > 0 {
+		ctx = metadata.NewOutgoingContext(ctx, c.headers)
+	}
+	_, err := c.client.Export(ctx, req, c.callOptions...)
+	return err
+}
+
+func (c *grpcClient) close() error {
+	return c.conn.Close()
+}
+
+type httpClient struct {
+	endpoint    string
+	headers     map[string]string
+	compression string
+	client      *http.Client
+}
+
+func newHTTPClient(config *exporterConfig, tlsConfig *tls.Config) *httpClient {
+	endpoint := config.endpoint
+	if !strings.HasPrefix(endpoint, "http://") && !strings.HasPrefix(endpoint, "https://") {
+		if config.insecure {
+			endpoint = "http://" + endpoint
+		} else {
+			endpoint = "https://" + endpoint
+		}
+	}
+
+	return &httpClient{
+		endpoint:    endpoint,
+		headers:     config.headers,
+		compression: config.compression,
+		client: &http.Client{
+			Timeout:   config.timeout,
+			Transport: &http.Transport{TLSClientConfig: tlsConfig},
+		},
+	}
+}
+
+func (c *httpClient) export(ctx context.Context, req *coltracepb.ExportTraceServiceRequest) error {
+	body, err := proto.Marshal(req)
+	if err != nil {
+		return err
+	}
+
+	if c.compression == compressionGzip {
+		var buf bytes.Buffer
+		gz := gzip.NewWriter(&buf)
+		if _, err = gz.Write(body); err != nil {
+			return err
+		}
+		if err = gz.Close(); err != nil {
+			return err
+		}
+		body = buf.Bytes()
+	}
+
+	httpReq, err := http.NewRequest(http.MethodPost, c.endpoint, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	httpReq = httpReq.WithContext(ctx)
+	httpReq.Header.Set("Content-Type", "application/x-protobuf")
+	if c.compression == compressionGzip {
+		httpReq.Header.Set("Content-Encoding", "gzip")
+	}
+	for k, v := range c.headers {
+		httpReq.Header.Set(k, v)
+	}
+
+	resp, err := c.client.Do(httpReq)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	io.Copy(ioutil.Discard, resp.Body) //nolint:errcheck
+
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return &httpStatusError{statusCode: resp.StatusCode}
+	}
+	return nil
+}
+
+func (c *httpClient) close() error {
+	c.client.CloseIdleConnections()
+	return nil
+}
+
+// httpStatusError is returned when the receiver responds with a non-2xx status code
+type httpStatusError struct {
+	statusCode int
+}
+
+func (e *httpStatusError) Error() string {
+	return fmt.Sprintf("otlp receiver responded with status code %d", e.statusCode)
+}
+
+// isRetriable reports whether an export error is transient and worth retrying
+func isRetriable(err error) bool {
+	if e, ok := err.(*httpStatusError); ok {
+		switch e.statusCode {
+		case http.StatusTooManyRequests, http.StatusBadGateway, http.StatusServiceUnavailable, http.StatusGatewayTimeout:
+			return true
+		}
+		return false
+	}
+
+	if s, ok := status.FromError(err); ok {
+		switch s.Code() {
+		case codes.Unavailable, codes.DeadlineExceeded, codes.ResourceExhausted, codes.Aborted:
+			return true
+		}
+		return false
+	}
+
+	// Network errors from the HTTP client
+	return true
+}
@@ -0,0 +1,369 @@
+// ------------------------------------------------------------
+// Copyright (c) Microsoft Corporation.
+// Licensed under the MIT License.
+// ------------------------------------------------------------
+
+package otlp
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/dapr/components-contrib/exporters"
+	"github.com/dapr/dapr/pkg/logger"
+	"go.opencensus.io/trace"
+)
+
+const (
+	protocolGRPC = "grpc"
+	protocolHTTP = "http"
+
+	compressionGzip = "gzip"
+	compressionNone = "none"
+
+	// Defaults
+	defaultGRPCEndpoint   = "localhost:4317"
+	defaultHTTPEndpoint   = "http://localhost:4318/v1/traces"
+	defaultTimeout        = time.Second * 10
+	defaultMaxBatchSize   = 512
+	defaultBatchTimeout   = time.Second * 5
+	defaultMaxQueueSize   = 2048
+	defaultMaxRetries     = 5
+	defaultRetryInterval  = time.Second
+	defaultMaxRetryPeriod = time.Second * 30
+)
+
+// Metadata is the OTLP exporter config
+type otlpExporterMetadata struct {
+	Enabled        string `json:"enabled"`
+	Endpoint       string `json:"endpoint"`
+	Protocol       string `json:"protocol"`
+	Headers        string `json:"headers"`
+	Insecure       string `json:"insecure"`
+	CAFile         string `json:"caFile"`
+	Compression    string `json:"compression"`
+	Timeout        string `json:"timeout"`
+	MaxBatchSize   string `json:"maxBatchSize"`
+	BatchTimeout   string `json:"batchTimeout"`
+	MaxQueueSize   string `json:"maxQueueSize"`
+	MaxRetries     string `json:"maxRetries"`
+	RetryInterval  string `json:"retryInterval"`
+	MaxRetryPeriod string `json:"maxRetryPeriod"`
+}
+
+// exporterConfig is the parsed and validated OTLP exporter config
+type exporterConfig struct {
+	endpoint       string
+	protocol       string
+	headers        map[string]string
+	insecure       bool
+	caFile         string
+	compression    string
+	timeout        time.Duration
+	maxBatchSize   int
+	batchTimeout   time.Duration
+	maxQueueSize   int
+	maxRetries     int
+	retryInterval  time.Duration
+	maxRetryPeriod time.Duration
+}
+
+// NewOTLPExporter returns a new OTLP exporter instance
+func NewOTLPExporter(logger logger.Logger) *Exporter {
+	return &Exporter{logger: logger}
+}
+
+// Exporter is an OpenCensus exporter that sends spans using the OpenTelemetry protocol
+type Exporter struct {
+	logger  logger.Logger
+	daprID  string
+	config  *exporterConfig
+	client  client
+	queue   chan *trace.SpanData
+	stopCh  chan struct{}
+	stopped sync.WaitGroup
+}
+
+// Init creates a new OTLP client and starts the batching worker
+func (o *Exporter) Init(daprID string, hostAddress string, metadata exporters.Metadata) error {
+	// Shut down a previous registration so re-initializing doesn't start a second worker
+	if err := o.Shutdown(context.Background()); err != nil {
+		o.logger.Warnf("otlp exporter: error shutting down previous exporter: %s", err)
+	}
+
+	meta, err := o.getOTLPMetadata(metadata)
+	if err != nil {
+		return err
+	}
+
+	enabled, _ := strconv.ParseBool(meta.Enabled)
+	if !enabled {
+		return nil
+	}
+
+	err = exporters.ApplySampling(metadata)
+	if err != nil {
+		return err
+	}
+
+	config, err := parseConfig(meta)
+	if err != nil {
+		return err
+	}
+
+	c, err := newClient(config)
+	if err != nil {
+		return err
+	}
+
+	o.daprID = daprID
+	o.config = config
+	o.client = c
+	o.queue = make(chan *trace.SpanData, config.maxQueueSize)
+	o.stopCh = make(chan struct{})
+	o.stopped.Add(1)
+	go o.run()
+
+	trace.RegisterExporter(o)
+	return nil
+}
+
+// Shutdown unregisters the exporter, sends the queued spans and closes the client
+func (o *Exporter) Shutdown(ctx context.Context) error {
+	if o.stopCh == nil {
+		return nil
+	}
+	select {
+	case <-o.stopCh:
+		// Already shut down
+		return nil
+	default:
+	}
+
+	trace.UnregisterExporter(o)
+	close(o.stopCh)
+	err := exporters.WaitContext(ctx, func() error {
+		o.stopped.Wait()
+		return nil
+	})
+
+	// Closing the client also aborts an export still in flight when ctx expired
+	if closeErr := o.client.close(); err == nil {
+		err = closeErr
+	}
+	return err
+}
+
+// ExportSpan queues a span for export. Spans are dropped when the queue is full.
+func (o *Exporter) ExportSpan(sd *trace.SpanData) {
+	select {
+	case o.queue <- sd:
+	default:
+		o.logger.Warnf("otlp exporter: queue is full, dropping span %s", sd.Name)
+	}
+}
+
+// run batches queued spans and sends them when a batch is full or the batch timeout expires
+func (o *Exporter) run() {
+	defer o.stopped.Done()
+
+	ticker := time.NewTicker(o.config.batchTimeout)
+	defer ticker.Stop()
+
+	batch := make([]*trace.SpanData, 0, o.config.maxBatchSize)
+	flush := func() {
+		if len(batch) == 0 {
+			return
+		}
+		o.send(batch)
+		batch = make([]*trace.SpanData, 0, o.config.maxBatchSize)
+	}
+
+	for {
+		select {
+		case sd := <-o.queue:
+			batch = append(batch, sd)
+			if len(batch) >= o.config.maxBatchSize {
+				flush()
+			}
+		case <-ticker.C:
+			flush()
+		case <-o.stopCh:
+			for {
+				select {
+				case sd := <-o.queue:
+					batch = append(batch, sd)
+					if len(batch) >= o.config.maxBatchSize {
+						flush()
+					}
+				default:
+					flush()
+					return
+				}
+			}
+		}
+	}
+}
+
+// send exports a batch, retrying with exponential backoff on retriable errors
+func (o *Exporter) send(batch []*trace.SpanData) {
+	req := newExportRequest(o.daprID, batch)
+	interval := o.config.retryInterval
+	deadline := time.Now().Add(o.config.maxRetryPeriod)
+
+	for attempt := 0; ; attempt++ {
+		ctx, cancel := context.WithTimeout(context.Background(), o.config.timeout)
+		err := o.client.export(ctx, req)
+		cancel()
+		if err == nil {
+			return
+		}
+
+		if !isRetriable(err) || attempt >= o.config.maxRetries || time.Now().Add(interval).After(deadline) {
+			o.logger.Errorf("otlp exporter: dropping %d spans: %s", len(batch), err)
+			return
+		}
+
+		o.logger.Debugf("otlp exporter: export failed, retrying in %s: %s", interval, err)
+		select {
+		case <-time.After(interval):
+		case <-o.stopCh:
+			// Stop backing off when shutting down, but make one last attempt
+			ctx, cancel := context.WithTimeout(context.Background(), o.config.timeout)
+			defer cancel()
+			if err = o.client.export(ctx, req); err != nil {
+				o.logger.Errorf("otlp exporter: dropping %d spans on shutdown: %s", len(batch), err)
+			}
+			return
+		}
+		interval *= 2
+	}
+}
+
+func parseConfig(meta *otlpExporterMetadata) (*exporterConfig, error) {
+	config := &exporterConfig{
+		protocol:       protocolGRPC,
+		compression:    compressionNone,
+		timeout:        defaultTimeout,
+		maxBatchSize:   defaultMaxBatchSize,
+		batchTimeout:   defaultBatchTimeout,
+		maxQueueSize:   defaultMaxQueueSize,
+		maxRetries:     defaultMaxRetries,
+		retryInterval:  defaultRetryInterval,
+		maxRetryPeriod: defaultMaxRetryPeriod,
+		caFile:         meta.CAFile,
+	}
+
+	if meta.Protocol != "" {
+		config.protocol = strings.ToLower(meta.Protocol)
+	}
+	switch config.protocol {
+	case protocolGRPC:
+		config.endpoint = defaultGRPCEndpoint
+	case protocolHTTP:
+		config.endpoint = defaultHTTPEndpoint
+	default:
+		return nil, fmt.Errorf("otlp exporter: unsupported protocol %s", meta.Protocol)
+	}
+	if meta.Endpoint != "" {
+		config.endpoint = meta.Endpoint
+	}
+
+	if meta.Compression != "" {
+		config.compression = strings.ToLower(meta.Compression)
+	}
+	if config.compression != compressionGzip && config.compression != compressionNone {
+		return nil, fmt.Errorf("otlp exporter: unsupported compression %s", meta.Compression)
+	}
+
+	if meta.Insecure != "" {
+		insecure, err := strconv.ParseBool(meta.Insecure)
+		if err != nil {
+			return nil, fmt.Errorf("otlp exporter: invalid insecure value %s: %s", meta.Insecure, err)
+		}
+		config.insecure = insecure
+	}
+
+	headers, err := parseHeaders(meta.Headers)
+	if err != nil {
+		return nil, err
+	}
+	config.headers = headers
+
+	for _, d := range []struct {
+		name   string
+		value  string
+		target *time.Duration
+	}{
+		{"timeout", meta.Timeout, &config.timeout},
+		{"batchTimeout", meta.BatchTimeout, &config.batchTimeout},
+		{"retryInterval", meta.RetryInterval, &config.retryInterval},
+		{"maxRetryPeriod", meta.MaxRetryPeriod, &config.maxRetryPeriod},
+	} {
+		if d.value == "" {
+			continue
+		}
+		v, err := time.ParseDuration(d.value)
+		if err != nil || v <= 0 {
+			return nil, fmt.Errorf("otlp exporter: %s must be a positive duration, got %s", d.name, d.value)
+		}
+		*d.target = v
+	}
+
+	for _, i := range []struct {
+		name   string
+		value  string
+		min    int
+		target *int
+	}{
+		{"maxBatchSize", meta.MaxBatchSize, 1, &config.maxBatchSize},
+		{"maxQueueSize", meta.MaxQueueSize, 1, &config.maxQueueSize},
+		{"maxRetries", meta.MaxRetries, 0, &config.maxRetries},
+	} {
+		if i.value == "" {
+			continue
+		}
+		v, err := strconv.Atoi(i.value)
+		if err != nil || v < i.min {
+			return nil, fmt.Errorf("otlp exporter: %s must be an integer of at least %d, got %s", i.name, i.min, i.value)
+		}
+		*i.target = v
+	}
+
+	return config, nil
+}
+
+// parseHeaders parses a comma separated list of key=value pairs
+func parseHeaders(val string) (map[string]string, error) {
+	headers := map[string]string{}
+	for _, pair := range strings.Split(val, ",") {
+		if strings.TrimSpace(pair) == "" {
+			continue
+		}
+		kv := strings.SplitN(pair, "=", 2)
+		if len(kv) != 2 || strings.TrimSpace(kv[0]) == "" {
+			return nil, fmt.Errorf("otlp exporter: invalid header %s, expected key=value", pair)
+		}
+		headers[strings.TrimSpace(kv[0])] = strings.TrimSpace(kv[1])
+	}
+	return headers, nil
+}
+
+func (o *Exporter) getOTLPMetadata(metadata exporters.Metadata) (*otlpExporterMetadata, error) {
+	b, err := json.Marshal(metadata.Properties)
+	if err != nil {
+		return nil, err
+	}
+
+	var otlpMeta otlpExporterMetadata
+	err = json.Unmarshal(b, &otlpMeta)
+	if err != nil {
+		return nil, err
+	}
+	return &otlpMeta, nil
+}
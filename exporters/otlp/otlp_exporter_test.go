@@ -0,0 +1,244 @@
+// ------------------------------------------------------------
+// Copyright (c) Microsoft Corporation.
+// Licensed under the MIT License.
+// ------------------------------------------------------------
+
+package otlp
+
+import (
+	"compress/gzip"
+	"context"
+	"io/ioutil"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/dapr/components-contrib/exporters"
+	"github.com/dapr/dapr/pkg/logger"
+	"github.com/golang/protobuf/proto"
+	coltracepb "github.com/open-telemetry/opentelemetry-proto/gen/go/collector/trace/v1"
+	"github.com/stretchr/testify/assert"
+	"go.opencensus.io/trace"
+	"google.golang.org/grpc"
+	grpcmetadata "google.golang.org/grpc/metadata"
+)
+
+type testReceiver struct {
+	coltracepb.UnimplementedTraceServiceServer
+	requests chan *coltracepb.ExportTraceServiceRequest
+	headers  chan grpcmetadata.MD
+}
+
+func (r *testReceiver) Export(ctx context.Context, req *coltracepb.ExportTraceServiceRequest) (*coltracepb.ExportTraceServiceResponse, error) {
+	md, _ := grpcmetadata.FromIncomingContext(ctx)
+	r.headers <- md
+	r.requests <- req
+	return &coltracepb.ExportTraceServiceResponse{}, nil
+}
+
+func testSpan(name string) *trace.SpanData {
+	return &trace.SpanData{
+		SpanContext: trace.SpanContext{
+			TraceID: trace.TraceID{1, 2, 3},
+			SpanID:  trace.SpanID{4, 5, 6},
+		},
+		SpanKind:   trace.SpanKindServer,
+		Name:       name,
+		StartTime:  time.Now().Add(-time.Second),
+		EndTime:    time.Now(),
+		Attributes: map[string]interface{}{"http.method": "GET"},
+		Status:     trace.Status{Code: 2, Message: "unknown"},
+	}
+}
+
+func TestParseMetadata(t *testing.T) {
+	m := exporters.Metadata{}
+	m.Properties = map[string]string{"endpoint": "collector:4317", "headers": "api-key=secret, tenant=a", "maxBatchSize": "10"}
+	exporter := NewOTLPExporter(logger.NewLogger("test"))
+	metadata, err := exporter.getOTLPMetadata(m)
+	assert.Nil(t, err)
+	assert.Equal(t, "collector:4317", metadata.Endpoint)
+
+	config, err := parseConfig(metadata)
+	assert.Nil(t, err)
+	assert.Equal(t, protocolGRPC, config.protocol)
+	assert.Equal(t, map[string]string{"api-key": "secret", "tenant": "a"}, config.headers)
+	assert.Equal(t, 10, config.maxBatchSize)
+	assert.Equal(t, defaultMaxQueueSize, config.maxQueueSize)
+}
+
+func TestParseInvalidMetadata(t *testing.T) {
+	tests := map[string]*otlpExporterMetadata{
+		"protocol":    {Protocol: "thrift"},
+		"compression": {Compression: "zstd"},
+		"headers":     {Headers: "novalue"},
+		"batch size":  {MaxBatchSize: "0"},
+		"timeout":     {Timeout: "soon"},
+		"insecure":    {Insecure: "maybe"},
+	}
+	for name, meta := range tests {
+		t.Run(name, func(t *testing.T) {
+			_, err := parseConfig(meta)
+			assert.NotNil(t, err)
+		})
+	}
+}
+
+func TestConvertSpan(t *testing.T) {
+	req := newExportRequest("myapp", []*trace.SpanData{testSpan("span1")})
+	assert.Len(t, req.ResourceSpans, 1)
+	assert.Equal(t, "myapp", req.ResourceSpans[0].Resource.Attributes[0].Value.GetStringValue())
+
+	span := req.ResourceSpans[0].InstrumentationLibrarySpans[0].Spans[0]
+	assert.Equal(t, "span1", span.Name)
+	assert.Equal(t, []byte{1, 2, 3, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0}, span.TraceId)
+	assert.Nil(t, span.ParentSpanId)
+	assert.Equal(t, "GET", span.Attributes[0].Value.GetStringValue())
+	assert.Equal(t, "UnknownError", span.Status.Code.String())
+	assert.Equal(t, "SERVER", span.Kind.String())
+}
+
+func TestExportGRPC(t *testing.T) {
+	lis, err := net.Listen("tcp", "127.0.0.1:0")
+	assert.Nil(t, err)
+	receiver := &testReceiver{
+		requests: make(chan *coltracepb.ExportTraceServiceRequest, 1),
+		headers:  make(chan grpcmetadata.MD, 1),
+	}
+	server := grpc.NewServer()
+	coltracepb.RegisterTraceServiceServer(server, receiver)
+	go server.Serve(lis) //nolint:errcheck
+	defer server.Stop()
+
+	config, err := parseConfig(&otlpExporterMetadata{
+		Endpoint:    lis.Addr().String(),
+		Insecure:    "true",
+		Headers:     "api-key=secret",
+		Compression: "gzip",
+	})
+	assert.Nil(t, err)
+	c, err := newClient(config)
+	assert.Nil(t, err)
+	defer c.close()
+
+	err = c.export(context.Background(), newExportRequest("myapp", []*trace.SpanData{testSpan("span1")}))
+	assert.Nil(t, err)
+
+	req := <-receiver.requests
+	assert.Equal(t, "span1", req.ResourceSpans[0].InstrumentationLibrarySpans[0].Spans[0].Name)
+	md := <-receiver.headers
+	assert.Equal(t, []string{"secret"}, md.Get("api-key"))
+}
+
+func TestExportHTTP(t *testing.T) {
+	requests := make(chan *coltracepb.ExportTraceServiceRequest, 1)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "application/x-protobuf", r.Header.Get("Content-Type"))
+		assert.Equal(t, "secret", r.Header.Get("api-key"))
+		assert.Equal(t, "gzip", r.Header.Get("Content-Encoding"))
+
+		gz, err := gzip.NewReader(r.Body)
+		assert.Nil(t, err)
+		body, err := ioutil.ReadAll(gz)
+		assert.Nil(t, err)
+
+		var req coltracepb.ExportTraceServiceRequest
+		assert.Nil(t, proto.Unmarshal(body, &req))
+		requests <- &req
+	}))
+	defer server.Close()
+
+	exporter := NewOTLPExporter(logger.NewLogger("test"))
+	err := exporter.Init("myapp", "localhost", exporters.Metadata{Properties: map[string]string{
+		"enabled":      "true",
+		"protocol":     "http",
+		"endpoint":     server.URL + "/v1/traces",
+		"insecure":     "true",
+		"headers":      "api-key=secret",
+		"compression":  "gzip",
+		"maxBatchSize": "2",
+	}})
+	assert.Nil(t, err)
+	defer exporter.Shutdown(context.Background()) //nolint:errcheck
+
+	exporter.ExportSpan(testSpan("span1"))
+	exporter.ExportSpan(testSpan("span2"))
+
+	select {
+	case req := <-requests:
+		spans := req.ResourceSpans[0].InstrumentationLibrarySpans[0].Spans
+		assert.Len(t, spans, 2)
+	case <-time.After(time.Second * 5):
+		assert.Fail(t, "spans were not exported")
+	}
+}
+
+func TestShutdownFlushesQueue(t *testing.T) {
+	requests := make(chan *coltracepb.ExportTraceServiceRequest, 1)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, err := ioutil.ReadAll(r.Body)
+		assert.Nil(t, err)
+
+		var req coltracepb.ExportTraceServiceRequest
+		assert.Nil(t, proto.Unmarshal(body, &req))
+		requests <- &req
+	}))
+	defer server.Close()
+
+	exporter := NewOTLPExporter(logger.NewLogger("test"))
+	err := exporter.Init("myapp", "localhost", exporters.Metadata{Properties: map[string]string{
+		"enabled":      "true",
+		"protocol":     "http",
+		"endpoint":     server.URL,
+		"insecure":     "true",
+		"batchTimeout": "1h",
+	}})
+	assert.Nil(t, err)
+
+	exporter.ExportSpan(testSpan("span1"))
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second*5)
+	defer cancel()
+	assert.Nil(t, exporter.Shutdown(ctx))
+
+	select {
+	case req := <-requests:
+		assert.Equal(t, "span1", req.ResourceSpans[0].InstrumentationLibrarySpans[0].Spans[0].Name)
+	default:
+		assert.Fail(t, "queued span was not exported on shutdown")
+	}
+
+	// Shutting down again is a no-op
+	assert.Nil(t, exporter.Shutdown(context.Background()))
+}
+
+func TestRetryOnUnavailable(t *testing.T) {
+	attempts := 0
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		attempts++
+		if attempts < 3 {
+			w.WriteHeader(http.StatusServiceUnavailable)
+		}
+	}))
+	defer server.Close()
+
+	config, err := parseConfig(&otlpExporterMetadata{
+		Protocol:      "http",
+		Endpoint:      server.URL,
+		Insecure:      "true",
+		RetryInterval: "1ms",
+	})
+	assert.Nil(t, err)
+	c, err := newClient(config)
+	assert.Nil(t, err)
+
+	exporter := &Exporter{logger: logger.NewLogger("test"), config: config, client: c, stopCh: make(chan struct{})}
+	exporter.send([]*trace.SpanData{testSpan("span1")})
+	assert.Equal(t, 3, attempts)
+}
+
+func TestIsRetriable(t *testing.T) {
+	assert.True(t, isRetriable(&httpStatusError{statusCode: http.StatusTooManyRequests}))
+	assert.False(t, isRetriable(&httpStatusError{statusCode: http.StatusBadRequest}))
+}
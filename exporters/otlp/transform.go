@@ -0,0 +1,158 @@
+// ------------------------------------------------------------
+// Copyright (c) Microsoft Corporation.
+// Licensed under the MIT License.
+// ------------------------------------------------------------
+
+package otlp
+
+import (
+	"fmt"
+	"time"
+
+	coltracepb "github.com/open-telemetry/opentelemetry-proto/gen/go/collector/trace/v1"
+	commonpb "github.com/open-telemetry/opentelemetry-proto/gen/go/common/v1"
+	resourcepb "github.com/open-telemetry/opentelemetry-proto/gen/go/resource/v1"
+	tracepb "github.com/open-telemetry/opentelemetry-proto/gen/go/trace/v1"
+	"go.opencensus.io/trace"
+)
+
+const (
+	serviceNameKey         = "service.name"
+	instrumentationLibrary = "dapr"
+)
+
+// newExportRequest converts OpenCensus spans to an OTLP export request for a single service
+func newExportRequest(serviceName string, spans []*trace.SpanData) *coltracepb.ExportTraceServiceRequest {
+	otlpSpans := make([]*tracepb.Span, 0, len(spans))
+	for _, sd := range spans {
+		otlpSpans = append(otlpSpans, convertSpan(sd))
+	}
+
+	return &coltracepb.ExportTraceServiceRequest{
+		ResourceSpans: []*tracepb.ResourceSpans{
+			{
+				Resource: &resourcepb.Resource{
+					Attributes: []*commonpb.KeyValue{stringKeyValue(serviceNameKey, serviceName)},
+				},
+				InstrumentationLibrarySpans: []*tracepb.InstrumentationLibrarySpans{
+					{
+						InstrumentationLibrary: &commonpb.InstrumentationLibrary{Name: instrumentationLibrary},
+						Spans:                  otlpSpans,
+					},
+				},
+			},
+		},
+	}
+}
+
+func convertSpan(sd *trace.SpanData) *tracepb.Span {
+	span := &tracepb.Span{
+		TraceId:                sd.TraceID[:],
+		SpanId:                 sd.SpanID[:],
+		Name:                   sd.Name,
+		Kind:                   convertSpanKind(sd.SpanKind),
+		StartTimeUnixNano:      unixNano(sd.StartTime),
+		EndTimeUnixNano:        unixNano(sd.EndTime),
+		Attributes:             convertAttributes(sd.Attributes),
+		DroppedAttributesCount: uint32(sd.DroppedAttributeCount),
+		DroppedEventsCount:     uint32(sd.DroppedAnnotationCount + sd.DroppedMessageEventCount),
+		DroppedLinksCount:      uint32(sd.DroppedLinkCount),
+		Status:                 convertStatus(sd.Status),
+	}
+
+	if sd.ParentSpanID != (trace.SpanID{}) {
+		span.ParentSpanId = sd.ParentSpanID[:]
+	}
+
+	for _, a := range sd.Annotations {
+		span.Events = append(span.Events, &tracepb.Span_Event{
+			TimeUnixNano: unixNano(a.Time),
+			Name:         a.Message,
+			Attributes:   convertAttributes(a.Attributes),
+		})
+	}
+	for _, m := range sd.MessageEvents {
+		name := "message"
+		switch m.EventType {
+		case trace.MessageEventTypeSent:
+			name = "message.sent"
+		case trace.MessageEventTypeRecv:
+			name = "message.received"
+		}
+		span.Events = append(span.Events, &tracepb.Span_Event{
+			TimeUnixNano: unixNano(m.Time),
+			Name:         name,
+			Attributes: []*commonpb.KeyValue{
+				intKeyValue("message.id", m.MessageID),
+				intKeyValue("message.uncompressed_size", m.UncompressedByteSize),
+				intKeyValue("message.compressed_size", m.CompressedByteSize),
+			},
+		})
+	}
+	for _, l := range sd.Links {
+		traceID, spanID := l.TraceID, l.SpanID
+		span.Links = append(span.Links, &tracepb.Span_Link{
+			TraceId:    traceID[:],
+			SpanId:     spanID[:],
+			Attributes: convertAttributes(l.Attributes),
+		})
+	}
+
+	return span
+}
+
+func convertSpanKind(kind int) tracepb.Span_SpanKind {
+	switch kind {
+	case trace.SpanKindServer:
+		return tracepb.Span_SERVER
+	case trace.SpanKindClient:
+		return tracepb.Span_CLIENT
+	default:
+		return tracepb.Span_INTERNAL
+	}
+}
+
+// convertStatus converts the OpenCensus status, both use gRPC codes
+func convertStatus(s trace.Status) *tracepb.Status {
+	return &tracepb.Status{Code: tracepb.Status_StatusCode(s.Code), Message: s.Message}
+}
+
+func convertAttributes(attributes map[string]interface{}) []*commonpb.KeyValue {
+	if len(attributes) == 0 {
+		return nil
+	}
+
+	kvs := make([]*commonpb.KeyValue, 0, len(attributes))
+	for k, v := range attributes {
+		var value *commonpb.AnyValue
+		switch t := v.(type) {
+		case string:
+			value = &commonpb.AnyValue{Value: &commonpb.AnyValue_StringValue{StringValue: t}}
+		case bool:
+			value = &commonpb.AnyValue{Value: &commonpb.AnyValue_BoolValue{BoolValue: t}}
+		case int64:
+			value = &commonpb.AnyValue{Value: &commonpb.AnyValue_IntValue{IntValue: t}}
+		case float64:
+			value = &commonpb.AnyValue{Value: &commonpb.AnyValue_DoubleValue{DoubleValue: t}}
+		default:
+			value = &commonpb.AnyValue{Value: &commonpb.AnyValue_StringValue{StringValue: fmt.Sprintf("%v", t)}}
+		}
+		kvs = append(kvs, &commonpb.KeyValue{Key: k, Value: value})
+	}
+	return kvs
+}
+
+func stringKeyValue(key, value string) *commonpb.KeyValue {
+	return &commonpb.KeyValue{Key: key, Value: &commonpb.AnyValue{Value: &commonpb.AnyValue_StringValue{StringValue: value}}}
+}
+
+func intKeyValue(key string, value int64) *commonpb.KeyValue {
+	return &commonpb.KeyValue{Key: key, Value: &commonpb.AnyValue{Value: &commonpb.AnyValue_IntValue{IntValue: value}}}
+}
+
+func unixNano(t time.Time) uint64 {
+	if t.IsZero() {
+		return 0
+	}
+	return uint64(t.UnixNano())
+}
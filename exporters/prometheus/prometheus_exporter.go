@@ -0,0 +1,243 @@
+// ------------------------------------------------------------
+// Copyright (c) Microsoft Corporation.
+// Licensed under the MIT License.
+// ------------------------------------------------------------
+
+package prometheus
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net"
+	"net/http"
+	"regexp"
+	"strconv"
+	"strings"
+	"sync"
+
+	ocprometheus "contrib.go.opencensus.io/exporter/prometheus"
+	"github.com/dapr/components-contrib/exporters"
+	"github.com/dapr/dapr/pkg/logger"
+	prom "github.com/prometheus/client_golang/prometheus"
+)
+
+const (
+	// appIDLabel is the constant label holding the dapr app id
+	appIDLabel = "app_id"
+
+	// Defaults
+	defaultAddress   = ":9090"
+	defaultPath      = "/metrics"
+	defaultNamespace = "dapr"
+)
+
+var labelNameRegex = regexp.MustCompile(`^[a-zA-Z_][a-zA-Z0-9_]*$`)
+
+// Metadata is the prometheus exporter config
+type prometheusMetadata struct {
+	Enabled     string `json:"enabled"`
+	Address     string `json:"address"`
+	Path        string `json:"path"`
+	Namespace   string `json:"namespace"`
+	ConstLabels string `json:"constLabels"`
+}
+
+// Mux is the subset of http.ServeMux used to register the metrics handler
+type Mux interface {
+	Handle(pattern string, handler http.Handler)
+}
+
+// NewPrometheusExporter returns a new prometheus exporter instance that serves metrics on its own address
+func NewPrometheusExporter(logger logger.Logger) *Exporter {
+	return &Exporter{logger: logger}
+}
+
+// NewPrometheusExporterWithMux returns a new prometheus exporter instance that registers
+// its metrics handler on mux instead of starting a server
+func NewPrometheusExporterWithMux(logger logger.Logger, mux Mux) *Exporter {
+	return &Exporter{logger: logger, mux: mux, registered: map[string]bool{}}
+}
+
+// Exporter serves OpenCensus stats for Prometheus scraping
+type Exporter struct {
+	logger   logger.Logger
+	mux      Mux
+	exporter *ocprometheus.Exporter
+	server   *http.Server
+	listener net.Listener
+
+	// A mux can't unregister a handler and panics on duplicate patterns, so each path
+	// is registered once and serves the current exporter while it is the configured path
+	registered map[string]bool
+	lock       sync.RWMutex
+	path       string
+}
+
+// Init creates the OpenCensus prometheus exporter and serves it on the configured path
+func (p *Exporter) Init(daprID string, hostAddress string, metadata exporters.Metadata) error {
+	// Stop a previous server so re-initializing releases its address
+	if err := p.Shutdown(context.Background()); err != nil {
+		p.logger.Warnf("prometheus exporter: error shutting down previous server: %s", err)
+	}
+
+	meta, err := p.getPrometheusMetadata(metadata)
+	if err != nil {
+		return err
+	}
+
+	if meta.Enabled == "" {
+		return nil
+	}
+	enabled, err := strconv.ParseBool(meta.Enabled)
+	if err != nil {
+		return fmt.Errorf("prometheus exporter: invalid enabled value %s: %s", meta.Enabled, err)
+	}
+	if !enabled {
+		return nil
+	}
+
+	options, err := getOptions(daprID, meta)
+	if err != nil {
+		return err
+	}
+	options.OnError = func(err error) {
+		p.logger.Errorf("prometheus exporter: %s", err)
+	}
+
+	// The exporter reads the registered views through the OpenCensus metric
+	// producer on every scrape, so it isn't registered with view.RegisterExporter.
+	exporter, err := ocprometheus.NewExporter(*options)
+	if err != nil {
+		return err
+	}
+
+	path := meta.Path
+	if path == "" {
+		path = defaultPath
+	}
+	if !strings.HasPrefix(path, "/") {
+		return fmt.Errorf("prometheus exporter: path must start with /, got %s", path)
+	}
+
+	if p.mux != nil {
+		if !p.registered[path] {
+			p.mux.Handle(path, p.handlerFor(path))
+			p.registered[path] = true
+		}
+		p.setExporter(path, exporter)
+		return nil
+	}
+
+	address := meta.Address
+	if address == "" {
+		address = defaultAddress
+	}
+	listener, err := net.Listen("tcp", address)
+	if err != nil {
+		return fmt.Errorf("prometheus exporter: error listening on %s: %s", address, err)
+	}
+
+	mux := http.NewServeMux()
+	mux.Handle(path, p.handlerFor(path))
+	p.setExporter(path, exporter)
+	p.listener = listener
+	server := &http.Server{Handler: mux}
+	p.server = server
+	go func() {
+		if err := server.Serve(listener); err != nil && err != http.ErrServerClosed {
+			p.logger.Errorf("prometheus exporter: error serving metrics: %s", err)
+		}
+	}()
+
+	p.logger.Infof("prometheus exporter: serving metrics on %s%s", listener.Addr(), path)
+	return nil
+}
+
+// Shutdown stops the metrics server. A handler registered on a provided mux stays
+// registered, but responds with not found until the exporter is initialized again.
+func (p *Exporter) Shutdown(ctx context.Context) error {
+	p.setExporter("", nil)
+	if p.server == nil {
+		return nil
+	}
+
+	server, listener := p.server, p.listener
+	p.server = nil
+	p.listener = nil
+	err := server.Shutdown(ctx)
+	// Shutdown only closes the listener once Serve started using it
+	listener.Close() //nolint:errcheck
+	return err
+}
+
+// setExporter swaps the exporter served on path
+func (p *Exporter) setExporter(path string, exporter *ocprometheus.Exporter) {
+	p.lock.Lock()
+	defer p.lock.Unlock()
+
+	p.path = path
+	p.exporter = exporter
+}
+
+// handlerFor returns a handler that serves the current exporter while path is the configured path
+func (p *Exporter) handlerFor(path string) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		p.lock.RLock()
+		exporter := p.exporter
+		current := p.path
+		p.lock.RUnlock()
+
+		if exporter == nil || current != path {
+			http.NotFound(w, r)
+			return
+		}
+		exporter.ServeHTTP(w, r)
+	})
+}
+
+func getOptions(daprID string, meta *prometheusMetadata) (*ocprometheus.Options, error) {
+	namespace := meta.Namespace
+	if namespace == "" {
+		namespace = defaultNamespace
+	}
+	if !labelNameRegex.MatchString(namespace) {
+		return nil, fmt.Errorf("prometheus exporter: invalid namespace %s", namespace)
+	}
+
+	labels := prom.Labels{}
+	if daprID != "" {
+		labels[appIDLabel] = daprID
+	}
+	for _, pair := range strings.Split(meta.ConstLabels, ",") {
+		if strings.TrimSpace(pair) == "" {
+			continue
+		}
+		kv := strings.SplitN(pair, "=", 2)
+		name := strings.TrimSpace(kv[0])
+		if len(kv) != 2 || !labelNameRegex.MatchString(name) {
+			return nil, fmt.Errorf("prometheus exporter: invalid constant label %s, expected name=value", pair)
+		}
+		labels[name] = strings.TrimSpace(kv[1])
+	}
+
+	return &ocprometheus.Options{
+		Namespace:   namespace,
+		Registry:    prom.NewRegistry(),
+		ConstLabels: labels,
+	}, nil
+}
+
+func (p *Exporter) getPrometheusMetadata(metadata exporters.Metadata) (*prometheusMetadata, error) {
+	b, err := json.Marshal(metadata.Properties)
+	if err != nil {
+		return nil, err
+	}
+
+	var prometheusMeta prometheusMetadata
+	err = json.Unmarshal(b, &prometheusMeta)
+	if err != nil {
+		return nil, err
+	}
+	return &prometheusMeta, nil
+}
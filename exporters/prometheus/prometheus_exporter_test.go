@@ -0,0 +1,170 @@
+// ------------------------------------------------------------
+// Copyright (c) Microsoft Corporation.
+// Licensed under the MIT License.
+// ------------------------------------------------------------
+
+package prometheus
+
+import (
+	"context"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/dapr/components-contrib/exporters"
+	"github.com/dapr/dapr/pkg/logger"
+	"github.com/stretchr/testify/assert"
+	"go.opencensus.io/stats"
+	"go.opencensus.io/stats/view"
+)
+
+func TestParseMetadata(t *testing.T) {
+	m := exporters.Metadata{}
+	m.Properties = map[string]string{"address": ":9091", "namespace": "myns"}
+	exporter := NewPrometheusExporter(logger.NewLogger("test"))
+	metadata, err := exporter.getPrometheusMetadata(m)
+	assert.Nil(t, err)
+	assert.Equal(t, ":9091", metadata.Address)
+	assert.Equal(t, "myns", metadata.Namespace)
+}
+
+func TestGetOptions(t *testing.T) {
+	t.Run("defaults", func(t *testing.T) {
+		options, err := getOptions("myapp", &prometheusMetadata{})
+		assert.Nil(t, err)
+		assert.Equal(t, defaultNamespace, options.Namespace)
+		assert.Equal(t, "myapp", options.ConstLabels[appIDLabel])
+	})
+
+	t.Run("constant labels", func(t *testing.T) {
+		options, err := getOptions("myapp", &prometheusMetadata{ConstLabels: "env=prod, region=eu"})
+		assert.Nil(t, err)
+		assert.Len(t, options.ConstLabels, 3)
+		assert.Equal(t, "eu", options.ConstLabels["region"])
+	})
+
+	invalid := map[string]*prometheusMetadata{
+		"namespace":  {Namespace: "my-ns"},
+		"label":      {ConstLabels: "env"},
+		"label name": {ConstLabels: "1env=prod"},
+	}
+	for name, meta := range invalid {
+		t.Run(name, func(t *testing.T) {
+			_, err := getOptions("myapp", meta)
+			assert.NotNil(t, err)
+		})
+	}
+}
+
+func recordTestStat(t *testing.T, name string) {
+	measure := stats.Int64(name, "Number of test requests", stats.UnitDimensionless)
+	v := &view.View{Name: name + "_total", Measure: measure, Aggregation: view.Count()}
+	assert.Nil(t, view.Register(v))
+	stats.Record(context.Background(), measure.M(1))
+}
+
+func scrape(t *testing.T, url string) string {
+	res, err := http.Get(url)
+	if !assert.Nil(t, err) {
+		return ""
+	}
+	defer res.Body.Close()
+	assert.Equal(t, http.StatusOK, res.StatusCode)
+	body, _ := ioutil.ReadAll(res.Body)
+	return string(body)
+}
+
+func TestServeOnMux(t *testing.T) {
+	recordTestStat(t, "test_requests")
+
+	mux := http.NewServeMux()
+	exporter := NewPrometheusExporterWithMux(logger.NewLogger("test"), mux)
+	err := exporter.Init("myapp", "localhost", exporters.Metadata{Properties: map[string]string{
+		"enabled": "true",
+		"path":    "/custom-metrics",
+	}})
+	assert.Nil(t, err)
+	defer exporter.Shutdown(context.Background()) //nolint:errcheck
+
+	server := httptest.NewServer(mux)
+	defer server.Close()
+
+	body := scrape(t, server.URL+"/custom-metrics")
+	assert.Contains(t, body, `dapr_test_requests_total{app_id="myapp"}`)
+}
+
+func TestReinitOnMux(t *testing.T) {
+	recordTestStat(t, "test_reinit")
+
+	mux := http.NewServeMux()
+	exporter := NewPrometheusExporterWithMux(logger.NewLogger("test"), mux)
+	server := httptest.NewServer(mux)
+	defer server.Close()
+
+	// Initializing again on the same path must not register a duplicate pattern
+	for _, path := range []string{"/metrics", "/metrics", "/other-metrics"} {
+		err := exporter.Init("myapp", "localhost", exporters.Metadata{Properties: map[string]string{
+			"enabled": "true",
+			"path":    path,
+		}})
+		assert.Nil(t, err)
+		assert.Contains(t, scrape(t, server.URL+path), "dapr_test_reinit_total")
+	}
+
+	// The previous path and a shut down exporter no longer serve metrics
+	res, err := http.Get(server.URL + "/metrics")
+	assert.Nil(t, err)
+	res.Body.Close()
+	assert.Equal(t, http.StatusNotFound, res.StatusCode)
+
+	assert.Nil(t, exporter.Shutdown(context.Background()))
+	res, err = http.Get(server.URL + "/other-metrics")
+	assert.Nil(t, err)
+	res.Body.Close()
+	assert.Equal(t, http.StatusNotFound, res.StatusCode)
+}
+
+func TestServeOnAddress(t *testing.T) {
+	recordTestStat(t, "test_served")
+
+	exporter := NewPrometheusExporter(logger.NewLogger("test"))
+	err := exporter.Init("myapp", "localhost", exporters.Metadata{Properties: map[string]string{
+		"enabled": "true",
+		"address": "127.0.0.1:0",
+	}})
+	assert.Nil(t, err)
+
+	body := scrape(t, fmt.Sprintf("http://%s%s", exporter.listener.Addr(), defaultPath))
+	assert.Contains(t, body, `dapr_test_served_total{app_id="myapp"} 1`)
+
+	assert.Nil(t, exporter.Shutdown(context.Background()))
+}
+
+func TestReinitReleasesAddress(t *testing.T) {
+	exporter := NewPrometheusExporter(logger.NewLogger("test"))
+	err := exporter.Init("myapp", "localhost", exporters.Metadata{Properties: map[string]string{
+		"enabled": "true",
+		"address": "127.0.0.1:0",
+	}})
+	assert.Nil(t, err)
+	address := exporter.listener.Addr().String()
+
+	// Initializing again on the same address must not fail with the port still bound
+	err = exporter.Init("myapp", "localhost", exporters.Metadata{Properties: map[string]string{
+		"enabled": "true",
+		"address": address,
+	}})
+	assert.Nil(t, err)
+	assert.Equal(t, address, exporter.listener.Addr().String())
+	scrape(t, fmt.Sprintf("http://%s%s", address, defaultPath))
+
+	assert.Nil(t, exporter.Shutdown(context.Background()))
+}
+
+func TestInvalidEnabled(t *testing.T) {
+	exporter := NewPrometheusExporter(logger.NewLogger("test"))
+	err := exporter.Init("myapp", "localhost", exporters.Metadata{Properties: map[string]string{"enabled": "sure"}})
+	assert.NotNil(t, err)
+}
@@ -0,0 +1,129 @@
+// ------------------------------------------------------------
+// Copyright (c) Microsoft Corporation.
+// Licensed under the MIT License.
+// ------------------------------------------------------------
+
+package recorder
+
+import (
+	"context"
+	"sync"
+
+	"github.com/dapr/components-contrib/exporters"
+	"github.com/dapr/dapr/pkg/logger"
+	"go.opencensus.io/trace"
+)
+
+// NewRecorderExporter returns a new recorder exporter instance
+func NewRecorderExporter(logger logger.Logger) *Exporter {
+	return &Exporter{logger: logger}
+}
+
+// Exporter is an OpenCensus exporter that keeps every exported span in memory.
+// It is meant for assertions in tests.
+type Exporter struct {
+	logger logger.Logger
+	lock   sync.RWMutex
+	spans  []*trace.SpanData
+}
+
+// SpanNode is a span together with its recorded child spans
+type SpanNode struct {
+	Span     *trace.SpanData
+	Children []*SpanNode
+}
+
+// Init registers the recorder with OpenCensus
+func (r *Exporter) Init(daprID string, hostAddress string, metadata exporters.Metadata) error {
+	err := exporters.ApplySampling(metadata)
+	if err != nil {
+		return err
+	}
+
+	trace.RegisterExporter(r)
+	return nil
+}
+
+// Shutdown unregisters the recorder. Recorded spans are kept until Reset is called.
+func (r *Exporter) Shutdown(ctx context.Context) error {
+	trace.UnregisterExporter(r)
+	return nil
+}
+
+// ExportSpan records the span
+func (r *Exporter) ExportSpan(sd *trace.SpanData) {
+	r.lock.Lock()
+	r.spans = append(r.spans, sd)
+	r.lock.Unlock()
+}
+
+// Reset discards all recorded spans
+func (r *Exporter) Reset() {
+	r.lock.Lock()
+	r.spans = nil
+	r.lock.Unlock()
+}
+
+// Spans returns all recorded spans in the order they were exported
+func (r *Exporter) Spans() []*trace.SpanData {
+	return r.filter(func(*trace.SpanData) bool { return true })
+}
+
+// SpansByName returns the recorded spans with the given name
+func (r *Exporter) SpansByName(name string) []*trace.SpanData {
+	return r.filter(func(sd *trace.SpanData) bool { return sd.Name == name })
+}
+
+// SpansWithAttribute returns the recorded spans that have the attribute set to value
+func (r *Exporter) SpansWithAttribute(key string, value interface{}) []*trace.SpanData {
+	return r.filter(func(sd *trace.SpanData) bool {
+		v, ok := sd.Attributes[key]
+		return ok && v == value
+	})
+}
+
+// Children returns the recorded spans whose parent is the given span
+func (r *Exporter) Children(parent *trace.SpanData) []*trace.SpanData {
+	return r.filter(func(sd *trace.SpanData) bool {
+		return sd.TraceID == parent.TraceID && sd.ParentSpanID == parent.SpanID
+	})
+}
+
+// Tree returns the recorded spans arranged by parent. Spans whose parent wasn't
+// recorded, such as spans continuing a remote trace, are returned as roots.
+func (r *Exporter) Tree() []*SpanNode {
+	spans := r.Spans()
+
+	type spanKey struct {
+		traceID trace.TraceID
+		spanID  trace.SpanID
+	}
+	nodes := make(map[spanKey]*SpanNode, len(spans))
+	for _, sd := range spans {
+		nodes[spanKey{sd.TraceID, sd.SpanID}] = &SpanNode{Span: sd}
+	}
+
+	var roots []*SpanNode
+	for _, sd := range spans {
+		node := nodes[spanKey{sd.TraceID, sd.SpanID}]
+		if parent, ok := nodes[spanKey{sd.TraceID, sd.ParentSpanID}]; ok && sd.ParentSpanID != (trace.SpanID{}) {
+			parent.Children = append(parent.Children, node)
+			continue
+		}
+		roots = append(roots, node)
+	}
+	return roots
+}
+
+func (r *Exporter) filter(match func(*trace.SpanData) bool) []*trace.SpanData {
+	r.lock.RLock()
+	defer r.lock.RUnlock()
+
+	result := []*trace.SpanData{}
+	for _, sd := range r.spans {
+		if match(sd) {
+			result = append(result, sd)
+		}
+	}
+	return result
+}
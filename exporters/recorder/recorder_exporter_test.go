@@ -0,0 +1,60 @@
+// ------------------------------------------------------------
+// Copyright (c) Microsoft Corporation.
+// Licensed under the MIT License.
+// ------------------------------------------------------------
+
+package recorder
+
+import (
+	"context"
+	"testing"
+
+	"github.com/dapr/components-contrib/exporters"
+	"github.com/dapr/dapr/pkg/logger"
+	"github.com/stretchr/testify/assert"
+	"go.opencensus.io/trace"
+)
+
+func TestRecorder(t *testing.T) {
+	r := NewRecorderExporter(logger.NewLogger("test"))
+	assert.Nil(t, r.Init("myapp", "localhost", exporters.Metadata{}))
+	defer r.Shutdown(context.Background()) //nolint:errcheck
+
+	sampler := trace.WithSampler(trace.AlwaysSample())
+	ctx, parent := trace.StartSpan(context.Background(), "parent", sampler)
+	_, child1 := trace.StartSpan(ctx, "child", sampler)
+	child1.AddAttributes(trace.StringAttribute("component", "redis"))
+	child1.End()
+	_, child2 := trace.StartSpan(ctx, "child", sampler)
+	child2.End()
+	parent.End()
+
+	spans := r.Spans()
+	assert.Len(t, spans, 3)
+	assert.Len(t, r.SpansByName("child"), 2)
+
+	withAttr := r.SpansWithAttribute("component", "redis")
+	assert.Len(t, withAttr, 1)
+	assert.Equal(t, child1.SpanContext().SpanID, withAttr[0].SpanID)
+
+	parentData := r.SpansByName("parent")[0]
+	assert.Len(t, r.Children(parentData), 2)
+
+	tree := r.Tree()
+	assert.Len(t, tree, 1)
+	assert.Equal(t, "parent", tree[0].Span.Name)
+	assert.Len(t, tree[0].Children, 2)
+
+	r.Reset()
+	assert.Empty(t, r.Spans())
+}
+
+func TestShutdownStopsRecording(t *testing.T) {
+	r := NewRecorderExporter(logger.NewLogger("test"))
+	assert.Nil(t, r.Init("myapp", "localhost", exporters.Metadata{}))
+	assert.Nil(t, r.Shutdown(context.Background()))
+
+	_, span := trace.StartSpan(context.Background(), "ignored", trace.WithSampler(trace.AlwaysSample()))
+	span.End()
+	assert.Empty(t, r.Spans())
+}
@@ -0,0 +1,189 @@
+// ------------------------------------------------------------
+// Copyright (c) Microsoft Corporation.
+// Licensed under the MIT License.
+// ------------------------------------------------------------
+
+package exporters
+
+import (
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"go.opencensus.io/trace"
+)
+
+const (
+	// SamplingTypeKey selects the sampler: always, never, probability or ratelimited
+	SamplingTypeKey = "samplingType"
+	// SamplingRateKey is the fraction of traces for probability sampling,
+	// or the number of traces per second for rate-limited sampling
+	SamplingRateKey = "samplingRate"
+	// SamplingParentBasedKey makes spans follow the sampling decision of their parent, when they have one
+	SamplingParentBasedKey = "samplingParentBased"
+
+	// SamplingAlways samples every trace
+	SamplingAlways = "always"
+	// SamplingNever samples no traces
+	SamplingNever = "never"
+	// SamplingProbability samples a fraction of traces
+	SamplingProbability = "probability"
+	// SamplingRateLimited samples up to a fixed number of traces per second
+	SamplingRateLimited = "ratelimited"
+)
+
+// SamplingConfig is the sampling configuration shared by all exporters
+type SamplingConfig struct {
+	Type        string
+	Rate        float64
+	ParentBased bool
+}
+
+var (
+	samplingLock    sync.Mutex
+	appliedSampling *SamplingConfig
+)
+
+// ParseSamplingConfig reads the sampling configuration from the exporter metadata.
+// The returned config is nil when the metadata doesn't specify any sampling settings.
+func ParseSamplingConfig(metadata Metadata) (*SamplingConfig, error) {
+	samplingType, hasType := metadata.Properties[SamplingTypeKey]
+	rate, hasRate := metadata.Properties[SamplingRateKey]
+	parentBased, hasParentBased := metadata.Properties[SamplingParentBasedKey]
+	if !hasType && !hasRate && !hasParentBased {
+		return nil, nil
+	}
+
+	config := &SamplingConfig{Type: strings.ToLower(samplingType)}
+	if config.Type == "" {
+		config.Type = SamplingAlways
+		if hasRate {
+			config.Type = SamplingProbability
+		}
+	}
+
+	switch config.Type {
+	case SamplingAlways, SamplingNever:
+	case SamplingProbability, SamplingRateLimited:
+		if !hasRate {
+			return nil, fmt.Errorf("sampling type %s requires %s", config.Type, SamplingRateKey)
+		}
+		r, err := strconv.ParseFloat(rate, 64)
+		if err != nil {
+			return nil, fmt.Errorf("error parsing %s: %s", SamplingRateKey, err)
+		}
+		if config.Type == SamplingProbability && (r < 0 || r > 1) {
+			return nil, fmt.Errorf("%s must be between 0 and 1 for probability sampling", SamplingRateKey)
+		}
+		if config.Type == SamplingRateLimited && r <= 0 {
+			return nil, fmt.Errorf("%s must be positive for rate-limited sampling", SamplingRateKey)
+		}
+		config.Rate = r
+	default:
+		return nil, fmt.Errorf("unknown sampling type %s", samplingType)
+	}
+
+	if hasParentBased {
+		b, err := strconv.ParseBool(parentBased)
+		if err != nil {
+			return nil, fmt.Errorf("error parsing %s: %s", SamplingParentBasedKey, err)
+		}
+		config.ParentBased = b
+	}
+
+	return config, nil
+}
+
+// Sampler returns the OpenCensus sampler for the config
+func (c *SamplingConfig) Sampler() trace.Sampler {
+	var sampler trace.Sampler
+	switch c.Type {
+	case SamplingNever:
+		sampler = trace.NeverSample()
+	case SamplingProbability:
+		sampler = trace.ProbabilitySampler(c.Rate)
+	case SamplingRateLimited:
+		sampler = newRateLimitedSampler(c.Rate)
+	default:
+		sampler = trace.AlwaysSample()
+	}
+
+	if c.ParentBased {
+		return parentBasedSampler(sampler)
+	}
+	return sampler
+}
+
+// ApplySampling sets the global sampler from the exporter metadata. Exporters
+// that don't specify sampling settings have no opinion: they keep the sampler
+// already applied, or the default always sampler when there is none. The first
+// exporter with explicit settings applies them, and later exporters that specify
+// different settings get an error instead of silently overriding them.
+func ApplySampling(metadata Metadata) error {
+	config, err := ParseSamplingConfig(metadata)
+	if err != nil {
+		return err
+	}
+
+	samplingLock.Lock()
+	defer samplingLock.Unlock()
+
+	if config == nil {
+		if appliedSampling == nil {
+			trace.ApplyConfig(trace.Config{DefaultSampler: trace.AlwaysSample()})
+		}
+		return nil
+	}
+
+	if appliedSampling != nil {
+		if *config != *appliedSampling {
+			return fmt.Errorf("sampling configuration %+v conflicts with the already applied %+v", *config, *appliedSampling)
+		}
+		return nil
+	}
+
+	trace.ApplyConfig(trace.Config{DefaultSampler: config.Sampler()})
+	appliedSampling = config
+	return nil
+}
+
+// parentBasedSampler follows the decision of the parent span when there is
+// one, and defers to the root sampler otherwise
+func parentBasedSampler(root trace.Sampler) trace.Sampler {
+	return func(p trace.SamplingParameters) trace.SamplingDecision {
+		if p.ParentContext.TraceID != (trace.TraceID{}) {
+			return trace.SamplingDecision{Sample: p.ParentContext.IsSampled()}
+		}
+		return root(p)
+	}
+}
+
+// newRateLimitedSampler samples up to perSecond traces per second using a token bucket.
+// The bucket holds at least one token so rates below one per second still sample.
+func newRateLimitedSampler(perSecond float64) trace.Sampler {
+	var lock sync.Mutex
+	capacity := math.Max(perSecond, 1)
+	tokens := capacity
+	last := time.Now()
+
+	return func(p trace.SamplingParameters) trace.SamplingDecision {
+		lock.Lock()
+		defer lock.Unlock()
+
+		now := time.Now()
+		tokens += now.Sub(last).Seconds() * perSecond
+		if tokens > capacity {
+			tokens = capacity
+		}
+		last = now
+
+		if tokens >= 1 {
+			tokens--
+			return trace.SamplingDecision{Sample: true}
+		}
+		return trace.SamplingDecision{Sample: false}
+	}
+}
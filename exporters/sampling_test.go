@@ -0,0 +1,130 @@
+// ------------------------------------------------------------
+// Copyright (c) Microsoft Corporation.
+// Licensed under the MIT License.
+// ------------------------------------------------------------
+
+package exporters
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"go.opencensus.io/trace"
+)
+
+func resetSampling() {
+	samplingLock.Lock()
+	appliedSampling = nil
+	samplingLock.Unlock()
+}
+
+func TestParseSamplingConfig(t *testing.T) {
+	t.Run("no settings", func(t *testing.T) {
+		config, err := ParseSamplingConfig(Metadata{Properties: map[string]string{"enabled": "true"}})
+		assert.Nil(t, err)
+		assert.Nil(t, config)
+	})
+
+	t.Run("rate implies probability", func(t *testing.T) {
+		config, err := ParseSamplingConfig(Metadata{Properties: map[string]string{SamplingRateKey: "0.25"}})
+		assert.Nil(t, err)
+		assert.Equal(t, SamplingConfig{Type: SamplingProbability, Rate: 0.25}, *config)
+	})
+
+	t.Run("parent based rate limited", func(t *testing.T) {
+		config, err := ParseSamplingConfig(Metadata{Properties: map[string]string{
+			SamplingTypeKey:        "RateLimited",
+			SamplingRateKey:        "10",
+			SamplingParentBasedKey: "true",
+		}})
+		assert.Nil(t, err)
+		assert.Equal(t, SamplingConfig{Type: SamplingRateLimited, Rate: 10, ParentBased: true}, *config)
+	})
+
+	invalid := map[string]map[string]string{
+		"unknown type":          {SamplingTypeKey: "sometimes"},
+		"missing rate":          {SamplingTypeKey: SamplingProbability},
+		"probability too large": {SamplingTypeKey: SamplingProbability, SamplingRateKey: "1.5"},
+		"zero rate limit":       {SamplingTypeKey: SamplingRateLimited, SamplingRateKey: "0"},
+		"bad parent based":      {SamplingParentBasedKey: "perhaps"},
+	}
+	for name, properties := range invalid {
+		t.Run(name, func(t *testing.T) {
+			_, err := ParseSamplingConfig(Metadata{Properties: properties})
+			assert.NotNil(t, err)
+		})
+	}
+}
+
+func TestSamplers(t *testing.T) {
+	params := trace.SamplingParameters{TraceID: trace.TraceID{1}}
+
+	t.Run("never", func(t *testing.T) {
+		sampler := (&SamplingConfig{Type: SamplingNever}).Sampler()
+		assert.False(t, sampler(params).Sample)
+	})
+
+	t.Run("parent based follows parent", func(t *testing.T) {
+		sampler := (&SamplingConfig{Type: SamplingNever, ParentBased: true}).Sampler()
+		withParent := params
+		withParent.ParentContext = trace.SpanContext{TraceID: trace.TraceID{1}, TraceOptions: 1}
+		assert.True(t, sampler(withParent).Sample)
+		assert.False(t, sampler(params).Sample)
+	})
+
+	t.Run("rate limited", func(t *testing.T) {
+		sampler := (&SamplingConfig{Type: SamplingRateLimited, Rate: 2}).Sampler()
+		assert.True(t, sampler(params).Sample)
+		assert.True(t, sampler(params).Sample)
+		assert.False(t, sampler(params).Sample)
+	})
+
+	t.Run("rate limited below one per second", func(t *testing.T) {
+		sampler := newRateLimitedSampler(0.5)
+		assert.True(t, sampler(params).Sample)
+		assert.False(t, sampler(params).Sample)
+	})
+}
+
+func TestRateLimitedSamplerRefills(t *testing.T) {
+	params := trace.SamplingParameters{TraceID: trace.TraceID{1}}
+	sampler := newRateLimitedSampler(20)
+	for i := 0; i < 20; i++ {
+		assert.True(t, sampler(params).Sample)
+	}
+	assert.False(t, sampler(params).Sample)
+
+	time.Sleep(100 * time.Millisecond)
+	assert.True(t, sampler(params).Sample)
+}
+
+func TestApplySampling(t *testing.T) {
+	resetSampling()
+	defer resetSampling()
+
+	first := Metadata{Properties: map[string]string{SamplingRateKey: "0.5"}}
+	assert.Nil(t, ApplySampling(first))
+
+	// Exporters without sampling settings, or with the same ones, share the applied sampler
+	assert.Nil(t, ApplySampling(Metadata{Properties: map[string]string{}}))
+	assert.Nil(t, ApplySampling(first))
+
+	// Conflicting settings don't override the applied sampler
+	err := ApplySampling(Metadata{Properties: map[string]string{SamplingTypeKey: SamplingAlways}})
+	assert.NotNil(t, err)
+}
+
+func TestApplySamplingUnsetFirst(t *testing.T) {
+	resetSampling()
+	defer resetSampling()
+
+	// An exporter without sampling settings doesn't pin the sampler for the ones initialized after it
+	assert.Nil(t, ApplySampling(Metadata{Properties: map[string]string{}}))
+	assert.Nil(t, ApplySampling(Metadata{Properties: map[string]string{SamplingTypeKey: SamplingNever}}))
+	assert.Nil(t, ApplySampling(Metadata{Properties: map[string]string{}}))
+	assert.Equal(t, SamplingConfig{Type: SamplingNever}, *appliedSampling)
+
+	err := ApplySampling(Metadata{Properties: map[string]string{SamplingRateKey: "0.5"}})
+	assert.NotNil(t, err)
+}
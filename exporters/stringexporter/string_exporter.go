@@ -6,6 +6,7 @@
 package stringexporter
 
 import (
+	"context"
 	"strconv"
 
 	"github.com/dapr/components-contrib/exporters"
@@ -36,8 +37,18 @@ func (se *Exporter) ExportSpan(sd *trace.SpanData) {
 
 // Init creates a new string exporter endpoint and reporter
 func (se *Exporter) Init(daprID string, hostAddress string, metadata exporters.Metadata) error {
+	err := exporters.ApplySampling(metadata)
+	if err != nil {
+		return err
+	}
+
 	se.Buffer = metadata.Buffer
-	trace.ApplyConfig(trace.Config{DefaultSampler: trace.AlwaysSample()})
 	trace.RegisterExporter(se)
 	return nil
 }
+
+// Shutdown unregisters the exporter
+func (se *Exporter) Shutdown(ctx context.Context) error {
+	trace.UnregisterExporter(se)
+	return nil
+}
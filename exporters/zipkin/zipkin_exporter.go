@@ -6,21 +6,63 @@
 package zipkin
 
 import (
+	"context"
+	"crypto/tls"
+	"crypto/x509"
 	"encoding/json"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"net/url"
 	"strconv"
+	"strings"
+	"time"
 
 	"contrib.go.opencensus.io/exporter/zipkin"
 	"github.com/dapr/components-contrib/exporters"
 	"github.com/dapr/dapr/pkg/logger"
 	openzipkin "github.com/openzipkin/zipkin-go"
+	"github.com/openzipkin/zipkin-go/reporter"
 	zipkinHTTP "github.com/openzipkin/zipkin-go/reporter/http"
 	"go.opencensus.io/trace"
 )
 
+const (
+	// Defaults, matching the zipkin-go HTTP reporter
+	defaultTimeout       = time.Second * 5
+	defaultBatchSize     = 100
+	defaultBatchInterval = time.Second
+	defaultMaxBacklog    = 1000
+)
+
 // Metadata is the zipkin config
 type zipkinMetadata struct {
-	ExporterAddress string `json:"exporterAddress"`
-	Enabled         string `json:"enabled"`
+	ExporterAddress    string `json:"exporterAddress"`
+	Enabled            string `json:"enabled"`
+	BatchSize          string `json:"batchSize"`
+	BatchInterval      string `json:"batchInterval"`
+	Timeout            string `json:"timeout"`
+	MaxQueueSize       string `json:"maxQueueSize"`
+	Headers            string `json:"headers"`
+	CAFile             string `json:"caFile"`
+	CertFile           string `json:"certFile"`
+	KeyFile            string `json:"keyFile"`
+	InsecureSkipVerify string `json:"insecureSkipVerify"`
+}
+
+// reporterConfig is the validated zipkin reporter configuration
+type reporterConfig struct {
+	address            string
+	batchSize          int
+	batchInterval      time.Duration
+	timeout            time.Duration
+	maxBacklog         int
+	headers            map[string]string
+	caFile             string
+	certFile           string
+	keyFile            string
+	insecureSkipVerify bool
 }
 
 // NewZipkinExporter returns a new zipkin exporter instance
@@ -30,32 +72,202 @@ func NewZipkinExporter(logger logger.Logger) *Exporter {
 
 // Exporter is an OpenCensus zipkin exporter
 type Exporter struct {
-	logger logger.Logger
+	logger   logger.Logger
+	exporter *zipkin.Exporter
+	reporter reporter.Reporter
 }
 
 // Init creates a new zipkin endpoint and reporter
 func (z *Exporter) Init(daprID string, hostAddress string, metadata exporters.Metadata) error {
+	// Shut down a previous registration so re-initializing doesn't export spans twice
+	if err := z.Shutdown(context.Background()); err != nil {
+		z.logger.Warnf("zipkin exporter: error shutting down previous exporter: %s", err)
+	}
+
 	meta, err := z.getZipkinMetadata(metadata)
 	if err != nil {
 		return err
 	}
 
-	enabled, _ := strconv.ParseBool(meta.Enabled)
+	if meta.Enabled == "" {
+		return nil
+	}
+	enabled, err := strconv.ParseBool(meta.Enabled)
+	if err != nil {
+		return fmt.Errorf("zipkin exporter: invalid enabled value %s: %s", meta.Enabled, err)
+	}
 	if !enabled {
 		return nil
 	}
 
+	config, err := parseReporterConfig(meta)
+	if err != nil {
+		return err
+	}
+	httpClient, err := newHTTPClient(config)
+	if err != nil {
+		return err
+	}
+
+	err = exporters.ApplySampling(metadata)
+	if err != nil {
+		return err
+	}
+
 	localEndpoint, err := openzipkin.NewEndpoint(daprID, hostAddress)
 	if err != nil {
 		return err
 	}
-	reporter := zipkinHTTP.NewReporter(meta.ExporterAddress)
-	ze := zipkin.NewExporter(reporter, localEndpoint)
-	trace.RegisterExporter(ze)
-	trace.ApplyConfig(trace.Config{DefaultSampler: trace.AlwaysSample()})
+	z.reporter = zipkinHTTP.NewReporter(config.address,
+		zipkinHTTP.Client(httpClient),
+		zipkinHTTP.BatchSize(config.batchSize),
+		zipkinHTTP.BatchInterval(config.batchInterval),
+		zipkinHTTP.MaxBacklog(config.maxBacklog),
+		zipkinHTTP.RequestCallback(func(req *http.Request) {
+			for k, v := range config.headers {
+				req.Header.Set(k, v)
+			}
+		}),
+	)
+	z.exporter = zipkin.NewExporter(z.reporter, localEndpoint)
+	trace.RegisterExporter(z.exporter)
 	return nil
 }
 
+// Shutdown unregisters the exporter and closes the reporter, which sends any buffered spans
+func (z *Exporter) Shutdown(ctx context.Context) error {
+	if z.exporter == nil {
+		return nil
+	}
+
+	trace.UnregisterExporter(z.exporter)
+	r := z.reporter
+	z.exporter = nil
+	z.reporter = nil
+	return exporters.WaitContext(ctx, r.Close)
+}
+
+func parseReporterConfig(meta *zipkinMetadata) (*reporterConfig, error) {
+	if meta.ExporterAddress == "" {
+		return nil, errors.New("zipkin exporter: exporterAddress is required")
+	}
+	if u, err := url.Parse(meta.ExporterAddress); err != nil || u.Scheme == "" || u.Host == "" {
+		return nil, fmt.Errorf("zipkin exporter: invalid exporterAddress %s", meta.ExporterAddress)
+	}
+
+	config := &reporterConfig{
+		address:       meta.ExporterAddress,
+		batchSize:     defaultBatchSize,
+		batchInterval: defaultBatchInterval,
+		timeout:       defaultTimeout,
+		maxBacklog:    defaultMaxBacklog,
+		headers:       map[string]string{},
+		caFile:        meta.CAFile,
+		certFile:      meta.CertFile,
+		keyFile:       meta.KeyFile,
+	}
+
+	for _, d := range []struct {
+		name   string
+		value  string
+		target *time.Duration
+	}{
+		{"batchInterval", meta.BatchInterval, &config.batchInterval},
+		{"timeout", meta.Timeout, &config.timeout},
+	} {
+		if d.value == "" {
+			continue
+		}
+		v, err := time.ParseDuration(d.value)
+		if err != nil || v <= 0 {
+			return nil, fmt.Errorf("zipkin exporter: %s must be a positive duration, got %s", d.name, d.value)
+		}
+		*d.target = v
+	}
+
+	for _, i := range []struct {
+		name   string
+		value  string
+		target *int
+	}{
+		{"batchSize", meta.BatchSize, &config.batchSize},
+		{"maxQueueSize", meta.MaxQueueSize, &config.maxBacklog},
+	} {
+		if i.value == "" {
+			continue
+		}
+		v, err := strconv.Atoi(i.value)
+		if err != nil || v <= 0 {
+			return nil, fmt.Errorf("zipkin exporter: %s must be a positive integer, got %s", i.name, i.value)
+		}
+		*i.target = v
+	}
+	if config.maxBacklog < config.batchSize {
+		return nil, fmt.Errorf("zipkin exporter: maxQueueSize %d must not be smaller than batchSize %d", config.maxBacklog, config.batchSize)
+	}
+
+	for _, pair := range strings.Split(meta.Headers, ",") {
+		if strings.TrimSpace(pair) == "" {
+			continue
+		}
+		kv := strings.SplitN(pair, "=", 2)
+		if len(kv) != 2 || strings.TrimSpace(kv[0]) == "" {
+			return nil, fmt.Errorf("zipkin exporter: invalid header %s, expected key=value", pair)
+		}
+		config.headers[strings.TrimSpace(kv[0])] = strings.TrimSpace(kv[1])
+	}
+
+	if (config.certFile == "") != (config.keyFile == "") {
+		return nil, errors.New("zipkin exporter: certFile and keyFile must be set together")
+	}
+	if meta.InsecureSkipVerify != "" {
+		skip, err := strconv.ParseBool(meta.InsecureSkipVerify)
+		if err != nil {
+			return nil, fmt.Errorf("zipkin exporter: invalid insecureSkipVerify value %s: %s", meta.InsecureSkipVerify, err)
+		}
+		config.insecureSkipVerify = skip
+	}
+
+	return config, nil
+}
+
+// newHTTPClient creates the client used by the reporter, with the configured timeout and TLS settings
+func newHTTPClient(config *reporterConfig) (*http.Client, error) {
+	client := &http.Client{Timeout: config.timeout}
+	if config.caFile == "" && config.certFile == "" && !config.insecureSkipVerify {
+		return client, nil
+	}
+
+	tlsConfig := &tls.Config{
+		MinVersion:         tls.VersionTLS12,
+		InsecureSkipVerify: config.insecureSkipVerify, //nolint:gosec
+	}
+	if config.caFile != "" {
+		ca, err := ioutil.ReadFile(config.caFile)
+		if err != nil {
+			return nil, fmt.Errorf("zipkin exporter: error reading CA file: %s", err)
+		}
+		pool := x509.NewCertPool()
+		if !pool.AppendCertsFromPEM(ca) {
+			return nil, fmt.Errorf("zipkin exporter: no certificates found in CA file %s", config.caFile)
+		}
+		tlsConfig.RootCAs = pool
+	}
+	if config.certFile != "" {
+		cert, err := tls.LoadX509KeyPair(config.certFile, config.keyFile)
+		if err != nil {
+			return nil, fmt.Errorf("zipkin exporter: error loading client certificate: %s", err)
+		}
+		tlsConfig.Certificates = []tls.Certificate{cert}
+	}
+
+	client.Transport = &http.Transport{
+		Proxy:           http.ProxyFromEnvironment,
+		TLSClientConfig: tlsConfig,
+	}
+	return client, nil
+}
+
 func (z *Exporter) getZipkinMetadata(metadata exporters.Metadata) (*zipkinMetadata, error) {
 	b, err := json.Marshal(metadata.Properties)
 	if err != nil {
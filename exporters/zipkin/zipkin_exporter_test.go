@@ -6,10 +6,15 @@
 package zipkin
 
 import (
+	"context"
+	"net/http"
+	"net/http/httptest"
 	"testing"
+	"time"
 
 	"github.com/dapr/components-contrib/exporters"
 	"github.com/dapr/dapr/pkg/logger"
+	"github.com/openzipkin/zipkin-go/model"
 	"github.com/stretchr/testify/assert"
 )
 
@@ -21,3 +26,90 @@ func TestParseMetadata(t *testing.T) {
 	assert.Nil(t, err)
 	assert.Equal(t, "c", metadata.ExporterAddress)
 }
+
+func TestParseReporterConfig(t *testing.T) {
+	t.Run("defaults", func(t *testing.T) {
+		config, err := parseReporterConfig(&zipkinMetadata{ExporterAddress: "http://zipkin:9411/api/v2/spans"})
+		assert.Nil(t, err)
+		assert.Equal(t, defaultBatchSize, config.batchSize)
+		assert.Equal(t, defaultBatchInterval, config.batchInterval)
+		assert.Equal(t, defaultTimeout, config.timeout)
+		assert.Equal(t, defaultMaxBacklog, config.maxBacklog)
+	})
+
+	t.Run("all options", func(t *testing.T) {
+		config, err := parseReporterConfig(&zipkinMetadata{
+			ExporterAddress:    "https://zipkin:9411/api/v2/spans",
+			BatchSize:          "10",
+			BatchInterval:      "500ms",
+			Timeout:            "2s",
+			MaxQueueSize:       "50",
+			Headers:            "Authorization=Bearer token, X-Tenant=a",
+			InsecureSkipVerify: "true",
+		})
+		assert.Nil(t, err)
+		assert.Equal(t, 10, config.batchSize)
+		assert.Equal(t, time.Millisecond*500, config.batchInterval)
+		assert.Equal(t, time.Second*2, config.timeout)
+		assert.Equal(t, 50, config.maxBacklog)
+		assert.Equal(t, map[string]string{"Authorization": "Bearer token", "X-Tenant": "a"}, config.headers)
+		assert.True(t, config.insecureSkipVerify)
+	})
+
+	invalid := map[string]*zipkinMetadata{
+		"no address":           {},
+		"relative address":     {ExporterAddress: "zipkin:9411"},
+		"bad batch size":       {ExporterAddress: "http://zipkin:9411", BatchSize: "0"},
+		"bad batch interval":   {ExporterAddress: "http://zipkin:9411", BatchInterval: "often"},
+		"bad timeout":          {ExporterAddress: "http://zipkin:9411", Timeout: "-1s"},
+		"queue below batch":    {ExporterAddress: "http://zipkin:9411", BatchSize: "100", MaxQueueSize: "10"},
+		"bad header":           {ExporterAddress: "http://zipkin:9411", Headers: "novalue"},
+		"cert without key":     {ExporterAddress: "http://zipkin:9411", CertFile: "cert.pem"},
+		"bad skip verify flag": {ExporterAddress: "http://zipkin:9411", InsecureSkipVerify: "sometimes"},
+	}
+	for name, meta := range invalid {
+		t.Run(name, func(t *testing.T) {
+			_, err := parseReporterConfig(meta)
+			assert.NotNil(t, err)
+		})
+	}
+}
+
+func TestInvalidEnabled(t *testing.T) {
+	exporter := NewZipkinExporter(logger.NewLogger("test"))
+	err := exporter.Init("myapp", "localhost", exporters.Metadata{Properties: map[string]string{
+		"enabled":         "yes please",
+		"exporterAddress": "http://zipkin:9411/api/v2/spans",
+	}})
+	assert.NotNil(t, err)
+}
+
+func TestReporterSendsHeaders(t *testing.T) {
+	headers := make(chan http.Header, 1)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		headers <- r.Header
+		w.WriteHeader(http.StatusAccepted)
+	}))
+	defer server.Close()
+
+	exporter := NewZipkinExporter(logger.NewLogger("test"))
+	err := exporter.Init("myapp", "127.0.0.1", exporters.Metadata{Properties: map[string]string{
+		"enabled":         "true",
+		"exporterAddress": server.URL,
+		"headers":         "Authorization=Bearer token",
+	}})
+	assert.Nil(t, err)
+
+	exporter.reporter.Send(model.SpanModel{
+		SpanContext: model.SpanContext{TraceID: model.TraceID{Low: 1}, ID: 1},
+		Name:        "span1",
+	})
+	assert.Nil(t, exporter.Shutdown(context.Background()))
+
+	select {
+	case h := <-headers:
+		assert.Equal(t, "Bearer token", h.Get("Authorization"))
+	case <-time.After(time.Second * 5):
+		assert.Fail(t, "spans were not reported")
+	}
+}
@@ -0,0 +1,342 @@
+// ------------------------------------------------------------
+// Copyright (c) Microsoft Corporation.
+// Licensed under the MIT License.
+// ------------------------------------------------------------
+
+package accesslog
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"math/rand"
+	"os"
+	"path"
+	"strconv"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/dapr/components-contrib/middleware"
+	"github.com/dapr/components-contrib/pubsub"
+	"github.com/dapr/dapr/pkg/logger"
+	"github.com/google/uuid"
+	"github.com/valyala/fasthttp"
+)
+
+// Metadata is the access log middleware config
+type accessLogMiddlewareMetadata struct {
+	SampleRate        float64
+	LogHeaders        bool
+	RedactHeaders     map[string]bool
+	TraceIDHeader     string
+	TrustForwardedFor bool
+	AuditTopic        string
+	AuditRoutes       []string
+	AuditSource       string
+}
+
+// accessLogEntry is a single access log line
+type accessLogEntry struct {
+	Time      string            `json:"time"`
+	Method    string            `json:"method"`
+	Path      string            `json:"path"`
+	Status    int               `json:"status"`
+	LatencyMs float64           `json:"latencyMs"`
+	BytesIn   int               `json:"bytesIn"`
+	BytesOut  int               `json:"bytesOut"`
+	ClientIP  string            `json:"clientIP"`
+	TraceID   string            `json:"traceID,omitempty"`
+	Headers   map[string]string `json:"headers,omitempty"`
+}
+
+const (
+	sampleRateKey        = "sampleRate"
+	logHeadersKey        = "logHeaders"
+	redactHeadersKey     = "redactHeaders"
+	traceIDHeaderKey     = "traceIDHeader"
+	trustForwardedForKey = "trustForwardedFor"
+	auditTopicKey        = "auditTopic"
+	auditRoutesKey       = "auditRoutes"
+	auditSourceKey       = "auditSource"
+
+	// AuditEventType is the cloud event type of published audit events
+	AuditEventType = "com.dapr.middleware.audit"
+
+	redactedValue = "REDACTED"
+
+	// Defaults
+	defaultSampleRate    = 1.0
+	defaultRedactHeaders = "Authorization,Cookie,Set-Cookie,X-API-Key"
+	defaultTraceIDHeader = "traceparent"
+	defaultAuditSource   = "accesslog"
+
+	// auditQueueSize bounds the audit events waiting to be published, further events are dropped
+	auditQueueSize = 1000
+)
+
+// NewAccessLogMiddleware returns a new access log middleware. The publisher
+// is only required when audit events are configured.
+func NewAccessLogMiddleware(logger logger.Logger, publisher pubsub.PubSub) *Middleware {
+	return &Middleware{
+		logger:    logger,
+		publisher: publisher,
+		out:       os.Stdout,
+		audits:    make(chan *auditEvent, auditQueueSize),
+		stopCh:    make(chan struct{}),
+	}
+}
+
+// Middleware is an access logging and audit middleware
+type Middleware struct {
+	logger    logger.Logger
+	publisher pubsub.PubSub
+
+	outLock sync.Mutex
+	out     io.Writer
+
+	// audits is drained by a single worker, so a slow publisher doesn't grow goroutines
+	audits      chan *auditEvent
+	dropped     uint64
+	startWorker sync.Once
+	stopOnce    sync.Once
+	stopCh      chan struct{}
+}
+
+// auditEvent is an audit event waiting to be published
+type auditEvent struct {
+	meta    *accessLogMiddlewareMetadata
+	subject string
+	data    []byte
+}
+
+// GetHandler returns the HTTP handler provided by the middleware
+func (m *Middleware) GetHandler(metadata middleware.Metadata) (func(h fasthttp.RequestHandler) fasthttp.RequestHandler, error) {
+	meta, err := m.getNativeMetadata(metadata)
+	if err != nil {
+		return nil, err
+	}
+	if meta.AuditTopic != "" && m.publisher == nil {
+		return nil, fmt.Errorf("accesslog middleware property %s requires a pubsub component", auditTopicKey)
+	}
+	if meta.AuditTopic != "" {
+		m.startWorker.Do(func() { go m.publishAudits() })
+	}
+
+	return func(h fasthttp.RequestHandler) fasthttp.RequestHandler {
+		return func(ctx *fasthttp.RequestCtx) {
+			start := time.Now()
+			h(ctx)
+
+			p := string(ctx.Path())
+			audit := meta.AuditTopic != "" && matchesRoute(meta.AuditRoutes, p)
+			if !audit && (meta.SampleRate <= 0 || rand.Float64() >= meta.SampleRate) { //nolint:gosec
+				return
+			}
+
+			entry := m.newEntry(ctx, meta, start)
+			b, err := json.Marshal(entry)
+			if err != nil {
+				m.logger.Errorf("accesslog middleware: error marshaling log entry: %s", err)
+				return
+			}
+
+			m.write(b)
+			if audit {
+				m.queueAudit(&auditEvent{meta: meta, subject: p, data: b})
+			}
+		}
+	}, nil
+}
+
+func (m *Middleware) newEntry(ctx *fasthttp.RequestCtx, meta *accessLogMiddlewareMetadata, start time.Time) *accessLogEntry {
+	entry := &accessLogEntry{
+		Time:      start.UTC().Format(time.RFC3339Nano),
+		Method:    string(ctx.Method()),
+		Path:      string(ctx.Path()),
+		Status:    ctx.Response.StatusCode(),
+		LatencyMs: float64(time.Since(start)) / float64(time.Millisecond),
+		BytesIn:   len(ctx.Request.Body()),
+		BytesOut:  len(ctx.Response.Body()),
+		ClientIP:  clientIP(ctx, meta.TrustForwardedFor),
+		TraceID:   traceID(ctx.Request.Header.Peek(meta.TraceIDHeader)),
+	}
+
+	if meta.LogHeaders {
+		entry.Headers = map[string]string{}
+		ctx.Request.Header.VisitAll(func(k, v []byte) {
+			key := string(k)
+			if meta.RedactHeaders[strings.ToLower(key)] {
+				entry.Headers[key] = redactedValue
+				return
+			}
+			entry.Headers[key] = string(v)
+		})
+	}
+
+	return entry
+}
+
+func (m *Middleware) write(b []byte) {
+	m.outLock.Lock()
+	defer m.outLock.Unlock()
+
+	if _, err := m.out.Write(append(b, '\n')); err != nil {
+		m.logger.Errorf("accesslog middleware: error writing log entry: %s", err)
+	}
+}
+
+// Close stops publishing audit events, queued events are dropped
+func (m *Middleware) Close() error {
+	m.stopOnce.Do(func() { close(m.stopCh) })
+	return nil
+}
+
+// DroppedAuditEvents returns the number of audit events dropped because the queue was full
+func (m *Middleware) DroppedAuditEvents() uint64 {
+	return atomic.LoadUint64(&m.dropped)
+}
+
+// queueAudit queues an audit event without blocking the request, and drops it when the queue is full
+func (m *Middleware) queueAudit(event *auditEvent) {
+	select {
+	case m.audits <- event:
+	default:
+		// Log the first drop and every thousandth after it, instead of every request of an overload
+		if dropped := atomic.AddUint64(&m.dropped, 1); dropped%1000 == 1 {
+			m.logger.Warnf("accesslog middleware: audit queue is full, %d audit events dropped so far", dropped)
+		}
+	}
+}
+
+func (m *Middleware) publishAudits() {
+	for {
+		select {
+		case event := <-m.audits:
+			m.publishAudit(event)
+		case <-m.stopCh:
+			return
+		}
+	}
+}
+
+func (m *Middleware) publishAudit(event *auditEvent) {
+	meta := event.meta
+	envelope := pubsub.NewCloudEventsEnvelope(uuid.New().String(), meta.AuditSource, AuditEventType, event.subject, event.data)
+	b, err := json.Marshal(envelope)
+	if err != nil {
+		m.logger.Errorf("accesslog middleware: error marshaling audit event: %s", err)
+		return
+	}
+
+	err = m.publisher.Publish(&pubsub.PublishRequest{
+		Topic: meta.AuditTopic,
+		Data:  b,
+	})
+	if err != nil {
+		m.logger.Errorf("accesslog middleware: error publishing audit event to topic %s: %s", meta.AuditTopic, err)
+	}
+}
+
+func clientIP(ctx *fasthttp.RequestCtx, trustForwardedFor bool) string {
+	if trustForwardedFor {
+		if xff := ctx.Request.Header.Peek("X-Forwarded-For"); len(xff) > 0 {
+			return strings.TrimSpace(strings.Split(string(xff), ",")[0])
+		}
+	}
+	return ctx.RemoteIP().String()
+}
+
+// traceID returns the trace ID from a W3C traceparent header value, or the
+// value itself for other propagation formats
+func traceID(value []byte) string {
+	v := string(value)
+	parts := strings.Split(v, "-")
+	if len(parts) == 4 && len(parts[1]) == 32 {
+		return parts[1]
+	}
+	return v
+}
+
+func matchesRoute(routes []string, p string) bool {
+	for _, route := range routes {
+		if strings.ContainsAny(route, "*?[") {
+			if ok, _ := path.Match(route, p); ok {
+				return true
+			}
+		} else if strings.HasPrefix(p, route) {
+			return true
+		}
+	}
+	return false
+}
+
+func (m *Middleware) getNativeMetadata(metadata middleware.Metadata) (*accessLogMiddlewareMetadata, error) {
+	middlewareMetadata := accessLogMiddlewareMetadata{
+		SampleRate:    defaultSampleRate,
+		TraceIDHeader: defaultTraceIDHeader,
+		AuditSource:   defaultAuditSource,
+	}
+
+	if val, ok := metadata.Properties[sampleRateKey]; ok && val != "" {
+		f, err := strconv.ParseFloat(val, 64)
+		if err != nil {
+			return nil, fmt.Errorf("error parsing accesslog middleware property %s: %+v", sampleRateKey, err)
+		}
+		if f < 0 || f > 1 {
+			return nil, fmt.Errorf("accesslog middleware property %s must be between 0 and 1", sampleRateKey)
+		}
+		middlewareMetadata.SampleRate = f
+	}
+	for key, target := range map[string]*bool{
+		logHeadersKey:        &middlewareMetadata.LogHeaders,
+		trustForwardedForKey: &middlewareMetadata.TrustForwardedFor,
+	} {
+		if val, ok := metadata.Properties[key]; ok && val != "" {
+			b, err := strconv.ParseBool(val)
+			if err != nil {
+				return nil, fmt.Errorf("error parsing accesslog middleware property %s: %+v", key, err)
+			}
+			*target = b
+		}
+	}
+
+	redactHeaders := defaultRedactHeaders
+	if val, ok := metadata.Properties[redactHeadersKey]; ok {
+		redactHeaders = val
+	}
+	middlewareMetadata.RedactHeaders = map[string]bool{}
+	for _, h := range splitList(redactHeaders) {
+		middlewareMetadata.RedactHeaders[strings.ToLower(h)] = true
+	}
+
+	if val, ok := metadata.Properties[traceIDHeaderKey]; ok && val != "" {
+		middlewareMetadata.TraceIDHeader = val
+	}
+	if val, ok := metadata.Properties[auditSourceKey]; ok && val != "" {
+		middlewareMetadata.AuditSource = val
+	}
+	middlewareMetadata.AuditTopic = metadata.Properties[auditTopicKey]
+	middlewareMetadata.AuditRoutes = splitList(metadata.Properties[auditRoutesKey])
+	for _, route := range middlewareMetadata.AuditRoutes {
+		if _, err := path.Match(route, "/"); err != nil {
+			return nil, fmt.Errorf("accesslog middleware property %s: invalid route %s: %s", auditRoutesKey, route, err)
+		}
+	}
+	if middlewareMetadata.AuditTopic != "" && len(middlewareMetadata.AuditRoutes) == 0 {
+		return nil, fmt.Errorf("accesslog middleware property %s requires %s", auditTopicKey, auditRoutesKey)
+	}
+
+	return &middlewareMetadata, nil
+}
+
+func splitList(val string) []string {
+	var items []string
+	for _, item := range strings.Split(val, ",") {
+		if item = strings.TrimSpace(item); item != "" {
+			items = append(items, item)
+		}
+	}
+	return items
+}
@@ -0,0 +1,174 @@
+// ------------------------------------------------------------
+// Copyright (c) Microsoft Corporation.
+// Licensed under the MIT License.
+// ------------------------------------------------------------
+
+package accesslog
+
+import (
+	"bytes"
+	"encoding/json"
+	"net"
+	"testing"
+	"time"
+
+	"github.com/dapr/components-contrib/middleware"
+	"github.com/dapr/components-contrib/pubsub"
+	"github.com/dapr/dapr/pkg/logger"
+	"github.com/stretchr/testify/assert"
+	"github.com/valyala/fasthttp"
+)
+
+type fakePublisher struct {
+	published chan *pubsub.PublishRequest
+}
+
+func (f *fakePublisher) Init(metadata pubsub.Metadata) error {
+	return nil
+}
+
+func (f *fakePublisher) Publish(req *pubsub.PublishRequest) error {
+	f.published <- req
+	return nil
+}
+
+func (f *fakePublisher) Subscribe(req pubsub.SubscribeRequest, handler func(msg *pubsub.NewMessage) error) error {
+	return nil
+}
+
+func newTestCtx(method, uri string) *fasthttp.RequestCtx {
+	req := fasthttp.Request{}
+	req.Header.SetMethod(method)
+	req.SetRequestURI(uri)
+
+	ctx := &fasthttp.RequestCtx{}
+	ctx.Init(&req, &net.TCPAddr{IP: net.ParseIP("10.0.0.1"), Port: 4000}, nil)
+	return ctx
+}
+
+func TestAccessLog(t *testing.T) {
+	var out bytes.Buffer
+	m := NewAccessLogMiddleware(logger.NewLogger("test"), nil)
+	m.out = &out
+
+	wrapper, err := m.GetHandler(middleware.Metadata{Properties: map[string]string{logHeadersKey: "true"}})
+	assert.Nil(t, err)
+	handler := wrapper(func(ctx *fasthttp.RequestCtx) {
+		ctx.SetStatusCode(fasthttp.StatusCreated)
+		ctx.Response.SetBodyString("created")
+	})
+
+	ctx := newTestCtx("POST", "http://localhost/orders")
+	ctx.Request.Header.Set("Authorization", "Bearer token")
+	ctx.Request.Header.Set("traceparent", "00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01")
+	ctx.Request.SetBodyString("order")
+	handler(ctx)
+
+	var entry accessLogEntry
+	assert.Nil(t, json.Unmarshal(out.Bytes(), &entry))
+	assert.Equal(t, "POST", entry.Method)
+	assert.Equal(t, "/orders", entry.Path)
+	assert.Equal(t, fasthttp.StatusCreated, entry.Status)
+	assert.Equal(t, 5, entry.BytesIn)
+	assert.Equal(t, 7, entry.BytesOut)
+	assert.Equal(t, "10.0.0.1", entry.ClientIP)
+	assert.Equal(t, "4bf92f3577b34da6a3ce929d0e0e4736", entry.TraceID)
+	assert.Equal(t, redactedValue, entry.Headers["Authorization"])
+}
+
+func TestAccessLogSampling(t *testing.T) {
+	var out bytes.Buffer
+	m := NewAccessLogMiddleware(logger.NewLogger("test"), nil)
+	m.out = &out
+
+	wrapper, err := m.GetHandler(middleware.Metadata{Properties: map[string]string{sampleRateKey: "0"}})
+	assert.Nil(t, err)
+	handler := wrapper(func(ctx *fasthttp.RequestCtx) {})
+	handler(newTestCtx("GET", "http://localhost/health"))
+	assert.Equal(t, 0, out.Len())
+}
+
+func TestAuditEvents(t *testing.T) {
+	var out bytes.Buffer
+	publisher := &fakePublisher{published: make(chan *pubsub.PublishRequest, 1)}
+	m := NewAccessLogMiddleware(logger.NewLogger("test"), publisher)
+	m.out = &out
+
+	wrapper, err := m.GetHandler(middleware.Metadata{Properties: map[string]string{
+		sampleRateKey:  "0",
+		auditTopicKey:  "audit",
+		auditRoutesKey: "/admin/,/users/*/delete",
+		auditSourceKey: "myapp",
+	}})
+	assert.Nil(t, err)
+	handler := wrapper(func(ctx *fasthttp.RequestCtx) {})
+
+	handler(newTestCtx("POST", "http://localhost/users/42/delete"))
+
+	select {
+	case req := <-publisher.published:
+		assert.Equal(t, "audit", req.Topic)
+		var envelope pubsub.CloudEventsEnvelope
+		assert.Nil(t, json.Unmarshal(req.Data, &envelope))
+		assert.Equal(t, AuditEventType, envelope.Type)
+		assert.Equal(t, "myapp", envelope.Source)
+		assert.Equal(t, "/users/42/delete", envelope.Subject)
+	case <-time.After(time.Second * 5):
+		assert.Fail(t, "audit event was not published")
+	}
+
+	// Audited requests are always logged, regardless of sampling
+	assert.NotEqual(t, 0, out.Len())
+}
+
+func TestAuditQueueDropsOnOverflow(t *testing.T) {
+	publisher := &fakePublisher{published: make(chan *pubsub.PublishRequest)}
+	m := NewAccessLogMiddleware(logger.NewLogger("test"), publisher)
+	m.out = &bytes.Buffer{}
+	m.audits = make(chan *auditEvent, 1)
+	defer m.Close()
+
+	wrapper, err := m.GetHandler(middleware.Metadata{Properties: map[string]string{
+		auditTopicKey:  "audit",
+		auditRoutesKey: "/admin/",
+	}})
+	assert.Nil(t, err)
+	handler := wrapper(func(ctx *fasthttp.RequestCtx) {})
+
+	// The publisher blocks, so at most one event is being published and one is queued
+	done := make(chan struct{})
+	go func() {
+		for i := 0; i < 5; i++ {
+			handler(newTestCtx("POST", "http://localhost/admin/users"))
+		}
+		close(done)
+	}()
+	select {
+	case <-done:
+	case <-time.After(time.Second * 5):
+		t.Fatal("requests waited for the audit publisher")
+	}
+	dropped := m.DroppedAuditEvents()
+	assert.True(t, dropped >= 3)
+
+	// The worker continues with the events that weren't dropped
+	for i := uint64(0); i < 5-dropped; i++ {
+		<-publisher.published
+	}
+}
+
+func TestInvalidMetadata(t *testing.T) {
+	m := NewAccessLogMiddleware(logger.NewLogger("test"), nil)
+	tests := map[string]map[string]string{
+		"bad sample rate":         {sampleRateKey: "2"},
+		"bad bool":                {logHeadersKey: "maybe"},
+		"topic without routes":    {auditTopicKey: "audit"},
+		"topic without publisher": {auditTopicKey: "audit", auditRoutesKey: "/admin"},
+	}
+	for name, properties := range tests {
+		t.Run(name, func(t *testing.T) {
+			_, err := m.GetHandler(middleware.Metadata{Properties: properties})
+			assert.NotNil(t, err)
+		})
+	}
+}
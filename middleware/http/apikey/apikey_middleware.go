@@ -0,0 +1,306 @@
+// ------------------------------------------------------------
+// Copyright (c) Microsoft Corporation.
+// Licensed under the MIT License.
+// ------------------------------------------------------------
+
+package apikey
+
+import (
+	"crypto/sha256"
+	"crypto/subtle"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/dapr/components-contrib/middleware"
+	"github.com/dapr/components-contrib/secretstores"
+	"github.com/dapr/dapr/pkg/logger"
+	"github.com/valyala/fasthttp"
+)
+
+// Metadata is the API key middleware config
+type apiKeyMiddlewareMetadata struct {
+	HeaderName     string
+	QueryParam     string
+	SecretNames    []string
+	ReloadInterval time.Duration
+	ClientIDHeader string
+	ScopesHeader   string
+	RequiredScopes []string
+}
+
+// apiKey is a hashed key loaded from the secret store
+type apiKey struct {
+	ClientID string   `json:"-"`
+	Hash     string   `json:"hash"`
+	Scopes   []string `json:"scopes"`
+
+	hash []byte
+}
+
+const (
+	headerNameKey     = "headerName"
+	queryParamKey     = "queryParam"
+	secretNamesKey    = "secretNames"
+	reloadIntervalKey = "reloadInterval"
+	clientIDHeaderKey = "clientIDHeader"
+	scopesHeaderKey   = "scopesHeader"
+	requiredScopesKey = "requiredScopes"
+
+	// Defaults
+	defaultHeaderName     = "X-API-Key"
+	defaultClientIDHeader = "X-Client-ID"
+	defaultScopesHeader   = "X-Client-Scopes"
+	defaultReloadInterval = time.Minute * 5
+)
+
+// NewAPIKeyMiddleware returns a new API key middleware that validates keys
+// against the hashes held in the given secret store
+func NewAPIKeyMiddleware(logger logger.Logger, store secretstores.SecretStore) *Middleware {
+	return &Middleware{logger: logger, store: store}
+}
+
+// Middleware is an API key authentication middleware
+type Middleware struct {
+	logger logger.Logger
+	store  secretstores.SecretStore
+
+	lock  sync.Mutex
+	stops []chan struct{}
+}
+
+// keySet holds the keys of one handler, so handlers with different secrets don't share them
+type keySet struct {
+	logger      logger.Logger
+	store       secretstores.SecretStore
+	secretNames []string
+
+	lock sync.RWMutex
+	keys []*apiKey
+}
+
+// GetHandler returns the HTTP handler provided by the middleware
+func (m *Middleware) GetHandler(metadata middleware.Metadata) (func(h fasthttp.RequestHandler) fasthttp.RequestHandler, error) {
+	meta, err := m.getNativeMetadata(metadata)
+	if err != nil {
+		return nil, err
+	}
+	if m.store == nil {
+		return nil, errors.New("apikey middleware requires a secret store")
+	}
+
+	keys := &keySet{logger: m.logger, store: m.store, secretNames: meta.SecretNames}
+	if err = keys.load(); err != nil {
+		return nil, err
+	}
+	if meta.ReloadInterval > 0 {
+		stopCh := make(chan struct{})
+		m.lock.Lock()
+		m.stops = append(m.stops, stopCh)
+		m.lock.Unlock()
+		go keys.reload(meta.ReloadInterval, stopCh)
+	}
+
+	return func(h fasthttp.RequestHandler) fasthttp.RequestHandler {
+		return func(ctx *fasthttp.RequestCtx) {
+			key := keys.lookup(extractKey(ctx, meta))
+			if key == nil {
+				ctx.Error(fasthttp.StatusMessage(fasthttp.StatusUnauthorized), fasthttp.StatusUnauthorized)
+				return
+			}
+			if !hasScopes(key.Scopes, meta.RequiredScopes) {
+				ctx.Error(fasthttp.StatusMessage(fasthttp.StatusForbidden), fasthttp.StatusForbidden)
+				return
+			}
+
+			// The key itself is never passed on to the app
+			ctx.Request.Header.Del(meta.HeaderName)
+			if meta.QueryParam != "" && ctx.QueryArgs().Has(meta.QueryParam) {
+				removeQueryParam(ctx, meta.QueryParam)
+			}
+			ctx.Request.Header.Set(meta.ClientIDHeader, key.ClientID)
+			ctx.Request.Header.Set(meta.ScopesHeader, strings.Join(key.Scopes, ","))
+
+			h(ctx)
+		}
+	}, nil
+}
+
+// Close stops reloading the keys of the handlers returned by GetHandler
+func (m *Middleware) Close() error {
+	m.lock.Lock()
+	defer m.lock.Unlock()
+
+	for _, stopCh := range m.stops {
+		close(stopCh)
+	}
+	m.stops = nil
+	return nil
+}
+
+func extractKey(ctx *fasthttp.RequestCtx, meta *apiKeyMiddlewareMetadata) string {
+	if v := ctx.Request.Header.Peek(meta.HeaderName); len(v) > 0 {
+		return string(v)
+	}
+	if meta.QueryParam != "" {
+		return string(ctx.QueryArgs().Peek(meta.QueryParam))
+	}
+	return ""
+}
+
+// removeQueryParam removes a query parameter from the URI and from the request line,
+// which is what access logs and the app see
+func removeQueryParam(ctx *fasthttp.RequestCtx, name string) {
+	args := ctx.QueryArgs()
+	args.Del(name)
+	ctx.URI().SetQueryStringBytes(args.QueryString())
+	ctx.Request.Header.SetRequestURIBytes(ctx.URI().RequestURI())
+}
+
+// lookup compares the hash of the given key against every loaded key in
+// constant time, so the time taken doesn't reveal which key came closest
+func (s *keySet) lookup(key string) *apiKey {
+	if key == "" {
+		return nil
+	}
+
+	sum := sha256.Sum256([]byte(key))
+
+	s.lock.RLock()
+	defer s.lock.RUnlock()
+
+	var found *apiKey
+	for _, k := range s.keys {
+		if subtle.ConstantTimeCompare(sum[:], k.hash) == 1 && found == nil {
+			found = k
+		}
+	}
+	return found
+}
+
+func (s *keySet) reload(interval time.Duration, stopCh <-chan struct{}) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ticker.C:
+			if err := s.load(); err != nil {
+				s.logger.Warnf("apikey middleware: failed to reload keys, keeping the previous set: %s", err)
+			}
+		case <-stopCh:
+			return
+		}
+	}
+}
+
+// loadKeys reads the keys from the secret store. Each secret entry maps a
+// client ID to either a hex encoded SHA-256 hash of the key, or to a JSON
+// object with the hash and the scopes granted to the client.
+func (s *keySet) load() error {
+	var keys []*apiKey
+	for _, name := range s.secretNames {
+		resp, err := s.store.GetSecret(secretstores.GetSecretRequest{Name: name})
+		if err != nil {
+			return fmt.Errorf("apikey middleware: error reading secret %s: %s", name, err)
+		}
+		for clientID, value := range resp.Data {
+			key, err := parseKey(clientID, value)
+			if err != nil {
+				return fmt.Errorf("apikey middleware: secret %s: %s", name, err)
+			}
+			keys = append(keys, key)
+		}
+	}
+
+	s.lock.Lock()
+	s.keys = keys
+	s.lock.Unlock()
+	return nil
+}
+
+func parseKey(clientID string, value string) (*apiKey, error) {
+	key := &apiKey{Hash: strings.TrimSpace(value)}
+	if strings.HasPrefix(key.Hash, "{") {
+		if err := json.Unmarshal([]byte(value), key); err != nil {
+			return nil, fmt.Errorf("invalid key entry for client %s: %s", clientID, err)
+		}
+	}
+	key.ClientID = clientID
+
+	hash, err := hex.DecodeString(key.Hash)
+	if err != nil || len(hash) != sha256.Size {
+		return nil, fmt.Errorf("key for client %s is not a hex encoded SHA-256 hash", clientID)
+	}
+	key.hash = hash
+	return key, nil
+}
+
+func hasScopes(granted []string, required []string) bool {
+	for _, r := range required {
+		found := false
+		for _, g := range granted {
+			if g == r {
+				found = true
+				break
+			}
+		}
+		if !found {
+			return false
+		}
+	}
+	return true
+}
+
+func (m *Middleware) getNativeMetadata(metadata middleware.Metadata) (*apiKeyMiddlewareMetadata, error) {
+	middlewareMetadata := apiKeyMiddlewareMetadata{
+		HeaderName:     defaultHeaderName,
+		ClientIDHeader: defaultClientIDHeader,
+		ScopesHeader:   defaultScopesHeader,
+		ReloadInterval: defaultReloadInterval,
+	}
+
+	if val, ok := metadata.Properties[headerNameKey]; ok && val != "" {
+		middlewareMetadata.HeaderName = val
+	}
+	if val, ok := metadata.Properties[queryParamKey]; ok {
+		middlewareMetadata.QueryParam = val
+	}
+	if val, ok := metadata.Properties[clientIDHeaderKey]; ok && val != "" {
+		middlewareMetadata.ClientIDHeader = val
+	}
+	if val, ok := metadata.Properties[scopesHeaderKey]; ok && val != "" {
+		middlewareMetadata.ScopesHeader = val
+	}
+	if val, ok := metadata.Properties[reloadIntervalKey]; ok && val != "" {
+		d, err := time.ParseDuration(val)
+		if err != nil {
+			return nil, fmt.Errorf("error parsing apikey middleware property %s: %+v", reloadIntervalKey, err)
+		}
+		if d < 0 {
+			return nil, fmt.Errorf("apikey middleware property %s must not be negative", reloadIntervalKey)
+		}
+		middlewareMetadata.ReloadInterval = d
+	}
+	middlewareMetadata.SecretNames = splitList(metadata.Properties[secretNamesKey])
+	if len(middlewareMetadata.SecretNames) == 0 {
+		return nil, fmt.Errorf("missing apikey middleware property %s", secretNamesKey)
+	}
+	middlewareMetadata.RequiredScopes = splitList(metadata.Properties[requiredScopesKey])
+
+	return &middlewareMetadata, nil
+}
+
+func splitList(val string) []string {
+	var items []string
+	for _, item := range strings.Split(val, ",") {
+		if item = strings.TrimSpace(item); item != "" {
+			items = append(items, item)
+		}
+	}
+	return items
+}
@@ -0,0 +1,206 @@
+// ------------------------------------------------------------
+// Copyright (c) Microsoft Corporation.
+// Licensed under the MIT License.
+// ------------------------------------------------------------
+
+package apikey
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"testing"
+
+	"github.com/dapr/components-contrib/middleware"
+	"github.com/dapr/components-contrib/secretstores"
+	"github.com/dapr/dapr/pkg/logger"
+	"github.com/stretchr/testify/assert"
+	"github.com/valyala/fasthttp"
+)
+
+type fakeSecretStore struct {
+	secrets map[string]map[string]string
+}
+
+func (f *fakeSecretStore) Init(metadata secretstores.Metadata) error {
+	return nil
+}
+
+func (f *fakeSecretStore) GetSecret(req secretstores.GetSecretRequest) (secretstores.GetSecretResponse, error) {
+	data, ok := f.secrets[req.Name]
+	if !ok {
+		return secretstores.GetSecretResponse{}, fmt.Errorf("secret %s not found", req.Name)
+	}
+	return secretstores.GetSecretResponse{Data: data}, nil
+}
+
+func hashKey(key string) string {
+	sum := sha256.Sum256([]byte(key))
+	return hex.EncodeToString(sum[:])
+}
+
+func newTestStore() *fakeSecretStore {
+	return &fakeSecretStore{
+		secrets: map[string]map[string]string{
+			"apikeys": {
+				"billing":   hashKey("billing-key"),
+				"reporting": fmt.Sprintf(`{"hash": "%s", "scopes": ["read", "write"]}`, hashKey("reporting-key")),
+			},
+		},
+	}
+}
+
+func newTestHandler(t *testing.T, store secretstores.SecretStore, properties map[string]string, next fasthttp.RequestHandler) fasthttp.RequestHandler {
+	m := NewAPIKeyMiddleware(logger.NewLogger("test"), store)
+	handler, err := m.GetHandler(middleware.Metadata{Properties: properties})
+	assert.Nil(t, err)
+	return handler(next)
+}
+
+func TestAPIKeyMiddleware(t *testing.T) {
+	properties := map[string]string{
+		secretNamesKey:    "apikeys",
+		queryParamKey:     "api_key",
+		reloadIntervalKey: "0",
+	}
+
+	var clientID, scopes, forwardedKey, forwardedURI string
+	handler := newTestHandler(t, newTestStore(), properties, func(ctx *fasthttp.RequestCtx) {
+		clientID = string(ctx.Request.Header.Peek(defaultClientIDHeader))
+		scopes = string(ctx.Request.Header.Peek(defaultScopesHeader))
+		forwardedKey = string(ctx.Request.Header.Peek(defaultHeaderName))
+		forwardedURI = string(ctx.RequestURI())
+		if ctx.QueryArgs().Has("api_key") {
+			forwardedKey = string(ctx.QueryArgs().Peek("api_key"))
+		}
+	})
+
+	t.Run("valid key in header", func(t *testing.T) {
+		var ctx fasthttp.RequestCtx
+		ctx.Request.SetRequestURI("http://localhost/test")
+		ctx.Request.Header.Set(defaultHeaderName, "reporting-key")
+		handler(&ctx)
+		assert.Equal(t, fasthttp.StatusOK, ctx.Response.StatusCode())
+		assert.Equal(t, "reporting", clientID)
+		assert.Equal(t, "read,write", scopes)
+		assert.Equal(t, "", forwardedKey)
+	})
+
+	t.Run("valid key in query", func(t *testing.T) {
+		var ctx fasthttp.RequestCtx
+		ctx.Request.SetRequestURI("http://localhost/test?api_key=billing-key")
+		handler(&ctx)
+		assert.Equal(t, fasthttp.StatusOK, ctx.Response.StatusCode())
+		assert.Equal(t, "billing", clientID)
+		assert.Equal(t, "", forwardedKey)
+		assert.Equal(t, "/test", forwardedURI)
+	})
+
+	t.Run("other query parameters are kept", func(t *testing.T) {
+		var ctx fasthttp.RequestCtx
+		ctx.Request.SetRequestURI("http://localhost/test?page=2&api_key=billing-key")
+		handler(&ctx)
+		assert.Equal(t, fasthttp.StatusOK, ctx.Response.StatusCode())
+		assert.Equal(t, "", forwardedKey)
+		assert.Equal(t, "/test?page=2", forwardedURI)
+	})
+
+	t.Run("invalid key", func(t *testing.T) {
+		var ctx fasthttp.RequestCtx
+		ctx.Request.SetRequestURI("http://localhost/test")
+		ctx.Request.Header.Set(defaultHeaderName, "wrong-key")
+		handler(&ctx)
+		assert.Equal(t, fasthttp.StatusUnauthorized, ctx.Response.StatusCode())
+	})
+
+	t.Run("missing key", func(t *testing.T) {
+		var ctx fasthttp.RequestCtx
+		ctx.Request.SetRequestURI("http://localhost/test")
+		handler(&ctx)
+		assert.Equal(t, fasthttp.StatusUnauthorized, ctx.Response.StatusCode())
+	})
+}
+
+func TestAPIKeyMiddlewareRequiredScopes(t *testing.T) {
+	properties := map[string]string{
+		secretNamesKey:    "apikeys",
+		requiredScopesKey: "write",
+		reloadIntervalKey: "0",
+	}
+	handler := newTestHandler(t, newTestStore(), properties, func(ctx *fasthttp.RequestCtx) {})
+
+	var ctx fasthttp.RequestCtx
+	ctx.Request.SetRequestURI("http://localhost/test")
+	ctx.Request.Header.Set(defaultHeaderName, "billing-key")
+	handler(&ctx)
+	assert.Equal(t, fasthttp.StatusForbidden, ctx.Response.StatusCode())
+}
+
+func TestAPIKeyMiddlewareReload(t *testing.T) {
+	store := newTestStore()
+	keys := &keySet{logger: logger.NewLogger("test"), store: store, secretNames: []string{"apikeys"}}
+	assert.Nil(t, keys.load())
+	assert.NotNil(t, keys.lookup("billing-key"))
+
+	store.secrets["apikeys"]["billing"] = hashKey("rotated-key")
+	assert.Nil(t, keys.load())
+	assert.Nil(t, keys.lookup("billing-key"))
+	assert.NotNil(t, keys.lookup("rotated-key"))
+}
+
+func TestAPIKeyMiddlewareHandlersKeepTheirKeys(t *testing.T) {
+	store := newTestStore()
+	store.secrets["otherkeys"] = map[string]string{"other": hashKey("other-key")}
+	m := NewAPIKeyMiddleware(logger.NewLogger("test"), store)
+	defer m.Close()
+
+	status := func(secretNames string, key string) int {
+		handler, err := m.GetHandler(middleware.Metadata{Properties: map[string]string{secretNamesKey: secretNames}})
+		assert.Nil(t, err)
+
+		var ctx fasthttp.RequestCtx
+		ctx.Request.SetRequestURI("http://localhost/test")
+		ctx.Request.Header.Set(defaultHeaderName, key)
+		handler(func(ctx *fasthttp.RequestCtx) {})(&ctx)
+		return ctx.Response.StatusCode()
+	}
+
+	first, err := m.GetHandler(middleware.Metadata{Properties: map[string]string{secretNamesKey: "apikeys"}})
+	assert.Nil(t, err)
+	assert.Equal(t, fasthttp.StatusOK, status("otherkeys", "other-key"))
+
+	// Building another handler doesn't replace the keys of the first
+	var ctx fasthttp.RequestCtx
+	ctx.Request.SetRequestURI("http://localhost/test")
+	ctx.Request.Header.Set(defaultHeaderName, "billing-key")
+	first(func(ctx *fasthttp.RequestCtx) {})(&ctx)
+	assert.Equal(t, fasthttp.StatusOK, ctx.Response.StatusCode())
+	assert.Equal(t, fasthttp.StatusUnauthorized, status("otherkeys", "billing-key"))
+}
+
+func TestAPIKeyMiddlewareCloseStopsReloading(t *testing.T) {
+	m := NewAPIKeyMiddleware(logger.NewLogger("test"), newTestStore())
+	_, err := m.GetHandler(middleware.Metadata{Properties: map[string]string{secretNamesKey: "apikeys", reloadIntervalKey: "1h"}})
+	assert.Nil(t, err)
+	assert.Len(t, m.stops, 1)
+
+	assert.Nil(t, m.Close())
+	assert.Len(t, m.stops, 0)
+}
+
+func TestAPIKeyMiddlewareInvalidConfig(t *testing.T) {
+	m := NewAPIKeyMiddleware(logger.NewLogger("test"), newTestStore())
+
+	_, err := m.GetHandler(middleware.Metadata{Properties: map[string]string{}})
+	assert.NotNil(t, err)
+
+	_, err = m.GetHandler(middleware.Metadata{Properties: map[string]string{secretNamesKey: "missing"}})
+	assert.NotNil(t, err)
+
+	_, err = m.GetHandler(middleware.Metadata{Properties: map[string]string{secretNamesKey: "apikeys", reloadIntervalKey: "soon"}})
+	assert.NotNil(t, err)
+
+	store := &fakeSecretStore{secrets: map[string]map[string]string{"apikeys": {"client": "not-a-hash"}}}
+	_, err = NewAPIKeyMiddleware(logger.NewLogger("test"), store).GetHandler(middleware.Metadata{Properties: map[string]string{secretNamesKey: "apikeys"}})
+	assert.NotNil(t, err)
+}
@@ -0,0 +1,166 @@
+// Package nethttpadaptor converts between fasthttp and net/http handlers.
+//
+// Both directions buffer: a net/http handler hosted with NewFastHTTPHandler
+// receives the fully read fasthttp request body, and its response is sent once
+// it returns, so its ResponseWriter does not implement http.Flusher. Streamed
+// fasthttp response bodies are flushed by NewNetHTTPHandlerFunc as they are written.
+package nethttpadaptor
+
+import (
+	"bytes"
+	"io/ioutil"
+	"net/http"
+	"strings"
+
+	"github.com/dapr/components-contrib/middleware"
+	"github.com/dapr/dapr/pkg/logger"
+	"github.com/valyala/fasthttp"
+)
+
+// NetHTTPMiddlewareFactory creates a net/http middleware from the middleware metadata
+type NetHTTPMiddlewareFactory func(metadata middleware.Metadata) (func(next http.Handler) http.Handler, error)
+
+// NewMiddleware hosts a net/http middleware as a middleware.Middleware
+func NewMiddleware(logger logger.Logger, factory NetHTTPMiddlewareFactory) middleware.Middleware {
+	return &netHTTPMiddleware{logger: logger, factory: factory}
+}
+
+type netHTTPMiddleware struct {
+	logger  logger.Logger
+	factory NetHTTPMiddlewareFactory
+}
+
+// GetHandler returns the HTTP handler provided by the wrapped net/http middleware
+func (m *netHTTPMiddleware) GetHandler(metadata middleware.Metadata) (func(h fasthttp.RequestHandler) fasthttp.RequestHandler, error) {
+	mw, err := m.factory(metadata)
+	if err != nil {
+		return nil, err
+	}
+
+	return func(h fasthttp.RequestHandler) fasthttp.RequestHandler {
+		return NewFastHTTPHandler(m.logger, mw(NewNetHTTPHandlerFunc(m.logger, h)))
+	}, nil
+}
+
+// NewFastHTTPHandler wraps a http.Handler in a fasthttp.RequestHandler.
+// Unlike fasthttpadaptor, it keeps repeated headers, trailers and the
+// fasthttp.RequestCtx, which is exposed as the request context.
+func NewFastHTTPHandler(logger logger.Logger, h http.Handler) fasthttp.RequestHandler {
+	return func(ctx *fasthttp.RequestCtx) {
+		body := ctx.Request.Body()
+		r, err := http.NewRequest(string(ctx.Method()), string(ctx.RequestURI()), ioutil.NopCloser(bytes.NewReader(body)))
+		if err != nil {
+			logger.Errorf("error converting request, %+v", err)
+			ctx.Error(fasthttp.StatusMessage(fasthttp.StatusInternalServerError), fasthttp.StatusInternalServerError)
+			return
+		}
+		r = r.WithContext(ctx)
+
+		r.RequestURI = string(ctx.RequestURI())
+		r.Host = string(ctx.Host())
+		r.RemoteAddr = ctx.RemoteAddr().String()
+		r.ContentLength = int64(len(body))
+		r.URL.Scheme = string(ctx.Request.URI().Scheme())
+
+		ctx.Request.Header.VisitAll(func(k, v []byte) {
+			key := string(k)
+			switch key {
+			case "Transfer-Encoding":
+				r.TransferEncoding = append(r.TransferEncoding, string(v))
+			case "Host", "Content-Length":
+			default:
+				r.Header.Add(key, string(v))
+			}
+		})
+
+		w := &responseWriter{ctx: ctx, header: http.Header{}}
+		h.ServeHTTP(w, r)
+		w.finish()
+	}
+}
+
+// responseWriter writes a net/http response into the fasthttp response.
+// Trailers are represented as headers declared in the Trailer header, which
+// NewNetHTTPHandlerFunc turns back into real trailers.
+type responseWriter struct {
+	ctx         *fasthttp.RequestCtx
+	header      http.Header
+	wroteHeader bool
+}
+
+func (w *responseWriter) Header() http.Header {
+	return w.header
+}
+
+func (w *responseWriter) WriteHeader(statusCode int) {
+	if w.wroteHeader {
+		return
+	}
+	w.wroteHeader = true
+	w.ctx.Response.SetStatusCode(statusCode)
+	w.copyHeaders()
+}
+
+func (w *responseWriter) Write(p []byte) (int, error) {
+	if !w.wroteHeader {
+		w.WriteHeader(http.StatusOK)
+	}
+	return w.ctx.Response.BodyWriter().Write(p)
+}
+
+func (w *responseWriter) copyHeaders() {
+	for k, v := range w.header {
+		if strings.HasPrefix(k, http.TrailerPrefix) {
+			continue
+		}
+		setHeaderValues(&w.ctx.Response.Header, k, v)
+	}
+}
+
+func (w *responseWriter) finish() {
+	if !w.wroteHeader {
+		w.WriteHeader(http.StatusOK)
+	}
+
+	// Trailers are either declared up front via the Trailer header or set
+	// afterwards using the http.TrailerPrefix convention.
+	var declared []string
+	for _, name := range w.header[trailerHeader] {
+		for _, n := range strings.Split(name, ",") {
+			if n = strings.TrimSpace(n); n != "" {
+				declared = append(declared, http.CanonicalHeaderKey(n))
+			}
+		}
+	}
+	for _, name := range declared {
+		setHeaderValues(&w.ctx.Response.Header, name, w.header[name])
+	}
+	for k, v := range w.header {
+		if !strings.HasPrefix(k, http.TrailerPrefix) {
+			continue
+		}
+		name := http.CanonicalHeaderKey(strings.TrimPrefix(k, http.TrailerPrefix))
+		declared = append(declared, name)
+		setHeaderValues(&w.ctx.Response.Header, name, v)
+	}
+	if len(declared) > 0 {
+		w.ctx.Response.Header.Set(trailerHeader, strings.Join(declared, ", "))
+	}
+}
+
+// setHeaderValues replaces a response header with all the given values.
+// The first value goes through Set so that fasthttp's special headers, such
+// as Content-Type, are stored where fasthttp expects them.
+func setHeaderValues(header *fasthttp.ResponseHeader, key string, values []string) {
+	if key == fasthttp.HeaderContentLength {
+		return
+	}
+	header.Del(key)
+	for i, v := range values {
+		if i == 0 || key == fasthttp.HeaderSetCookie {
+			header.Set(key, v)
+		} else {
+			header.Add(key, v)
+		}
+	}
+}
@@ -0,0 +1,111 @@
+package nethttpadaptor
+
+import (
+	"io/ioutil"
+	"net"
+	"net/http"
+	"testing"
+
+	"github.com/dapr/components-contrib/middleware"
+	"github.com/dapr/dapr/pkg/logger"
+	"github.com/stretchr/testify/assert"
+	"github.com/valyala/fasthttp"
+)
+
+func newTestRequestCtx(method, uri, body string) *fasthttp.RequestCtx {
+	req := fasthttp.Request{}
+	req.Header.SetMethod(method)
+	req.SetRequestURI(uri)
+	req.SetBodyString(body)
+
+	ctx := &fasthttp.RequestCtx{}
+	ctx.Init(&req, &net.TCPAddr{IP: net.ParseIP("1.1.1.1"), Port: 5555}, nil)
+	return ctx
+}
+
+func TestNewFastHTTPHandler(t *testing.T) {
+	testLogger := logger.NewLogger("test")
+
+	t.Run("request is converted", func(t *testing.T) {
+		handler := NewFastHTTPHandler(testLogger, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			body, _ := ioutil.ReadAll(r.Body)
+			assert.Equal(t, "POST", r.Method)
+			assert.Equal(t, "/test/sub", r.URL.Path)
+			assert.Equal(t, "a=b", r.URL.RawQuery)
+			assert.Equal(t, "1.1.1.1:5555", r.RemoteAddr)
+			assert.Equal(t, "test body!", string(body))
+			assert.Equal(t, []string{"v1", "v2"}, r.Header["Testheader"])
+			_, isRequestCtx := r.Context().(*fasthttp.RequestCtx)
+			assert.True(t, isRequestCtx)
+		}))
+
+		ctx := newTestRequestCtx("POST", "http://localhost:8080/test/sub?a=b", "test body!")
+		ctx.Request.Header.Add("Testheader", "v1")
+		ctx.Request.Header.Add("Testheader", "v2")
+		handler(ctx)
+	})
+
+	t.Run("response is converted", func(t *testing.T) {
+		handler := NewFastHTTPHandler(testLogger, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			w.Header().Set("Content-Type", "application/json")
+			w.Header().Add("Testheader", "v1")
+			w.Header().Add("Testheader", "v2")
+			w.WriteHeader(http.StatusCreated)
+			w.Write([]byte(`{}`)) //nolint
+			w.Header().Set(http.TrailerPrefix+"Testtrailer", "trailerValue")
+		}))
+
+		ctx := newTestRequestCtx("GET", "http://localhost:8080/test", "")
+		handler(ctx)
+
+		assert.Equal(t, http.StatusCreated, ctx.Response.StatusCode())
+		assert.Equal(t, "application/json", string(ctx.Response.Header.ContentType()))
+		assert.Equal(t, "{}", string(ctx.Response.Body()))
+		assert.Equal(t, "Testtrailer", string(ctx.Response.Header.Peek("Trailer")))
+		assert.Equal(t, "trailerValue", string(ctx.Response.Header.Peek("Testtrailer")))
+
+		var values []string
+		ctx.Response.Header.VisitAll(func(k, v []byte) {
+			if string(k) == "Testheader" {
+				values = append(values, string(v))
+			}
+		})
+		assert.Equal(t, []string{"v1", "v2"}, values)
+	})
+
+	t.Run("response writer does not advertise flushing", func(t *testing.T) {
+		handler := NewFastHTTPHandler(testLogger, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			_, isFlusher := w.(http.Flusher)
+			assert.False(t, isFlusher)
+		}))
+
+		handler(newTestRequestCtx("GET", "http://localhost:8080/test", ""))
+	})
+}
+
+func TestNewMiddleware(t *testing.T) {
+	testLogger := logger.NewLogger("test")
+	m := NewMiddleware(testLogger, func(metadata middleware.Metadata) (func(next http.Handler) http.Handler, error) {
+		return func(next http.Handler) http.Handler {
+			return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				r.Header.Set("Injected", metadata.Properties["value"])
+				next.ServeHTTP(w, r)
+			})
+		}, nil
+	})
+
+	wrapper, err := m.GetHandler(middleware.Metadata{Properties: map[string]string{"value": "injected"}})
+	assert.Nil(t, err)
+
+	handler := wrapper(func(ctx *fasthttp.RequestCtx) {
+		assert.Equal(t, "injected", string(ctx.Request.Header.Peek("Injected")))
+		assert.Equal(t, "test body!", string(ctx.Request.Body()))
+		ctx.SetStatusCode(fasthttp.StatusAccepted)
+		ctx.Response.SetBodyString("done")
+	})
+
+	ctx := newTestRequestCtx("POST", "http://localhost:8080/test", "test body!")
+	handler(ctx)
+	assert.Equal(t, fasthttp.StatusAccepted, ctx.Response.StatusCode())
+	assert.Equal(t, "done", string(ctx.Response.Body()))
+}
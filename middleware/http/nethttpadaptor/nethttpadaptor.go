@@ -1,31 +1,24 @@
 package nethttpadaptor
 
 import (
-	"io/ioutil"
+	"io"
 	"net"
 	"net/http"
 	"strconv"
+	"strings"
 
 	"github.com/dapr/dapr/pkg/logger"
 	"github.com/valyala/fasthttp"
 )
 
+const trailerHeader = "Trailer"
+
 // NewNetHTTPHandlerFunc wraps a fasthttp.RequestHandler in a http.HandlerFunc
 func NewNetHTTPHandlerFunc(logger logger.Logger, h fasthttp.RequestHandler) http.HandlerFunc { //nolint
 	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
 		c := fasthttp.RequestCtx{}
-		remoteIP := net.ParseIP(r.RemoteAddr)
-		remoteAddr := net.IPAddr{remoteIP, ""} //nolint
-		c.Init(&fasthttp.Request{}, &remoteAddr, nil)
+		c.Init(&fasthttp.Request{}, parseRemoteAddr(r.RemoteAddr), nil)
 
-		if r.Body != nil {
-			reqBody, err := ioutil.ReadAll(r.Body)
-			if err != nil {
-				logger.Errorf("error reading request body, %+v", err)
-				return
-			}
-			c.Request.SetBody(reqBody)
-		}
 		c.Request.SetRequestURI(r.URL.RequestURI())
 		c.Request.URI().SetScheme(r.URL.Scheme)
 		c.Request.SetHost(r.Host)
@@ -36,7 +29,6 @@ func NewNetHTTPHandlerFunc(logger logger.Logger, h fasthttp.RequestHandler) http
 		c.Request.Header.Set("Protomajor", major)
 		c.Request.Header.Set("Protominor", minor)
 		c.Request.Header.SetContentType(r.Header.Get("Content-Type"))
-		c.Request.Header.SetContentLength(int(r.ContentLength))
 		c.Request.Header.SetReferer(r.Referer())
 		c.Request.Header.SetUserAgent(r.UserAgent())
 		for _, cookie := range r.Cookies() {
@@ -48,6 +40,13 @@ func NewNetHTTPHandlerFunc(logger logger.Logger, h fasthttp.RequestHandler) http
 			}
 		}
 
+		// The body is handed over as a stream, so it is only read when the
+		// handler asks for it. Trailers become available once it is drained.
+		if r.Body != nil {
+			c.Request.SetBodyStream(&trailerReader{body: r.Body, r: r, header: &c.Request.Header}, int(r.ContentLength))
+		}
+		c.Request.Header.SetContentLength(int(r.ContentLength))
+
 		ctx := r.Context()
 		reqCtx, ok := ctx.(*fasthttp.RequestCtx)
 		if ok {
@@ -58,9 +57,96 @@ func NewNetHTTPHandlerFunc(logger logger.Logger, h fasthttp.RequestHandler) http
 
 		h(&c)
 
+		// Headers listed in the Trailer header are held back and sent after the body
+		trailers := declaredTrailers(c.Response.Header.Peek(trailerHeader))
+		trailerValues := http.Header{}
 		c.Response.Header.VisitAll(func(k []byte, v []byte) {
-			w.Header().Add(string(k), string(v))
+			key := string(k)
+			if _, isTrailer := trailers[http.CanonicalHeaderKey(key)]; isTrailer {
+				trailerValues.Add(key, string(v))
+				return
+			}
+			w.Header().Add(key, string(v))
 		})
-		c.Response.BodyWriteTo(w)
+		w.WriteHeader(c.Response.StatusCode())
+
+		var err error
+		if f, ok := w.(http.Flusher); ok && c.Response.IsBodyStream() {
+			err = c.Response.BodyWriteTo(&flushWriter{w: w, f: f})
+		} else {
+			err = c.Response.BodyWriteTo(w)
+		}
+		if err != nil {
+			logger.Errorf("error writing response body, %+v", err)
+		}
+
+		for k, v := range trailerValues {
+			for _, i := range v {
+				w.Header().Add(k, i)
+			}
+		}
 	})
 }
+
+// parseRemoteAddr converts the net/http remote address, which usually
+// includes the port, to a net.Addr usable by fasthttp
+func parseRemoteAddr(remoteAddr string) net.Addr {
+	host, port, err := net.SplitHostPort(remoteAddr)
+	if err != nil {
+		return &net.IPAddr{IP: net.ParseIP(remoteAddr)}
+	}
+	p, _ := strconv.Atoi(port)
+	ip := net.ParseIP(host)
+	return &net.TCPAddr{IP: ip, Port: p}
+}
+
+// declaredTrailers returns the canonical header names listed in a Trailer header
+func declaredTrailers(value []byte) map[string]struct{} {
+	if len(value) == 0 {
+		return nil
+	}
+	trailers := make(map[string]struct{})
+	for _, name := range strings.Split(string(value), ",") {
+		name = strings.TrimSpace(name)
+		if name != "" {
+			trailers[http.CanonicalHeaderKey(name)] = struct{}{}
+		}
+	}
+	return trailers
+}
+
+// trailerReader copies the request trailers into the fasthttp request
+// headers once the net/http body has been fully read
+type trailerReader struct {
+	body   io.ReadCloser
+	r      *http.Request
+	header *fasthttp.RequestHeader
+}
+
+func (t *trailerReader) Read(p []byte) (int, error) {
+	n, err := t.body.Read(p)
+	if err == io.EOF {
+		for k, v := range t.r.Trailer {
+			for _, i := range v {
+				t.header.Add(k, i)
+			}
+		}
+	}
+	return n, err
+}
+
+func (t *trailerReader) Close() error {
+	return t.body.Close()
+}
+
+// flushWriter flushes after every write so streamed bodies reach the client as they are produced
+type flushWriter struct {
+	w io.Writer
+	f http.Flusher
+}
+
+func (fw *flushWriter) Write(p []byte) (int, error) {
+	n, err := fw.w.Write(p)
+	fw.f.Flush()
+	return n, err
+}
@@ -287,6 +287,34 @@ func TestNewNetHTTPHandlerFuncRequests(t *testing.T) {
 				}
 			},
 		},
+		{
+			"RemoteAddr with port is handled",
+			func() *http.Request {
+				req := httptest.NewRequest("GET", "https://localhost:8080", nil)
+				req.RemoteAddr = "1.1.1.1:5555"
+				return req
+			},
+			func(t *testing.T) func(ctx *fasthttp.RequestCtx) {
+				return func(ctx *fasthttp.RequestCtx) {
+					assert.Equal(t, "1.1.1.1:5555", ctx.RemoteAddr().String())
+					assert.Equal(t, "1.1.1.1", ctx.RemoteIP().String())
+				}
+			},
+		},
+		{
+			"Trailers are handled",
+			func() *http.Request {
+				req := httptest.NewRequest("POST", "https://localhost:8080", strings.NewReader("test body!"))
+				req.Trailer = http.Header{"Testtrailer": []string{"trailerValue"}}
+				return req
+			},
+			func(t *testing.T) func(ctx *fasthttp.RequestCtx) {
+				return func(ctx *fasthttp.RequestCtx) {
+					assert.Equal(t, "test body!", string(ctx.Request.Body()))
+					assert.Equal(t, "trailerValue", string(ctx.Request.Header.Peek("Testtrailer")))
+				}
+			},
+		},
 		{
 			"nil body is handled",
 			func() *http.Request {
@@ -357,6 +385,54 @@ func TestNewNetHTTPHandlerFuncResponses(t *testing.T) {
 				assert.Equal(t, 200, res.StatusCode)
 			},
 		},
+		{
+			"Non-200 StatusCode is handled",
+			func() fasthttp.RequestHandler {
+				return func(ctx *fasthttp.RequestCtx) {
+					ctx.SetStatusCode(fasthttp.StatusTooManyRequests)
+				}
+			},
+			func() *http.Request {
+				return httptest.NewRequest("GET", "http://localhost:8080/test", nil)
+			},
+			func(t *testing.T, res *http.Response) {
+				assert.Equal(t, http.StatusTooManyRequests, res.StatusCode)
+			},
+		},
+		{
+			"Streamed body is handled",
+			func() fasthttp.RequestHandler {
+				return func(ctx *fasthttp.RequestCtx) {
+					ctx.SetBodyStream(strings.NewReader("streamed body!"), -1)
+				}
+			},
+			func() *http.Request {
+				return httptest.NewRequest("GET", "http://localhost:8080/test", nil)
+			},
+			func(t *testing.T, res *http.Response) {
+				body, _ := ioutil.ReadAll(res.Body)
+				assert.Equal(t, "streamed body!", string(body))
+			},
+		},
+		{
+			"Trailers are handled",
+			func() fasthttp.RequestHandler {
+				return func(ctx *fasthttp.RequestCtx) {
+					ctx.Response.Header.Set("Trailer", "Testtrailer")
+					ctx.Response.Header.Set("Testtrailer", "trailerValue")
+					ctx.Response.SetBodyString("test body!")
+				}
+			},
+			func() *http.Request {
+				return httptest.NewRequest("GET", "http://localhost:8080/test", nil)
+			},
+			func(t *testing.T, res *http.Response) {
+				body, _ := ioutil.ReadAll(res.Body)
+				assert.Equal(t, "test body!", string(body))
+				assert.Equal(t, "", res.Header.Get("Testtrailer"))
+				assert.Equal(t, "trailerValue", res.Trailer.Get("Testtrailer"))
+			},
+		},
 		{
 			"Body is handled",
 			func() fasthttp.RequestHandler {
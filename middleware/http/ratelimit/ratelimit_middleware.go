@@ -14,7 +14,6 @@ import (
 	"github.com/dapr/dapr/pkg/logger"
 	"github.com/didip/tollbooth"
 	"github.com/valyala/fasthttp"
-	"github.com/valyala/fasthttp/fasthttpadaptor"
 )
 
 // Metadata is the ratelimit middleware config
@@ -50,7 +49,7 @@ func (m *Middleware) GetHandler(metadata middleware.Metadata) (func(h fasthttp.R
 
 	return func(h fasthttp.RequestHandler) fasthttp.RequestHandler {
 		limitHandler := tollbooth.LimitFuncHandler(limiter, nethttpadaptor.NewNetHTTPHandlerFunc(m.logger, h))
-		wrappedHandler := fasthttpadaptor.NewFastHTTPHandlerFunc(limitHandler.ServeHTTP)
+		wrappedHandler := nethttpadaptor.NewFastHTTPHandler(m.logger, limitHandler)
 		return func(ctx *fasthttp.RequestCtx) {
 			wrappedHandler(ctx)
 		}
@@ -0,0 +1,260 @@
+// ------------------------------------------------------------
+// Copyright (c) Microsoft Corporation.
+// Licensed under the MIT License.
+// ------------------------------------------------------------
+
+package transform
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"regexp"
+	"strings"
+
+	"github.com/dapr/components-contrib/middleware"
+	"github.com/dapr/dapr/pkg/logger"
+	"github.com/valyala/fasthttp"
+)
+
+const (
+	requestRulesKey  = "requestRules"
+	responseRulesKey = "responseRules"
+
+	setHeaderAction    = "setHeader"
+	addHeaderAction    = "addHeader"
+	removeHeaderAction = "removeHeader"
+	renameHeaderAction = "renameHeader"
+	rewritePathAction  = "rewritePath"
+	redactBodyAction   = "redactBody"
+
+	// Defaults
+	defaultRedactMask = "***"
+)
+
+// rule is a single transformation as declared in the middleware metadata
+type rule struct {
+	Action      string   `json:"action"`
+	Header      string   `json:"header"`
+	Value       string   `json:"value"`
+	To          string   `json:"to"`
+	Pattern     string   `json:"pattern"`
+	Replacement string   `json:"replacement"`
+	Fields      []string `json:"fields"`
+	Mask        string   `json:"mask"`
+
+	pattern *regexp.Regexp
+}
+
+// Metadata is the transform middleware config
+type transformMiddlewareMetadata struct {
+	RequestRules  []*rule
+	ResponseRules []*rule
+}
+
+// NewTransformMiddleware returns a new request/response transformation middleware
+func NewTransformMiddleware(logger logger.Logger) *Middleware {
+	return &Middleware{logger: logger}
+}
+
+// Middleware is a request/response transformation middleware
+type Middleware struct {
+	logger logger.Logger
+}
+
+// GetHandler returns the HTTP handler provided by the middleware
+func (m *Middleware) GetHandler(metadata middleware.Metadata) (func(h fasthttp.RequestHandler) fasthttp.RequestHandler, error) {
+	meta, err := m.getNativeMetadata(metadata)
+	if err != nil {
+		return nil, err
+	}
+
+	return func(h fasthttp.RequestHandler) fasthttp.RequestHandler {
+		return func(ctx *fasthttp.RequestCtx) {
+			for _, r := range meta.RequestRules {
+				m.applyRequestRule(ctx, r)
+			}
+
+			h(ctx)
+
+			for _, r := range meta.ResponseRules {
+				m.applyResponseRule(ctx, r)
+			}
+		}
+	}, nil
+}
+
+func (m *Middleware) applyRequestRule(ctx *fasthttp.RequestCtx, r *rule) {
+	header := &ctx.Request.Header
+	switch r.Action {
+	case setHeaderAction:
+		header.Set(r.Header, r.Value)
+	case addHeaderAction:
+		header.Add(r.Header, r.Value)
+	case removeHeaderAction:
+		header.Del(r.Header)
+	case renameHeaderAction:
+		if v := header.Peek(r.Header); v != nil {
+			value := string(v)
+			header.Del(r.Header)
+			header.Set(r.To, value)
+		}
+	case rewritePathAction:
+		uri := ctx.Request.URI()
+		path := string(uri.Path())
+		if r.pattern.MatchString(path) {
+			uri.SetPath(r.pattern.ReplaceAllString(path, r.Replacement))
+			ctx.Request.SetRequestURIBytes(uri.RequestURI())
+		}
+	case redactBodyAction:
+		body, err := redactJSON(ctx.Request.Body(), r)
+		if err != nil {
+			m.logger.Debugf("transform middleware: skipping request body redaction: %s", err)
+			return
+		}
+		ctx.Request.SetBody(body)
+	}
+}
+
+func (m *Middleware) applyResponseRule(ctx *fasthttp.RequestCtx, r *rule) {
+	header := &ctx.Response.Header
+	switch r.Action {
+	case setHeaderAction:
+		header.Set(r.Header, r.Value)
+	case addHeaderAction:
+		header.Add(r.Header, r.Value)
+	case removeHeaderAction:
+		header.Del(r.Header)
+	case renameHeaderAction:
+		if v := header.Peek(r.Header); v != nil {
+			value := string(v)
+			header.Del(r.Header)
+			header.Set(r.To, value)
+		}
+	case redactBodyAction:
+		body, err := redactJSON(ctx.Response.Body(), r)
+		if err != nil {
+			m.logger.Debugf("transform middleware: skipping response body redaction: %s", err)
+			return
+		}
+		ctx.Response.SetBody(body)
+	}
+}
+
+// redactJSON replaces the values of the rule's dotted field paths with the
+// rule's mask. Arrays along a path are traversed element by element.
+// Numbers are decoded as json.Number so they are written back unchanged.
+func redactJSON(body []byte, r *rule) ([]byte, error) {
+	if len(body) == 0 {
+		return body, nil
+	}
+
+	dec := json.NewDecoder(bytes.NewReader(body))
+	dec.UseNumber()
+	var doc interface{}
+	if err := dec.Decode(&doc); err != nil {
+		return nil, err
+	}
+	if _, err := dec.Token(); err != io.EOF {
+		return nil, fmt.Errorf("unexpected data after JSON document")
+	}
+
+	for _, field := range r.Fields {
+		redactPath(doc, strings.Split(field, "."), r.Mask)
+	}
+
+	return json.Marshal(doc)
+}
+
+func redactPath(node interface{}, path []string, mask string) {
+	switch v := node.(type) {
+	case []interface{}:
+		for _, item := range v {
+			redactPath(item, path, mask)
+		}
+	case map[string]interface{}:
+		child, ok := v[path[0]]
+		if !ok {
+			return
+		}
+		if len(path) == 1 {
+			v[path[0]] = mask
+			return
+		}
+		redactPath(child, path[1:], mask)
+	}
+}
+
+func (m *Middleware) getNativeMetadata(metadata middleware.Metadata) (*transformMiddlewareMetadata, error) {
+	var middlewareMetadata transformMiddlewareMetadata
+
+	requestRules, err := parseRules(metadata.Properties, requestRulesKey, true)
+	if err != nil {
+		return nil, err
+	}
+	responseRules, err := parseRules(metadata.Properties, responseRulesKey, false)
+	if err != nil {
+		return nil, err
+	}
+	middlewareMetadata.RequestRules = requestRules
+	middlewareMetadata.ResponseRules = responseRules
+
+	return &middlewareMetadata, nil
+}
+
+func parseRules(properties map[string]string, key string, isRequest bool) ([]*rule, error) {
+	val, ok := properties[key]
+	if !ok || val == "" {
+		return nil, nil
+	}
+
+	var rules []*rule
+	if err := json.Unmarshal([]byte(val), &rules); err != nil {
+		return nil, fmt.Errorf("error parsing transform middleware property %s: %+v", key, err)
+	}
+
+	for i, r := range rules {
+		if err := r.validate(isRequest); err != nil {
+			return nil, fmt.Errorf("transform middleware property %s: rule %d: %s", key, i, err)
+		}
+	}
+
+	return rules, nil
+}
+
+func (r *rule) validate(isRequest bool) error {
+	switch r.Action {
+	case setHeaderAction, addHeaderAction, removeHeaderAction:
+		if r.Header == "" {
+			return fmt.Errorf("action %s requires a header", r.Action)
+		}
+	case renameHeaderAction:
+		if r.Header == "" || r.To == "" {
+			return fmt.Errorf("action %s requires a header and a target name", r.Action)
+		}
+	case rewritePathAction:
+		if !isRequest {
+			return fmt.Errorf("action %s is only supported on requests", r.Action)
+		}
+		if r.Pattern == "" {
+			return fmt.Errorf("action %s requires a pattern", r.Action)
+		}
+		pattern, err := regexp.Compile(r.Pattern)
+		if err != nil {
+			return fmt.Errorf("invalid pattern %s: %s", r.Pattern, err)
+		}
+		r.pattern = pattern
+	case redactBodyAction:
+		if len(r.Fields) == 0 {
+			return fmt.Errorf("action %s requires at least one field", r.Action)
+		}
+		if r.Mask == "" {
+			r.Mask = defaultRedactMask
+		}
+	default:
+		return fmt.Errorf("unknown action %q", r.Action)
+	}
+
+	return nil
+}
@@ -0,0 +1,130 @@
+// ------------------------------------------------------------
+// Copyright (c) Microsoft Corporation.
+// Licensed under the MIT License.
+// ------------------------------------------------------------
+
+package transform
+
+import (
+	"testing"
+
+	"github.com/dapr/components-contrib/middleware"
+	"github.com/dapr/dapr/pkg/logger"
+	"github.com/stretchr/testify/assert"
+	"github.com/valyala/fasthttp"
+)
+
+func getTestHandler(t *testing.T, properties map[string]string, next fasthttp.RequestHandler) fasthttp.RequestHandler {
+	m := NewTransformMiddleware(logger.NewLogger("test"))
+	handler, err := m.GetHandler(middleware.Metadata{Properties: properties})
+	assert.Nil(t, err)
+	return handler(next)
+}
+
+func TestRequestHeaderRules(t *testing.T) {
+	properties := map[string]string{
+		requestRulesKey: `[
+			{"action": "setHeader", "header": "X-Injected", "value": "yes"},
+			{"action": "removeHeader", "header": "X-Internal"},
+			{"action": "renameHeader", "header": "X-Old", "to": "X-New"}
+		]`,
+	}
+	handler := getTestHandler(t, properties, func(ctx *fasthttp.RequestCtx) {
+		assert.Equal(t, "yes", string(ctx.Request.Header.Peek("X-Injected")))
+		assert.Nil(t, ctx.Request.Header.Peek("X-Internal"))
+		assert.Nil(t, ctx.Request.Header.Peek("X-Old"))
+		assert.Equal(t, "value", string(ctx.Request.Header.Peek("X-New")))
+	})
+
+	var ctx fasthttp.RequestCtx
+	ctx.Request.SetRequestURI("http://localhost/test")
+	ctx.Request.Header.Set("X-Internal", "secret")
+	ctx.Request.Header.Set("X-Old", "value")
+	handler(&ctx)
+}
+
+func TestRewritePath(t *testing.T) {
+	properties := map[string]string{
+		requestRulesKey: `[{"action": "rewritePath", "pattern": "^/v1/orders/(\\d+)$", "replacement": "/orders/$1/details"}]`,
+	}
+	var path string
+	handler := getTestHandler(t, properties, func(ctx *fasthttp.RequestCtx) {
+		path = string(ctx.Path())
+	})
+
+	var ctx fasthttp.RequestCtx
+	ctx.Request.SetRequestURI("http://localhost/v1/orders/42")
+	handler(&ctx)
+	assert.Equal(t, "/orders/42/details", path)
+
+	ctx.Request.SetRequestURI("http://localhost/v2/orders/42")
+	handler(&ctx)
+	assert.Equal(t, "/v2/orders/42", path)
+}
+
+func TestRedactResponseBody(t *testing.T) {
+	properties := map[string]string{
+		responseRulesKey: `[
+			{"action": "redactBody", "fields": ["user.ssn", "cards.number"]},
+			{"action": "setHeader", "header": "X-Redacted", "value": "true"}
+		]`,
+	}
+	handler := getTestHandler(t, properties, func(ctx *fasthttp.RequestCtx) {
+		ctx.Response.SetBodyString(`{"user":{"name":"alice","ssn":"123"},"cards":[{"number":"4111"},{"number":"5500"}]}`)
+	})
+
+	var ctx fasthttp.RequestCtx
+	ctx.Request.SetRequestURI("http://localhost/test")
+	handler(&ctx)
+	assert.JSONEq(t, `{"user":{"name":"alice","ssn":"***"},"cards":[{"number":"***"},{"number":"***"}]}`, string(ctx.Response.Body()))
+	assert.Equal(t, "true", string(ctx.Response.Header.Peek("X-Redacted")))
+}
+
+func TestRedactKeepsLargeNumbers(t *testing.T) {
+	properties := map[string]string{
+		responseRulesKey: `[{"action": "redactBody", "fields": ["token"]}]`,
+	}
+	handler := getTestHandler(t, properties, func(ctx *fasthttp.RequestCtx) {
+		ctx.Response.SetBodyString(`{"id":1234567890123456789,"price":1.50,"token":"abc"}`)
+	})
+
+	var ctx fasthttp.RequestCtx
+	ctx.Request.SetRequestURI("http://localhost/test")
+	handler(&ctx)
+	assert.JSONEq(t, `{"id":1234567890123456789,"price":1.50,"token":"***"}`, string(ctx.Response.Body()))
+	assert.Contains(t, string(ctx.Response.Body()), "1234567890123456789")
+}
+
+func TestRedactSkipsNonJSONBody(t *testing.T) {
+	properties := map[string]string{
+		requestRulesKey: `[{"action": "redactBody", "fields": ["password"], "mask": "-"}]`,
+	}
+	var body string
+	handler := getTestHandler(t, properties, func(ctx *fasthttp.RequestCtx) {
+		body = string(ctx.Request.Body())
+	})
+
+	var ctx fasthttp.RequestCtx
+	ctx.Request.SetRequestURI("http://localhost/test")
+	ctx.Request.SetBodyString("plain text")
+	handler(&ctx)
+	assert.Equal(t, "plain text", body)
+}
+
+func TestInvalidRules(t *testing.T) {
+	m := NewTransformMiddleware(logger.NewLogger("test"))
+	tests := map[string]map[string]string{
+		"unknown action":          {requestRulesKey: `[{"action": "explode"}]`},
+		"missing header":          {requestRulesKey: `[{"action": "setHeader"}]`},
+		"bad pattern":             {requestRulesKey: `[{"action": "rewritePath", "pattern": "("}]`},
+		"rewrite on response":     {responseRulesKey: `[{"action": "rewritePath", "pattern": "a"}]`},
+		"redact without fields":   {responseRulesKey: `[{"action": "redactBody"}]`},
+		"malformed rules payload": {requestRulesKey: `{`},
+	}
+	for name, properties := range tests {
+		t.Run(name, func(t *testing.T) {
+			_, err := m.GetHandler(middleware.Metadata{Properties: properties})
+			assert.NotNil(t, err)
+		})
+	}
+}
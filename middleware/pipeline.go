@@ -0,0 +1,166 @@
+// ------------------------------------------------------------
+// Copyright (c) Microsoft Corporation.
+// Licensed under the MIT License.
+// ------------------------------------------------------------
+
+package middleware
+
+import (
+	"errors"
+	"fmt"
+	"path"
+	"strings"
+
+	"github.com/valyala/fasthttp"
+)
+
+// Conditions restrict a middleware in a pipeline to matching requests.
+// All non-empty conditions must match for the middleware to be applied.
+type Conditions struct {
+	PathPrefix string   `json:"pathPrefix"`
+	PathGlob   string   `json:"pathGlob"`
+	Methods    []string `json:"methods"`
+	Headers    []string `json:"headers"`
+}
+
+// Spec describes a single middleware in a pipeline
+type Spec struct {
+	Name       string
+	Middleware Middleware
+	Metadata   Metadata
+	Conditions Conditions
+}
+
+// Pipeline is an ordered list of middleware specs. The first spec is the
+// outermost handler, so it sees the request first and the response last.
+type Pipeline struct {
+	Specs []Spec
+}
+
+// BuildPipeline validates the specs and composes them into a single handler wrapper
+func BuildPipeline(specs []Spec) (func(h fasthttp.RequestHandler) fasthttp.RequestHandler, error) {
+	p := Pipeline{Specs: specs}
+	return p.Build()
+}
+
+// Build validates the pipeline and composes it into a single handler wrapper
+func (p *Pipeline) Build() (func(h fasthttp.RequestHandler) fasthttp.RequestHandler, error) {
+	wrappers := make([]func(h fasthttp.RequestHandler) fasthttp.RequestHandler, len(p.Specs))
+	for i, spec := range p.Specs {
+		name := spec.Name
+		if name == "" {
+			name = fmt.Sprintf("#%d", i)
+		}
+		if spec.Middleware == nil {
+			return nil, fmt.Errorf("middleware %s: no middleware implementation", name)
+		}
+
+		matcher, err := newConditionMatcher(spec.Conditions)
+		if err != nil {
+			return nil, fmt.Errorf("middleware %s: %s", name, err)
+		}
+
+		handler, err := spec.Middleware.GetHandler(spec.Metadata)
+		if err != nil {
+			return nil, fmt.Errorf("middleware %s: %s", name, err)
+		}
+		if handler == nil {
+			return nil, fmt.Errorf("middleware %s: no handler returned", name)
+		}
+
+		wrappers[i] = matcher.wrap(handler)
+	}
+
+	return func(h fasthttp.RequestHandler) fasthttp.RequestHandler {
+		for i := len(wrappers) - 1; i >= 0; i-- {
+			h = wrappers[i](h)
+		}
+		return h
+	}, nil
+}
+
+type conditionMatcher struct {
+	pathPrefix string
+	pathGlob   string
+	methods    map[string]bool
+	headers    []string
+}
+
+func newConditionMatcher(c Conditions) (*conditionMatcher, error) {
+	m := &conditionMatcher{
+		pathPrefix: c.PathPrefix,
+		pathGlob:   c.PathGlob,
+		headers:    c.Headers,
+	}
+
+	if c.PathGlob != "" {
+		if _, err := path.Match(c.PathGlob, "/"); err != nil {
+			return nil, fmt.Errorf("invalid path glob %s: %s", c.PathGlob, err)
+		}
+	}
+
+	if len(c.Methods) > 0 {
+		m.methods = make(map[string]bool, len(c.Methods))
+		for _, method := range c.Methods {
+			method = strings.ToUpper(strings.TrimSpace(method))
+			if method == "" {
+				return nil, errors.New("empty method in conditions")
+			}
+			m.methods[method] = true
+		}
+	}
+
+	for _, header := range c.Headers {
+		if strings.TrimSpace(header) == "" {
+			return nil, errors.New("empty header name in conditions")
+		}
+	}
+
+	return m, nil
+}
+
+func (m *conditionMatcher) isEmpty() bool {
+	return m.pathPrefix == "" && m.pathGlob == "" && len(m.methods) == 0 && len(m.headers) == 0
+}
+
+func (m *conditionMatcher) matches(ctx *fasthttp.RequestCtx) bool {
+	if len(m.methods) > 0 && !m.methods[string(ctx.Method())] {
+		return false
+	}
+
+	p := string(ctx.Path())
+	if m.pathPrefix != "" && !strings.HasPrefix(p, m.pathPrefix) {
+		return false
+	}
+	if m.pathGlob != "" {
+		if ok, _ := path.Match(m.pathGlob, p); !ok {
+			return false
+		}
+	}
+
+	for _, header := range m.headers {
+		if ctx.Request.Header.Peek(header) == nil {
+			return false
+		}
+	}
+
+	return true
+}
+
+// wrap applies the middleware handler only to requests that match the conditions
+func (m *conditionMatcher) wrap(handler func(h fasthttp.RequestHandler) fasthttp.RequestHandler) func(h fasthttp.RequestHandler) fasthttp.RequestHandler {
+	if m.isEmpty() {
+		return handler
+	}
+
+	return func(h fasthttp.RequestHandler) fasthttp.RequestHandler {
+		wrapped := handler(h)
+		return func(ctx *fasthttp.RequestCtx) {
+			if m.matches(ctx) {
+				wrapped(ctx)
+				return
+			}
+			h(ctx)
+		}
+	}
+}
@@ -0,0 +1,104 @@
+// ------------------------------------------------------------
+// Copyright (c) Microsoft Corporation.
+// Licensed under the MIT License.
+// ------------------------------------------------------------
+
+package middleware
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/valyala/fasthttp"
+)
+
+type recordingMiddleware struct {
+	name  string
+	trail *[]string
+	err   error
+}
+
+func (r *recordingMiddleware) GetHandler(metadata Metadata) (func(h fasthttp.RequestHandler) fasthttp.RequestHandler, error) {
+	if r.err != nil {
+		return nil, r.err
+	}
+	return func(h fasthttp.RequestHandler) fasthttp.RequestHandler {
+		return func(ctx *fasthttp.RequestCtx) {
+			*r.trail = append(*r.trail, r.name)
+			h(ctx)
+		}
+	}, nil
+}
+
+func runPipeline(t *testing.T, specs []Spec, method, uri string, headers map[string]string) []string {
+	var trail []string
+	for i := range specs {
+		if m, ok := specs[i].Middleware.(*recordingMiddleware); ok {
+			m.trail = &trail
+		}
+	}
+
+	wrapper, err := BuildPipeline(specs)
+	assert.Nil(t, err)
+	handler := wrapper(func(ctx *fasthttp.RequestCtx) {
+		trail = append(trail, "app")
+	})
+
+	var ctx fasthttp.RequestCtx
+	ctx.Request.Header.SetMethod(method)
+	ctx.Request.SetRequestURI(uri)
+	for k, v := range headers {
+		ctx.Request.Header.Set(k, v)
+	}
+	handler(&ctx)
+	return trail
+}
+
+func TestPipelineOrdering(t *testing.T) {
+	specs := []Spec{
+		{Name: "first", Middleware: &recordingMiddleware{name: "first"}},
+		{Name: "second", Middleware: &recordingMiddleware{name: "second"}},
+	}
+	trail := runPipeline(t, specs, "GET", "http://localhost/test", nil)
+	assert.Equal(t, []string{"first", "second", "app"}, trail)
+}
+
+func TestPipelineConditions(t *testing.T) {
+	specs := []Spec{
+		{Name: "prefix", Middleware: &recordingMiddleware{name: "prefix"}, Conditions: Conditions{PathPrefix: "/api/"}},
+		{Name: "glob", Middleware: &recordingMiddleware{name: "glob"}, Conditions: Conditions{PathGlob: "/api/*/orders"}},
+		{Name: "method", Middleware: &recordingMiddleware{name: "method"}, Conditions: Conditions{Methods: []string{"post"}}},
+		{Name: "header", Middleware: &recordingMiddleware{name: "header"}, Conditions: Conditions{Headers: []string{"X-Debug"}}},
+	}
+
+	t.Run("all match", func(t *testing.T) {
+		trail := runPipeline(t, specs, "POST", "http://localhost/api/v1/orders", map[string]string{"X-Debug": "1"})
+		assert.Equal(t, []string{"prefix", "glob", "method", "header", "app"}, trail)
+	})
+
+	t.Run("none match", func(t *testing.T) {
+		trail := runPipeline(t, specs, "GET", "http://localhost/health", nil)
+		assert.Equal(t, []string{"app"}, trail)
+	})
+
+	t.Run("prefix only", func(t *testing.T) {
+		trail := runPipeline(t, specs, "GET", "http://localhost/api/v1/users", nil)
+		assert.Equal(t, []string{"prefix", "app"}, trail)
+	})
+}
+
+func TestPipelineValidation(t *testing.T) {
+	tests := map[string][]Spec{
+		"nil middleware":   {{Name: "nil"}},
+		"bad glob":         {{Name: "glob", Middleware: &recordingMiddleware{}, Conditions: Conditions{PathGlob: "[a-"}}},
+		"empty method":     {{Name: "method", Middleware: &recordingMiddleware{}, Conditions: Conditions{Methods: []string{" "}}}},
+		"get handler fail": {{Name: "fail", Middleware: &recordingMiddleware{err: errors.New("bad metadata")}}},
+	}
+	for name, specs := range tests {
+		t.Run(name, func(t *testing.T) {
+			_, err := BuildPipeline(specs)
+			assert.NotNil(t, err)
+		})
+	}
+}
@@ -0,0 +1,152 @@
+// ------------------------------------------------------------
+// Copyright (c) Microsoft Corporation.
+// Licensed under the MIT License.
+// ------------------------------------------------------------
+
+package servicebus
+
+import (
+	"context"
+	"fmt"
+	"sync"
+
+	azservicebus "github.com/Azure/azure-service-bus-go"
+)
+
+// namespace is the subset of a Service Bus namespace used by the pub sub.
+// Topics and subscriptions are addressed by name so fakes can implement it.
+type namespace interface {
+	GetTopic(ctx context.Context, topic string) (*azservicebus.TopicEntity, error)
+	PutTopic(ctx context.Context, topic string) (*azservicebus.TopicEntity, error)
+	GetSubscription(ctx context.Context, topic string, subscription string) (*azservicebus.SubscriptionEntity, error)
+	PutSubscription(ctx context.Context, topic string, subscription string, opts ...azservicebus.SubscriptionManagementOption) (*azservicebus.SubscriptionEntity, error)
+	Send(ctx context.Context, topic string, msg *azservicebus.Message) error
+	OpenSubscription(topic string, subscription string) error
+	ReceiveOne(ctx context.Context, topic string, subscription string, handler azservicebus.Handler) error
+	RenewLocks(ctx context.Context, topic string, subscription string, msgs ...*azservicebus.Message) error
+	CloseSubscription(ctx context.Context, topic string, subscription string) error
+	Complete(ctx context.Context, msg *azservicebus.Message) error
+	Abandon(ctx context.Context, msg *azservicebus.Message) error
+}
+
+// serviceBusNamespace implements namespace with a Service Bus namespace
+type serviceBusNamespace struct {
+	namespace           *azservicebus.Namespace
+	topicManager        *azservicebus.TopicManager
+	subscriptionOptions []azservicebus.SubscriptionOption
+
+	lock          sync.Mutex
+	subscriptions map[string]*azservicebus.Subscription
+}
+
+func newServiceBusNamespace(m metadata) (namespace, error) {
+	ns, err := azservicebus.NewNamespace(azservicebus.NamespaceWithConnectionString(m.ConnectionString))
+	if err != nil {
+		return nil, err
+	}
+
+	var opts []azservicebus.SubscriptionOption
+	if m.PrefetchCount != nil {
+		opts = append(opts, azservicebus.SubscriptionWithPrefetchCount(uint32(*m.PrefetchCount)))
+	}
+
+	return &serviceBusNamespace{
+		namespace:           ns,
+		topicManager:        ns.NewTopicManager(),
+		subscriptionOptions: opts,
+		subscriptions:       map[string]*azservicebus.Subscription{},
+	}, nil
+}
+
+func (n *serviceBusNamespace) GetTopic(ctx context.Context, topic string) (*azservicebus.TopicEntity, error) {
+	return n.topicManager.Get(ctx, topic)
+}
+
+func (n *serviceBusNamespace) PutTopic(ctx context.Context, topic string) (*azservicebus.TopicEntity, error) {
+	return n.topicManager.Put(ctx, topic)
+}
+
+func (n *serviceBusNamespace) GetSubscription(ctx context.Context, topic string, subscription string) (*azservicebus.SubscriptionEntity, error) {
+	mgr, err := n.namespace.NewSubscriptionManager(topic)
+	if err != nil {
+		return nil, err
+	}
+	return mgr.Get(ctx, subscription)
+}
+
+func (n *serviceBusNamespace) PutSubscription(ctx context.Context, topic string, subscription string, opts ...azservicebus.SubscriptionManagementOption) (*azservicebus.SubscriptionEntity, error) {
+	mgr, err := n.namespace.NewSubscriptionManager(topic)
+	if err != nil {
+		return nil, err
+	}
+	return mgr.Put(ctx, subscription, opts...)
+}
+
+func (n *serviceBusNamespace) Send(ctx context.Context, topic string, msg *azservicebus.Message) error {
+	sender, err := n.namespace.NewTopic(topic)
+	if err != nil {
+		return err
+	}
+	defer sender.Close(context.Background())
+
+	return sender.Send(ctx, msg)
+}
+
+func (n *serviceBusNamespace) OpenSubscription(topic string, subscription string) error {
+	_, err := n.subscription(topic, subscription)
+	return err
+}
+
+func (n *serviceBusNamespace) ReceiveOne(ctx context.Context, topic string, subscription string, handler azservicebus.Handler) error {
+	sub, err := n.subscription(topic, subscription)
+	if err != nil {
+		return err
+	}
+	return sub.ReceiveOne(ctx, handler)
+}
+
+func (n *serviceBusNamespace) RenewLocks(ctx context.Context, topic string, subscription string, msgs ...*azservicebus.Message) error {
+	sub, err := n.subscription(topic, subscription)
+	if err != nil {
+		return err
+	}
+	return sub.RenewLocks(ctx, msgs...)
+}
+
+func (n *serviceBusNamespace) CloseSubscription(ctx context.Context, topic string, subscription string) error {
+	sub, err := n.subscription(topic, subscription)
+	if err != nil {
+		return err
+	}
+	return sub.Close(ctx)
+}
+
+func (n *serviceBusNamespace) Complete(ctx context.Context, msg *azservicebus.Message) error {
+	return msg.Complete(ctx)
+}
+
+func (n *serviceBusNamespace) Abandon(ctx context.Context, msg *azservicebus.Message) error {
+	return msg.Abandon(ctx)
+}
+
+// subscription returns the receiver of a subscription, closed receivers are reopened on use
+func (n *serviceBusNamespace) subscription(topic string, subscription string) (*azservicebus.Subscription, error) {
+	n.lock.Lock()
+	defer n.lock.Unlock()
+
+	key := topic + "/" + subscription
+	if sub, ok := n.subscriptions[key]; ok {
+		return sub, nil
+	}
+
+	t, err := n.namespace.NewTopic(topic)
+	if err != nil {
+		return nil, fmt.Errorf("%s could not instantiate topic %s, %s", errorMessagePrefix, topic, err)
+	}
+	sub, err := t.NewSubscription(subscription, n.subscriptionOptions...)
+	if err != nil {
+		return nil, fmt.Errorf("%s could not instantiate subscription %s for topic %s", errorMessagePrefix, subscription, topic)
+	}
+	n.subscriptions[key] = sub
+	return sub, nil
+}
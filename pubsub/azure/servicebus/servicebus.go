@@ -13,6 +13,7 @@ import (
 	"time"
 
 	azservicebus "github.com/Azure/azure-service-bus-go"
+	"github.com/dapr/components-contrib/componenterrors"
 	"github.com/dapr/components-contrib/pubsub"
 	"github.com/dapr/dapr/pkg/logger"
 )
@@ -50,11 +51,13 @@ type handler = struct{}
 
 type azureServiceBus struct {
 	metadata       metadata
-	namespace      *azservicebus.Namespace
-	topicManager   *azservicebus.TopicManager
+	client         namespace
 	activeMessages map[string]*azservicebus.Message
 	mu             sync.RWMutex
 	logger         logger.Logger
+
+	// newNamespace creates the client in Init, tests replace it with a fake
+	newNamespace func(m metadata) (namespace, error)
 }
 
 // NewAzureServiceBus returns a new Azure ServiceBus pub-sub implementation
@@ -62,6 +65,7 @@ func NewAzureServiceBus(logger logger.Logger) pubsub.PubSub {
 	return &azureServiceBus{
 		activeMessages: make(map[string]*azservicebus.Message),
 		logger:         logger,
+		newNamespace:   newServiceBusNamespace,
 	}
 }
 
@@ -197,13 +201,8 @@ func (a *azureServiceBus) Init(metadata pubsub.Metadata) error {
 	}
 
 	a.metadata = m
-	a.namespace, err = azservicebus.NewNamespace(azservicebus.NamespaceWithConnectionString(a.metadata.ConnectionString))
-	if err != nil {
-		return err
-	}
-
-	a.topicManager = a.namespace.NewTopicManager()
-	return nil
+	a.client, err = a.newNamespace(m)
+	return err
 }
 
 func (a *azureServiceBus) Publish(req *pubsub.PublishRequest) error {
@@ -214,20 +213,10 @@ func (a *azureServiceBus) Publish(req *pubsub.PublishRequest) error {
 		}
 	}
 
-	sender, err := a.namespace.NewTopic(req.Topic)
-	if err != nil {
-		return err
-	}
-	defer sender.Close(context.Background())
-
 	ctx, cancel := context.WithTimeout(context.Background(), time.Second*time.Duration(a.metadata.TimeoutInSec))
 	defer cancel()
 
-	err = sender.Send(ctx, azservicebus.NewMessage(req.Data))
-	if err != nil {
-		return err
-	}
-	return nil
+	return componenterrors.FromAzure(a.client.Send(ctx, req.Topic, azservicebus.NewMessage(req.Data)))
 }
 
 func (a *azureServiceBus) Subscribe(req pubsub.SubscribeRequest, daprHandler func(msg *pubsub.NewMessage) error) error {
@@ -238,22 +227,15 @@ func (a *azureServiceBus) Subscribe(req pubsub.SubscribeRequest, daprHandler fun
 			return err
 		}
 	}
-	topic, err := a.namespace.NewTopic(req.Topic)
-	if err != nil {
-		return fmt.Errorf("%s could not instantiate topic %s, %s", errorMessagePrefix, req.Topic, err)
-	}
 
-	var opts []azservicebus.SubscriptionOption
-	if a.metadata.PrefetchCount != nil {
-		opts = append(opts, azservicebus.SubscriptionWithPrefetchCount(uint32(*a.metadata.PrefetchCount)))
-	}
-	sub, err := topic.NewSubscription(subID, opts...)
+	// The receiver is opened before returning so subscriptions that can't be opened fail
+	err := a.client.OpenSubscription(req.Topic, subID)
 	if err != nil {
-		return fmt.Errorf("%s could not instantiate subscription %s for topic %s", errorMessagePrefix, subID, req.Topic)
+		return err
 	}
 
 	asbHandler := azservicebus.HandlerFunc(a.getHandlerFunc(req.Topic, daprHandler))
-	go a.handleSubscriptionMessages(req.Topic, sub, asbHandler)
+	go a.handleSubscriptionMessages(req.Topic, subID, asbHandler)
 
 	return nil
 }
@@ -275,7 +257,7 @@ func (a *azureServiceBus) getHandlerFunc(topic string, daprHandler func(msg *pub
 	}
 }
 
-func (a *azureServiceBus) handleSubscriptionMessages(topic string, sub *azservicebus.Subscription, asbHandler azservicebus.HandlerFunc) {
+func (a *azureServiceBus) handleSubscriptionMessages(topic string, subID string, asbHandler azservicebus.HandlerFunc) {
 	// Limiting the number of concurrent handlers will throttle
 	// how many messages are processed concurrently.
 	limitConcurrentHandlers := a.metadata.MaxConcurrentHandlers != nil
@@ -329,7 +311,7 @@ func (a *azureServiceBus) handleSubscriptionMessages(topic string, sub *azservic
 		for {
 			time.Sleep(time.Second * time.Duration(a.metadata.LockRenewalInSec))
 
-			if len(a.activeMessages) == 0 {
+			if a.activeMessageCount() == 0 {
 				a.logger.Debugf("No active messages to renew lock for")
 				continue
 			}
@@ -341,7 +323,7 @@ func (a *azureServiceBus) handleSubscriptionMessages(topic string, sub *azservic
 			}
 			a.mu.RUnlock()
 			a.logger.Debugf("Renewing %d active message lock(s)", len(msgs))
-			err := sub.RenewLocks(context.Background(), msgs...)
+			err := a.client.RenewLocks(context.Background(), topic, subID, msgs...)
 			if err != nil {
 				a.logger.Errorf("%s error renewing active message lock(s) for topic %s, ", errorMessagePrefix, topic, err)
 			}
@@ -351,12 +333,12 @@ func (a *azureServiceBus) handleSubscriptionMessages(topic string, sub *azservic
 	// Receiver loop
 	for {
 		// If we have too many active messages don't receive any more for a while.
-		if len(a.activeMessages) < a.metadata.MaxActiveMessages {
+		if a.activeMessageCount() < a.metadata.MaxActiveMessages {
 			a.logger.Debugf("Waiting to receive message from topic")
-			if err := sub.ReceiveOne(context.Background(), asyncAsbHandler); err != nil {
+			if err := a.client.ReceiveOne(context.Background(), topic, subID, asyncAsbHandler); err != nil {
 				a.logger.Errorf("%s error receiving from topic %s, %s", errorMessagePrefix, topic, err)
 				// Must close to reset sub's receiver
-				if err := sub.Close(context.Background()); err != nil {
+				if err := a.client.CloseSubscription(context.Background(), topic, subID); err != nil {
 					a.logger.Errorf("%s error closing subscription to topic %s, %s", errorMessagePrefix, topic, err)
 					return
 				}
@@ -377,14 +359,14 @@ func (a *azureServiceBus) abandonMessage(ctx context.Context, m *azservicebus.Me
 	a.removeActiveMessage(m.ID)
 
 	a.logger.Debugf("Abandoning message %s", m.ID)
-	return m.Abandon(ctx)
+	return a.client.Abandon(ctx, m)
 }
 
 func (a *azureServiceBus) completeMessage(ctx context.Context, m *azservicebus.Message) error {
 	a.removeActiveMessage(m.ID)
 
 	a.logger.Debugf("Completing message %s", m.ID)
-	return m.Complete(ctx)
+	return a.client.Complete(ctx, m)
 }
 
 func (a *azureServiceBus) addActiveMessage(m *azservicebus.Message) {
@@ -401,6 +383,13 @@ func (a *azureServiceBus) removeActiveMessage(messageID string) {
 	a.mu.Unlock()
 }
 
+func (a *azureServiceBus) activeMessageCount() int {
+	a.mu.RLock()
+	defer a.mu.RUnlock()
+
+	return len(a.activeMessages)
+}
+
 func (a *azureServiceBus) ensureTopic(topic string) error {
 	entity, err := a.getTopicEntity(topic)
 	if err != nil {
@@ -422,18 +411,13 @@ func (a *azureServiceBus) ensureSubscription(name string, topic string) error {
 		return err
 	}
 
-	subManager, err := a.namespace.NewSubscriptionManager(topic)
-	if err != nil {
-		return err
-	}
-
-	entity, err := a.getSubscriptionEntity(subManager, topic, name)
+	entity, err := a.getSubscriptionEntity(topic, name)
 	if err != nil {
 		return err
 	}
 
 	if entity == nil {
-		err = a.createSubscriptionEntity(subManager, topic, name)
+		err = a.createSubscriptionEntity(topic, name)
 		if err != nil {
 			return err
 		}
@@ -445,12 +429,12 @@ func (a *azureServiceBus) getTopicEntity(topic string) (*azservicebus.TopicEntit
 	ctx, cancel := context.WithTimeout(context.Background(), time.Second*time.Duration(a.metadata.TimeoutInSec))
 	defer cancel()
 
-	if a.topicManager == nil {
+	if a.client == nil {
 		return nil, fmt.Errorf("%s init() has not been called", errorMessagePrefix)
 	}
-	topicEntity, err := a.topicManager.Get(ctx, topic)
+	topicEntity, err := a.client.GetTopic(ctx, topic)
 	if err != nil && !azservicebus.IsErrNotFound(err) {
-		return nil, fmt.Errorf("%s could not get topic %s, %s", errorMessagePrefix, topic, err)
+		return nil, fmt.Errorf("%s could not get topic %s, %w", errorMessagePrefix, topic, componenterrors.FromAzure(err))
 	}
 	return topicEntity, nil
 }
@@ -458,24 +442,24 @@ func (a *azureServiceBus) getTopicEntity(topic string) (*azservicebus.TopicEntit
 func (a *azureServiceBus) createTopicEntity(topic string) error {
 	ctx, cancel := context.WithTimeout(context.Background(), time.Second*time.Duration(a.metadata.TimeoutInSec))
 	defer cancel()
-	_, err := a.topicManager.Put(ctx, topic)
+	_, err := a.client.PutTopic(ctx, topic)
 	if err != nil {
-		return fmt.Errorf("%s could not put topic %s, %s", errorMessagePrefix, topic, err)
+		return fmt.Errorf("%s could not put topic %s, %w", errorMessagePrefix, topic, componenterrors.FromAzure(err))
 	}
 	return nil
 }
 
-func (a *azureServiceBus) getSubscriptionEntity(mgr *azservicebus.SubscriptionManager, topic, subscription string) (*azservicebus.SubscriptionEntity, error) {
+func (a *azureServiceBus) getSubscriptionEntity(topic, subscription string) (*azservicebus.SubscriptionEntity, error) {
 	ctx, cancel := context.WithTimeout(context.Background(), time.Second*time.Duration(a.metadata.TimeoutInSec))
 	defer cancel()
-	entity, err := mgr.Get(ctx, subscription)
+	entity, err := a.client.GetSubscription(ctx, topic, subscription)
 	if err != nil && !azservicebus.IsErrNotFound(err) {
-		return nil, fmt.Errorf("%s could not get subscription %s, %s", errorMessagePrefix, subscription, err)
+		return nil, fmt.Errorf("%s could not get subscription %s, %w", errorMessagePrefix, subscription, componenterrors.FromAzure(err))
 	}
 	return entity, nil
 }
 
-func (a *azureServiceBus) createSubscriptionEntity(mgr *azservicebus.SubscriptionManager, topic, subscription string) error {
+func (a *azureServiceBus) createSubscriptionEntity(topic, subscription string) error {
 	ctx, cancel := context.WithTimeout(context.Background(), time.Second*time.Duration(a.metadata.TimeoutInSec))
 	defer cancel()
 
@@ -484,9 +468,9 @@ func (a *azureServiceBus) createSubscriptionEntity(mgr *azservicebus.Subscriptio
 		return err
 	}
 
-	_, err = mgr.Put(ctx, subscription, opts...)
+	_, err = a.client.PutSubscription(ctx, topic, subscription, opts...)
 	if err != nil {
-		return fmt.Errorf("%s could not put subscription %s, %s", errorMessagePrefix, subscription, err)
+		return fmt.Errorf("%s could not put subscription %s, %w", errorMessagePrefix, subscription, componenterrors.FromAzure(err))
 	}
 	return nil
 }
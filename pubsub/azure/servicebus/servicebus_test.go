@@ -6,11 +6,17 @@
 package servicebus
 
 import (
+	"errors"
+	"fmt"
 	"testing"
 
 	"github.com/stretchr/testify/assert"
+	"pack.ag/amqp"
 
+	"github.com/dapr/components-contrib/componenterrors"
 	"github.com/dapr/components-contrib/pubsub"
+	fakes "github.com/dapr/components-contrib/testing"
+	"github.com/dapr/dapr/pkg/logger"
 )
 
 const (
@@ -498,3 +504,90 @@ func TestParseServiceBusMetadata(t *testing.T) {
 func assertValidErrorMessage(t *testing.T, err error) {
 	assert.Contains(t, err.Error(), errorMessagePrefix)
 }
+
+func newTestServiceBus(t *testing.T, client *fakes.ServiceBusNamespace, properties map[string]string) *azureServiceBus {
+	a := NewAzureServiceBus(logger.NewLogger("test")).(*azureServiceBus)
+	a.newNamespace = func(m metadata) (namespace, error) {
+		return client, nil
+	}
+	assert.NoError(t, a.Init(pubsub.Metadata{Properties: properties}))
+	return a
+}
+
+func TestEntityManagement(t *testing.T) {
+	client := fakes.NewServiceBusNamespace()
+	properties := getFakeProperties()
+	properties[disableEntityManagement] = "false"
+	a := newTestServiceBus(t, client, properties)
+
+	err := a.Subscribe(pubsub.SubscribeRequest{Topic: "orders"}, func(msg *pubsub.NewMessage) error {
+		return nil
+	})
+	assert.NoError(t, err)
+
+	description, _, ok := client.Subscription("orders", "fakeConId")
+	assert.True(t, ok)
+	assert.Equal(t, int32(10), *description.MaxDeliveryCount)
+	assert.Equal(t, "PT120S", *description.LockDuration)
+	assert.Equal(t, "PT2400S", *description.DefaultMessageTimeToLive)
+	assert.Equal(t, "PT240S", *description.AutoDeleteOnIdle)
+
+	t.Run("management errors are returned", func(t *testing.T) {
+		client.FailNext("PutTopic", errors.New("unauthorized"))
+		err := a.Publish(&pubsub.PublishRequest{Topic: "invoices", Data: []byte("data")})
+		assert.Error(t, err)
+		assertValidErrorMessage(t, err)
+	})
+
+	t.Run("subscriptions that can't be opened fail", func(t *testing.T) {
+		client.FailNext("OpenSubscription", errors.New("could not instantiate subscription"))
+		err := a.Subscribe(pubsub.SubscribeRequest{Topic: "payments"}, func(msg *pubsub.NewMessage) error {
+			return nil
+		})
+		assert.EqualError(t, err, "could not instantiate subscription")
+	})
+
+	t.Run("management errors are classified", func(t *testing.T) {
+		client.FailNext("PutTopic", fmt.Errorf("error code: 401, Details: unauthorized"))
+		err := a.Publish(&pubsub.PublishRequest{Topic: "invoices", Data: []byte("data")})
+		assert.True(t, componenterrors.IsUnauthorized(err))
+		assertValidErrorMessage(t, err)
+	})
+
+	t.Run("send errors are classified", func(t *testing.T) {
+		client.FailNext("Send", &amqp.Error{Condition: "com.microsoft:server-busy", Description: "server busy"})
+		err := a.Publish(&pubsub.PublishRequest{Topic: "orders", Data: []byte("data")})
+		assert.True(t, componenterrors.IsThrottled(err))
+	})
+}
+
+func TestPublishAndSubscribe(t *testing.T) {
+	client := fakes.NewServiceBusNamespace()
+	client.AddSubscription("orders", "fakeConId")
+	properties := getFakeProperties()
+	properties[lockRenewalInSec] = "0"
+	a := newTestServiceBus(t, client, properties)
+
+	received := make(chan string, 10)
+	err := a.Subscribe(pubsub.SubscribeRequest{Topic: "orders"}, func(msg *pubsub.NewMessage) error {
+		received <- string(msg.Data)
+		if string(msg.Data) == "failed" {
+			return errors.New("handler error")
+		}
+		return nil
+	})
+	assert.NoError(t, err)
+
+	assert.NoError(t, a.Publish(&pubsub.PublishRequest{Topic: "orders", Data: []byte("processed")}))
+	assert.Equal(t, "processed", <-received)
+	assert.NoError(t, a.Publish(&pubsub.PublishRequest{Topic: "orders", Data: []byte("failed")}))
+	// Messages the handler fails on are abandoned and delivered again
+	assert.Equal(t, "failed", <-received)
+	assert.Equal(t, "failed", <-received)
+
+	assert.Equal(t, "processed", string(client.Completed()[0].Data))
+	assert.Equal(t, "failed", string(client.Abandoned()[0].Data))
+
+	// Publishing to topics that don't exist fails without entity management
+	assert.Error(t, a.Publish(&pubsub.PublishRequest{Topic: "invoices", Data: []byte("data")}))
+}
@@ -0,0 +1,333 @@
+// ------------------------------------------------------------
+// Copyright (c) Microsoft Corporation.
+// Licensed under the MIT License.
+// ------------------------------------------------------------
+
+// Package conformance checks that a pubsub component behaves the way Dapr expects.
+// Component tests call Run with a Config describing the component and the
+// guarantees it makes.
+package conformance
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/dapr/components-contrib/exporters"
+	"github.com/dapr/components-contrib/exporters/recorder"
+	"github.com/dapr/components-contrib/pubsub"
+	"github.com/dapr/dapr/pkg/logger"
+	"github.com/stretchr/testify/assert"
+	"go.opencensus.io/trace"
+	"go.opencensus.io/trace/propagation"
+)
+
+const (
+	// Defaults
+	defaultMessageCount   = 10
+	defaultTimeout        = time.Second * 10
+	defaultSubscribeDelay = time.Millisecond * 100
+)
+
+// Config describes a pubsub component under test
+type Config struct {
+	// NewPubSub returns a new instance of the component, Run initializes it
+	NewPubSub func() pubsub.PubSub
+	// Metadata initializes every instance. Instances share its consumerID, so they
+	// are expected to compete for messages.
+	Metadata map[string]string
+	// Redelivery is true when a message is delivered again after its handler fails
+	Redelivery bool
+	// Ordered is true when a subscriber receives the messages of a publisher in order
+	Ordered bool
+	// MessageCount is the number of messages published by each test
+	MessageCount int
+	// Timeout bounds the wait for messages to be delivered
+	Timeout time.Duration
+	// SubscribeDelay is the time a subscription takes to become active
+	SubscribeDelay time.Duration
+}
+
+// Run runs the conformance tests against the component described by config
+func Run(t *testing.T, config Config) {
+	if config.MessageCount == 0 {
+		config.MessageCount = defaultMessageCount
+	}
+	if config.Timeout == 0 {
+		config.Timeout = defaultTimeout
+	}
+	if config.SubscribeDelay == 0 {
+		config.SubscribeDelay = defaultSubscribeDelay
+	}
+
+	t.Run("publish and subscribe", func(t *testing.T) {
+		testPublishSubscribe(t, config)
+	})
+	t.Run("ordering", func(t *testing.T) {
+		if !config.Ordered {
+			t.Skip("the component doesn't guarantee ordering")
+		}
+		testOrdering(t, config)
+	})
+	t.Run("competing consumers", func(t *testing.T) {
+		testCompetingConsumers(t, config)
+	})
+	t.Run("redelivery", func(t *testing.T) {
+		if !config.Redelivery {
+			t.Skip("the component doesn't redeliver failed messages")
+		}
+		testRedelivery(t, config)
+	})
+	t.Run("multiple topics", func(t *testing.T) {
+		testMultipleTopics(t, config)
+	})
+	t.Run("envelope", func(t *testing.T) {
+		testEnvelope(t, config)
+	})
+	t.Run("trace propagation", func(t *testing.T) {
+		testTracePropagation(t, config)
+	})
+}
+
+// collector records the messages delivered to a subscriber
+type collector struct {
+	lock     sync.Mutex
+	messages []*pubsub.NewMessage
+	// fail is called before a message is recorded, the message fails when it returns an error
+	fail func(msg *pubsub.NewMessage) error
+}
+
+func (c *collector) handler(msg *pubsub.NewMessage) error {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+
+	if c.fail != nil {
+		if err := c.fail(msg); err != nil {
+			return err
+		}
+	}
+	c.messages = append(c.messages, msg)
+	return nil
+}
+
+func (c *collector) data() []string {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+
+	result := make([]string, 0, len(c.messages))
+	for _, msg := range c.messages {
+		result = append(result, string(msg.Data))
+	}
+	return result
+}
+
+func (c *collector) topics() map[string]bool {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+
+	result := map[string]bool{}
+	for _, msg := range c.messages {
+		result[msg.Topic] = true
+	}
+	return result
+}
+
+// waitForCount waits until the collectors together recorded count messages
+func waitForCount(timeout time.Duration, count int, collectors ...*collector) bool {
+	deadline := time.Now().Add(timeout)
+	for time.Now().Before(deadline) {
+		total := 0
+		for _, c := range collectors {
+			total += len(c.data())
+		}
+		if total >= count {
+			return true
+		}
+		time.Sleep(time.Millisecond * 10)
+	}
+	return false
+}
+
+func newInstance(t *testing.T, config Config) pubsub.PubSub {
+	ps := config.NewPubSub()
+	props := map[string]string{}
+	for k, v := range config.Metadata {
+		props[k] = v
+	}
+	if err := ps.Init(pubsub.Metadata{Properties: props}); err != nil {
+		t.Fatalf("init failed: %s", err)
+	}
+	// The PubSub interface has no Close, instances that hold connections implement io.Closer
+	if closer, ok := ps.(io.Closer); ok {
+		t.Cleanup(func() {
+			if err := closer.Close(); err != nil {
+				t.Errorf("close failed: %s", err)
+			}
+		})
+	}
+	return ps
+}
+
+func subscribe(t *testing.T, config Config, ps pubsub.PubSub, topic string, c *collector) {
+	if err := ps.Subscribe(pubsub.SubscribeRequest{Topic: topic}, c.handler); err != nil {
+		t.Fatalf("subscribe to %s failed: %s", topic, err)
+	}
+	time.Sleep(config.SubscribeDelay)
+}
+
+// publish publishes count numbered messages to topic and returns their data
+func publish(t *testing.T, ps pubsub.PubSub, topic string, count int) []string {
+	sent := make([]string, 0, count)
+	for i := 0; i < count; i++ {
+		data := fmt.Sprintf("%s-%d", topic, i)
+		assert.Nil(t, ps.Publish(&pubsub.PublishRequest{Topic: topic, Data: []byte(data)}))
+		sent = append(sent, data)
+	}
+	return sent
+}
+
+// topicName returns a topic unique to a test run so earlier runs can't interfere
+func topicName(name string) string {
+	return fmt.Sprintf("conformance-%s-%d", name, time.Now().UnixNano())
+}
+
+func testPublishSubscribe(t *testing.T, config Config) {
+	ps := newInstance(t, config)
+	topic := topicName("pubsub")
+	received := &collector{}
+	subscribe(t, config, ps, topic, received)
+
+	sent := publish(t, ps, topic, config.MessageCount)
+	assert.True(t, waitForCount(config.Timeout, len(sent), received), "timed out waiting for messages")
+	assert.ElementsMatch(t, sent, received.data())
+	assert.Equal(t, map[string]bool{topic: true}, received.topics())
+}
+
+func testOrdering(t *testing.T, config Config) {
+	ps := newInstance(t, config)
+	topic := topicName("ordering")
+	received := &collector{}
+	subscribe(t, config, ps, topic, received)
+
+	sent := publish(t, ps, topic, config.MessageCount)
+	assert.True(t, waitForCount(config.Timeout, len(sent), received), "timed out waiting for messages")
+	assert.Equal(t, sent, received.data())
+}
+
+func testCompetingConsumers(t *testing.T, config Config) {
+	topic := topicName("competing")
+	first, second := &collector{}, &collector{}
+	subscribe(t, config, newInstance(t, config), topic, first)
+	subscribe(t, config, newInstance(t, config), topic, second)
+
+	sent := publish(t, newInstance(t, config), topic, config.MessageCount)
+	assert.True(t, waitForCount(config.Timeout, len(sent), first, second), "timed out waiting for messages")
+
+	// Give duplicate deliveries time to arrive, every message must be received once
+	time.Sleep(config.SubscribeDelay)
+	assert.ElementsMatch(t, sent, append(first.data(), second.data()...))
+}
+
+func testRedelivery(t *testing.T, config Config) {
+	ps := newInstance(t, config)
+	topic := topicName("redelivery")
+
+	attempts := map[string]int{}
+	received := &collector{fail: func(msg *pubsub.NewMessage) error {
+		attempts[string(msg.Data)]++
+		if attempts[string(msg.Data)] == 1 {
+			return errors.New("conformance: failing first delivery")
+		}
+		return nil
+	}}
+	subscribe(t, config, ps, topic, received)
+
+	sent := publish(t, ps, topic, config.MessageCount)
+	assert.True(t, waitForCount(config.Timeout, len(sent), received), "timed out waiting for redelivered messages")
+	assert.ElementsMatch(t, sent, received.data())
+}
+
+func testMultipleTopics(t *testing.T, config Config) {
+	ps := newInstance(t, config)
+	topicA, topicB := topicName("topic-a"), topicName("topic-b")
+	receivedA, receivedB := &collector{}, &collector{}
+	subscribe(t, config, ps, topicA, receivedA)
+	subscribe(t, config, ps, topicB, receivedB)
+
+	sentA := publish(t, ps, topicA, config.MessageCount)
+	sentB := publish(t, ps, topicB, config.MessageCount)
+	assert.True(t, waitForCount(config.Timeout, len(sentA), receivedA), "timed out waiting for messages on %s", topicA)
+	assert.True(t, waitForCount(config.Timeout, len(sentB), receivedB), "timed out waiting for messages on %s", topicB)
+
+	assert.ElementsMatch(t, sentA, receivedA.data())
+	assert.Equal(t, map[string]bool{topicA: true}, receivedA.topics())
+	assert.ElementsMatch(t, sentB, receivedB.data())
+	assert.Equal(t, map[string]bool{topicB: true}, receivedB.topics())
+}
+
+func testEnvelope(t *testing.T, config Config) {
+	ps := newInstance(t, config)
+	topic := topicName("envelope")
+	received := &collector{}
+	subscribe(t, config, ps, topic, received)
+
+	envelope := pubsub.NewCloudEventsEnvelope("conformance-1", "conformance", "", topic, []byte(`{"order":1,"items":["a","b"]}`))
+	data, err := json.Marshal(envelope)
+	assert.Nil(t, err)
+	assert.Nil(t, ps.Publish(&pubsub.PublishRequest{Topic: topic, Data: data}))
+
+	if !waitForCount(config.Timeout, 1, received) {
+		t.Fatal("timed out waiting for the envelope")
+	}
+	var roundTrip pubsub.CloudEventsEnvelope
+	assert.Nil(t, json.Unmarshal([]byte(received.data()[0]), &roundTrip))
+	assert.Equal(t, *envelope, roundTrip)
+}
+
+// testTracePropagation publishes the span context of a publish span as the message
+// data and checks, using the recorder exporter, that the span of the handler
+// continues the publisher's trace
+func testTracePropagation(t *testing.T, config Config) {
+	spans := recorder.NewRecorderExporter(logger.NewLogger("conformance"))
+	if err := spans.Init("conformance", "", exporters.Metadata{Properties: map[string]string{}}); err != nil {
+		t.Fatalf("recorder init failed: %s", err)
+	}
+	defer spans.Shutdown(context.Background()) //nolint:errcheck
+
+	ps := newInstance(t, config)
+	topic := topicName("tracing")
+	received := &collector{fail: func(msg *pubsub.NewMessage) error {
+		parent, ok := propagation.FromBinary(msg.Data)
+		if !ok {
+			return errors.New("conformance: message data isn't a span context")
+		}
+		_, span := trace.StartSpanWithRemoteParent(context.Background(), "conformance/handle", parent, trace.WithSampler(trace.AlwaysSample()))
+		span.AddAttributes(trace.StringAttribute("topic", msg.Topic))
+		span.End()
+		return nil
+	}}
+	subscribe(t, config, ps, topic, received)
+
+	_, span := trace.StartSpan(context.Background(), "conformance/publish", trace.WithSampler(trace.AlwaysSample()))
+	span.AddAttributes(trace.StringAttribute("topic", topic))
+	assert.Nil(t, ps.Publish(&pubsub.PublishRequest{Topic: topic, Data: propagation.Binary(span.SpanContext())}))
+	span.End()
+
+	if !waitForCount(config.Timeout, 1, received) {
+		t.Fatal("timed out waiting for the traced message")
+	}
+	publishSpans := spans.SpansByName("conformance/publish")
+	if !assert.Len(t, publishSpans, 1) {
+		return
+	}
+	handleSpans := spans.Children(publishSpans[0])
+	if assert.Len(t, handleSpans, 1) {
+		assert.Equal(t, "conformance/handle", handleSpans[0].Name)
+		assert.Equal(t, topic, handleSpans[0].Attributes["topic"])
+	}
+}
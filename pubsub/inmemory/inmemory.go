@@ -0,0 +1,194 @@
+// ------------------------------------------------------------
+// Copyright (c) Microsoft Corporation.
+// Licensed under the MIT License.
+// ------------------------------------------------------------
+
+package inmemory
+
+import (
+	"fmt"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/dapr/components-contrib/pubsub"
+	"github.com/dapr/dapr/pkg/logger"
+	"github.com/google/uuid"
+)
+
+const (
+	consumerID         = "consumerID"
+	redeliveryInterval = "redeliveryInterval"
+	maxRedeliveries    = "maxRedeliveries"
+
+	// Defaults
+	defaultRedeliveryInterval = time.Millisecond * 500
+	defaultMaxRedeliveries    = 3
+)
+
+type metadata struct {
+	consumerID         string
+	redeliveryInterval time.Duration
+	maxRedeliveries    int
+}
+
+// Bus routes messages between the in-memory pubsub instances connected to it.
+// Each message is delivered to one subscriber of every consumer ID subscribed to its topic.
+type Bus struct {
+	lock sync.Mutex
+	// topics holds the subscribers of each topic grouped by consumer ID
+	topics map[string]map[string]*consumerGroup
+}
+
+type consumerGroup struct {
+	subscribers []*subscriber
+	next        int
+}
+
+type subscriber struct {
+	owner    *inMemoryPubSub
+	handler  func(msg *pubsub.NewMessage) error
+	metadata metadata
+	logger   logger.Logger
+}
+
+type inMemoryPubSub struct {
+	bus      *Bus
+	metadata metadata
+
+	logger logger.Logger
+}
+
+// NewBus returns a bus for in-memory pubsub instances to share.
+func NewBus() *Bus {
+	return &Bus{topics: map[string]map[string]*consumerGroup{}}
+}
+
+// NewPubSub returns an in-memory pub-sub instance connected to the bus.
+func (b *Bus) NewPubSub(logger logger.Logger) pubsub.PubSub {
+	return &inMemoryPubSub{bus: b, logger: logger}
+}
+
+// NewInMemoryPubSub returns a new in-memory pub-sub implementation with its own bus
+func NewInMemoryPubSub(logger logger.Logger) pubsub.PubSub {
+	return NewBus().NewPubSub(logger)
+}
+
+func parseInMemoryMetadata(meta pubsub.Metadata) (metadata, error) {
+	m := metadata{
+		consumerID:         uuid.New().String(),
+		redeliveryInterval: defaultRedeliveryInterval,
+		maxRedeliveries:    defaultMaxRedeliveries,
+	}
+
+	if val, ok := meta.Properties[consumerID]; ok && val != "" {
+		m.consumerID = val
+	}
+
+	if val, ok := meta.Properties[redeliveryInterval]; ok && val != "" {
+		d, err := time.ParseDuration(val)
+		if err != nil || d < 0 {
+			return m, fmt.Errorf("in-memory pubsub error: invalid redeliveryInterval %s", val)
+		}
+		m.redeliveryInterval = d
+	}
+
+	if val, ok := meta.Properties[maxRedeliveries]; ok && val != "" {
+		n, err := strconv.Atoi(val)
+		if err != nil || n < 0 {
+			return m, fmt.Errorf("in-memory pubsub error: invalid maxRedeliveries %s", val)
+		}
+		m.maxRedeliveries = n
+	}
+
+	return m, nil
+}
+
+func (p *inMemoryPubSub) Init(metadata pubsub.Metadata) error {
+	m, err := parseInMemoryMetadata(metadata)
+	if err != nil {
+		return err
+	}
+	p.metadata = m
+	return nil
+}
+
+func (p *inMemoryPubSub) Publish(req *pubsub.PublishRequest) error {
+	for _, s := range p.bus.subscribersOf(req.Topic) {
+		data := make([]byte, len(req.Data))
+		copy(data, req.Data)
+		go s.deliver(req.Topic, data)
+	}
+	return nil
+}
+
+func (p *inMemoryPubSub) Subscribe(req pubsub.SubscribeRequest, handler func(msg *pubsub.NewMessage) error) error {
+	p.bus.lock.Lock()
+	defer p.bus.lock.Unlock()
+
+	groups, ok := p.bus.topics[req.Topic]
+	if !ok {
+		groups = map[string]*consumerGroup{}
+		p.bus.topics[req.Topic] = groups
+	}
+	group, ok := groups[p.metadata.consumerID]
+	if !ok {
+		group = &consumerGroup{}
+		groups[p.metadata.consumerID] = group
+	}
+	group.subscribers = append(group.subscribers, &subscriber{owner: p, handler: handler, metadata: p.metadata, logger: p.logger})
+	return nil
+}
+
+// Close removes the subscriptions of the instance from the bus.
+func (p *inMemoryPubSub) Close() error {
+	p.bus.lock.Lock()
+	defer p.bus.lock.Unlock()
+
+	for topic, groups := range p.bus.topics {
+		for id, group := range groups {
+			subscribers := group.subscribers[:0]
+			for _, s := range group.subscribers {
+				if s.owner != p {
+					subscribers = append(subscribers, s)
+				}
+			}
+			group.subscribers = subscribers
+			if len(subscribers) == 0 {
+				delete(groups, id)
+			}
+		}
+		if len(groups) == 0 {
+			delete(p.bus.topics, topic)
+		}
+	}
+	return nil
+}
+
+// subscribersOf picks the next subscriber of every consumer group of a topic
+func (b *Bus) subscribersOf(topic string) []*subscriber {
+	b.lock.Lock()
+	defer b.lock.Unlock()
+
+	var result []*subscriber
+	for _, group := range b.topics[topic] {
+		result = append(result, group.subscribers[group.next%len(group.subscribers)])
+		group.next++
+	}
+	return result
+}
+
+// deliver calls the handler until it succeeds or the message runs out of redeliveries
+func (s *subscriber) deliver(topic string, data []byte) {
+	for attempt := 0; ; attempt++ {
+		err := s.handler(&pubsub.NewMessage{Topic: topic, Data: data})
+		if err == nil {
+			return
+		}
+		if attempt >= s.metadata.maxRedeliveries {
+			s.logger.Warnf("in-memory pubsub: dropping message on topic %s after %d attempts: %s", topic, attempt+1, err)
+			return
+		}
+		time.Sleep(s.metadata.redeliveryInterval)
+	}
+}
@@ -0,0 +1,78 @@
+// ------------------------------------------------------------
+// Copyright (c) Microsoft Corporation.
+// Licensed under the MIT License.
+// ------------------------------------------------------------
+
+package inmemory
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/dapr/components-contrib/pubsub"
+	"github.com/dapr/components-contrib/pubsub/conformance"
+	"github.com/dapr/dapr/pkg/logger"
+)
+
+func TestParseInMemoryMetadata(t *testing.T) {
+	t.Run("metadata is correct", func(t *testing.T) {
+		m, err := parseInMemoryMetadata(pubsub.Metadata{Properties: map[string]string{
+			consumerID:         "myapp",
+			redeliveryInterval: "10ms",
+			maxRedeliveries:    "5",
+		}})
+		assert.Nil(t, err)
+		assert.Equal(t, "myapp", m.consumerID)
+		assert.Equal(t, time.Millisecond*10, m.redeliveryInterval)
+		assert.Equal(t, 5, m.maxRedeliveries)
+	})
+
+	t.Run("defaults", func(t *testing.T) {
+		m, err := parseInMemoryMetadata(pubsub.Metadata{})
+		assert.Nil(t, err)
+		assert.NotEmpty(t, m.consumerID)
+		assert.Equal(t, defaultRedeliveryInterval, m.redeliveryInterval)
+		assert.Equal(t, defaultMaxRedeliveries, m.maxRedeliveries)
+	})
+
+	t.Run("invalid values", func(t *testing.T) {
+		_, err := parseInMemoryMetadata(pubsub.Metadata{Properties: map[string]string{redeliveryInterval: "soon"}})
+		assert.NotNil(t, err)
+		_, err = parseInMemoryMetadata(pubsub.Metadata{Properties: map[string]string{maxRedeliveries: "-1"}})
+		assert.NotNil(t, err)
+	})
+}
+
+func TestFanOutToConsumerIDs(t *testing.T) {
+	bus := NewBus()
+	received := make(chan string, 2)
+	for _, id := range []string{"app1", "app2"} {
+		id := id
+		ps := bus.NewPubSub(logger.NewLogger("test"))
+		assert.Nil(t, ps.Init(pubsub.Metadata{Properties: map[string]string{consumerID: id}}))
+		assert.Nil(t, ps.Subscribe(pubsub.SubscribeRequest{Topic: "orders"}, func(msg *pubsub.NewMessage) error {
+			received <- id
+			return nil
+		}))
+	}
+
+	publisher := bus.NewPubSub(logger.NewLogger("test"))
+	assert.Nil(t, publisher.Init(pubsub.Metadata{}))
+	assert.Nil(t, publisher.Publish(&pubsub.PublishRequest{Topic: "orders", Data: []byte("1")}))
+
+	assert.ElementsMatch(t, []string{"app1", "app2"}, []string{<-received, <-received})
+}
+
+func TestConformance(t *testing.T) {
+	bus := NewBus()
+	conformance.Run(t, conformance.Config{
+		NewPubSub: func() pubsub.PubSub { return bus.NewPubSub(logger.NewLogger("test")) },
+		Metadata: map[string]string{
+			consumerID:         "conformance",
+			redeliveryInterval: "10ms",
+		},
+		Redelivery: true,
+	})
+}
@@ -14,12 +14,18 @@ import (
 	"sync"
 
 	"github.com/Shopify/sarama"
+	"github.com/dapr/components-contrib/componenterrors"
 	"github.com/dapr/components-contrib/pubsub"
 	"github.com/dapr/dapr/pkg/logger"
 )
 
 // Kafka allows reading/writing to a Kafka consumer group
 type Kafka struct {
+	// lock is held for reading while publishing, and for writing while Reconfigure replaces the producer
+	lock sync.RWMutex
+	// subscriptionLock serializes changes to the consumer group
+	subscriptionLock sync.Mutex
+
 	producer      sarama.SyncProducer
 	consumerGroup string
 	brokers       []string
@@ -28,9 +34,9 @@ type Kafka struct {
 	saslUsername  string
 	saslPassword  string
 	cg            sarama.ConsumerGroup
-	topics        map[string]bool
+	topics        map[string]func(msg *pubsub.NewMessage) error
 	cancel        context.CancelFunc
-	consumer      consumer
+	consumer      *consumer
 	config        *sarama.Config
 }
 
@@ -43,15 +49,17 @@ type kafkaMetadata struct {
 }
 
 type consumer struct {
-	ready    chan bool
-	callback func(msg *pubsub.NewMessage) error
+	ready chan bool
+	// handlers holds the handler of each subscribed topic, messages are dispatched on their topic
+	handlers map[string]func(msg *pubsub.NewMessage) error
 	once     sync.Once
 }
 
 func (consumer *consumer) ConsumeClaim(session sarama.ConsumerGroupSession, claim sarama.ConsumerGroupClaim) error {
+	handler := consumer.handlers[claim.Topic()]
 	for message := range claim.Messages() {
-		if consumer.callback != nil {
-			err := consumer.callback(&pubsub.NewMessage{
+		if handler != nil {
+			err := handler(&pubsub.NewMessage{
 				Topic: claim.Topic(),
 				Data:  message.Value,
 			})
@@ -88,38 +96,79 @@ func (k *Kafka) Init(metadata pubsub.Metadata) error {
 		return err
 	}
 
-	p, err := k.getSyncProducer(meta)
+	p, err := getSyncProducer(meta)
 	if err != nil {
 		return err
 	}
 
-	k.brokers = meta.Brokers
 	k.producer = p
-	k.consumerGroup = meta.ConsumerID
+	k.setMetadata(meta)
 
-	if meta.AuthRequired {
-		k.saslUsername = meta.SaslUsername
-		k.saslPassword = meta.SaslPassword
+	k.topics = make(map[string]func(msg *pubsub.NewMessage) error)
+
+	k.logger.Debug("Kafka message bus initialization complete")
+	return nil
+}
+
+// Reconfigure connects with new metadata, such as rotated SASL credentials, and replaces the producer
+// and the consumer group. Publishes in flight complete with the previous producer before it is closed,
+// and the subscribed topics are consumed again by a consumer group using the new metadata.
+func (k *Kafka) Reconfigure(metadata pubsub.Metadata) error {
+	meta, err := k.getKafkaMetadata(metadata)
+	if err != nil {
+		return err
 	}
 
-	config := sarama.NewConfig()
-	config.Version = sarama.V2_0_0_0
+	p, err := getSyncProducer(meta)
+	if err != nil {
+		return err
+	}
 
-	if k.authRequired {
-		updateAuthInfo(config, k.saslUsername, k.saslPassword)
+	k.subscriptionLock.Lock()
+	defer k.subscriptionLock.Unlock()
+
+	var cg sarama.ConsumerGroup
+	if k.cg != nil {
+		cg, err = sarama.NewConsumerGroup(meta.Brokers, meta.ConsumerID, getConsumerConfig(meta))
+		if err != nil {
+			p.Close()
+			return err
+		}
 	}
 
-	k.config = config
+	k.lock.Lock()
+	old := k.producer
+	k.producer = p
+	k.setMetadata(meta)
+	k.lock.Unlock()
 
-	k.topics = make(map[string]bool)
+	if err := old.Close(); err != nil {
+		k.logger.Warnf("Error closing previous producer: %v", err)
+	}
 
-	k.logger.Debug("Kafka message bus initialization complete")
-	return nil
+	if cg == nil {
+		return nil
+	}
+	return k.consume(cg)
+}
+
+// setMetadata stores the settings used to create consumer groups
+func (k *Kafka) setMetadata(meta *kafkaMetadata) {
+	k.brokers = meta.Brokers
+	k.consumerGroup = meta.ConsumerID
+	k.authRequired = meta.AuthRequired
+	k.saslUsername = meta.SaslUsername
+	k.saslPassword = meta.SaslPassword
+	k.config = getConsumerConfig(meta)
 }
 
 // Publish message to Kafka cluster
 func (k *Kafka) Publish(req *pubsub.PublishRequest) error {
 	k.logger.Debugf("Publishing topic %v with data: %v", req.Topic, req.Data)
+
+	k.lock.RLock()
+	defer k.lock.RUnlock()
+
 	partition, offset, err := k.producer.SendMessage(&sarama.ProducerMessage{
 		Topic: req.Topic,
 		Value: sarama.ByteEncoder(req.Data),
@@ -128,16 +177,44 @@ func (k *Kafka) Publish(req *pubsub.PublishRequest) error {
 	k.logger.Debugf("Partition: %v, offset: %v", partition, offset)
 
 	if err != nil {
-		return err
+		return classifyKafkaError(err)
 	}
 
 	return nil
 }
 
-func (k *Kafka) addTopic(newTopic string) []string {
-	// Add topic to our map of topics
-	k.topics[newTopic] = true
+// classifyKafkaError maps the errors of the Kafka producer to componenterrors classes
+func classifyKafkaError(err error) error {
+	var kerr sarama.KError
+	if errors.As(err, &kerr) {
+		switch kerr {
+		case sarama.ErrNotLeaderForPartition, sarama.ErrLeaderNotAvailable, sarama.ErrRequestTimedOut,
+			sarama.ErrNetworkException, sarama.ErrNotEnoughReplicas, sarama.ErrNotEnoughReplicasAfterAppend,
+			sarama.ErrUnknownTopicOrPartition, sarama.ErrBrokerNotAvailable, sarama.ErrReplicaNotAvailable,
+			sarama.ErrOffsetsLoadInProgress, sarama.ErrConsumerCoordinatorNotAvailable, sarama.ErrNotCoordinatorForConsumer,
+			sarama.ErrRebalanceInProgress, sarama.ErrNotController, sarama.ErrKafkaStorageError:
+			return componenterrors.Retriable(err)
+		case sarama.ErrTopicAuthorizationFailed, sarama.ErrClusterAuthorizationFailed, sarama.ErrGroupAuthorizationFailed,
+			sarama.ErrSASLAuthenticationFailed:
+			return componenterrors.Unauthorized(err)
+		case sarama.ErrMessageSizeTooLarge, sarama.ErrInvalidMessage, sarama.ErrInvalidMessageSize, sarama.ErrInvalidTopic,
+			sarama.ErrMessageSetSizeTooLarge, sarama.ErrInvalidRequiredAcks, sarama.ErrUnsupportedVersion:
+			return componenterrors.Permanent(err)
+		default:
+			// Other broker errors are left unclassified rather than guessed permanent
+			return componenterrors.Classify(err)
+		}
+	}
+
+	switch {
+	case errors.Is(err, sarama.ErrOutOfBrokers), errors.Is(err, sarama.ErrNotConnected), errors.Is(err, sarama.ErrShuttingDown):
+		return componenterrors.Retriable(err)
+	default:
+		return componenterrors.Classify(err)
+	}
+}
 
+func (k *Kafka) topicList() []string {
 	topics := make([]string, len(k.topics))
 
 	i := 0
@@ -149,6 +226,16 @@ func (k *Kafka) addTopic(newTopic string) []string {
 	return topics
 }
 
+// topicHandlers returns a copy of the handlers of the subscribed topics for a new consumer group
+func (k *Kafka) topicHandlers() map[string]func(msg *pubsub.NewMessage) error {
+	handlers := make(map[string]func(msg *pubsub.NewMessage) error, len(k.topics))
+	for topic, handler := range k.topics {
+		handlers[topic] = handler
+	}
+
+	return handlers
+}
+
 // Close down consumer group resources, refresh once
 func (k *Kafka) closeSubscripionResources() {
 	if k.cg != nil {
@@ -161,7 +248,6 @@ func (k *Kafka) closeSubscripionResources() {
 
 		k.consumer.once.Do(func() {
 			close(k.consumer.ready)
-			k.consumer.once = sync.Once{}
 		})
 	}
 }
@@ -169,10 +255,8 @@ func (k *Kafka) closeSubscripionResources() {
 // Subscribe to topic in the Kafka cluster
 // This call cannot block like its sibling in bindings/kafka because of where this is invoked in runtime.go
 func (k *Kafka) Subscribe(req pubsub.SubscribeRequest, handler func(msg *pubsub.NewMessage) error) error {
-	topics := k.addTopic(req.Topic)
-
-	// Close resources and reset synchronization primitives
-	k.closeSubscripionResources()
+	k.subscriptionLock.Lock()
+	defer k.subscriptionLock.Unlock()
 
 	cg, err := sarama.NewConsumerGroup(k.brokers, k.consumerGroup, k.config)
 
@@ -180,21 +264,33 @@ func (k *Kafka) Subscribe(req pubsub.SubscribeRequest, handler func(msg *pubsub.
 		return err
 	}
 
+	k.topics[req.Topic] = handler
+
+	return k.consume(cg)
+}
+
+// consume replaces the current consumer group with cg and waits until it consumes the subscribed topics
+func (k *Kafka) consume(cg sarama.ConsumerGroup) error {
+	// Close resources and reset synchronization primitives
+	k.closeSubscripionResources()
+
 	k.cg = cg
 
 	ctx, cancel := context.WithCancel(context.Background())
 	k.cancel = cancel
 
+	topics := k.topicList()
 	ready := make(chan bool)
-	k.consumer = consumer{
+	groupConsumer := &consumer{
 		ready:    ready,
-		callback: handler,
+		handlers: k.topicHandlers(),
 	}
+	k.consumer = groupConsumer
 
 	go func() {
 		defer func() {
 			k.logger.Debugf("Closing ConsumerGroup for topics: %v", topics)
-			err := k.cg.Close()
+			err := cg.Close()
 
 			if err != nil {
 				k.logger.Errorf("Error closing consumer group: %v", err)
@@ -205,7 +301,7 @@ func (k *Kafka) Subscribe(req pubsub.SubscribeRequest, handler func(msg *pubsub.
 
 		for {
 			// Consume the requested topic
-			innerError := k.cg.Consume(ctx, topics, &(k.consumer))
+			innerError := cg.Consume(ctx, topics, groupConsumer)
 			if innerError != nil {
 				k.logger.Errorf("Error consuming %v: %v", topics, innerError)
 			}
@@ -269,14 +365,14 @@ func (k *Kafka) getKafkaMetadata(metadata pubsub.Metadata) (*kafkaMetadata, erro
 	return &meta, nil
 }
 
-func (k *Kafka) getSyncProducer(meta *kafkaMetadata) (sarama.SyncProducer, error) {
+func getSyncProducer(meta *kafkaMetadata) (sarama.SyncProducer, error) {
 	config := sarama.NewConfig()
 	config.Producer.RequiredAcks = sarama.WaitForAll
 	config.Producer.Retry.Max = 5
 	config.Producer.Return.Successes = true
 
-	if k.authRequired {
-		updateAuthInfo(config, k.saslUsername, k.saslPassword)
+	if meta.AuthRequired {
+		updateAuthInfo(config, meta.SaslUsername, meta.SaslPassword)
 	}
 
 	producer, err := sarama.NewSyncProducer(meta.Brokers, config)
@@ -286,6 +382,17 @@ func (k *Kafka) getSyncProducer(meta *kafkaMetadata) (sarama.SyncProducer, error
 	return producer, nil
 }
 
+func getConsumerConfig(meta *kafkaMetadata) *sarama.Config {
+	config := sarama.NewConfig()
+	config.Version = sarama.V2_0_0_0
+
+	if meta.AuthRequired {
+		updateAuthInfo(config, meta.SaslUsername, meta.SaslPassword)
+	}
+
+	return config
+}
+
 func updateAuthInfo(config *sarama.Config, saslUsername, saslPassword string) {
 	config.Net.SASL.Enable = true
 	config.Net.SASL.User = saslUsername
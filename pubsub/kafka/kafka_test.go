@@ -8,8 +8,10 @@ package kafka
 import (
 	"testing"
 
+	"github.com/Shopify/sarama"
 	"github.com/dapr/dapr/pkg/logger"
 
+	"github.com/dapr/components-contrib/componenterrors"
 	"github.com/dapr/components-contrib/pubsub"
 	"github.com/stretchr/testify/assert"
 )
@@ -96,3 +98,95 @@ func TestInvalidAuthRequiredFlag(t *testing.T) {
 
 	assert.Equal(t, "kafka error: invalid value for 'authRequired' attribute", err.Error())
 }
+
+func newMockBroker(t *testing.T, id int32) *sarama.MockBroker {
+	broker := sarama.NewMockBroker(t, id)
+	broker.SetHandlerByMap(map[string]sarama.MockResponse{
+		"MetadataRequest": sarama.NewMockMetadataResponse(t).
+			SetBroker(broker.Addr(), broker.BrokerID()).
+			SetLeader("topic", 0, broker.BrokerID()),
+		"ProduceRequest": sarama.NewMockProduceResponse(t),
+	})
+	return broker
+}
+
+func TestReconfigure(t *testing.T) {
+	broker := newMockBroker(t, 1)
+	defer broker.Close()
+
+	k := getKafkaPubsub()
+	err := k.Init(pubsub.Metadata{Properties: map[string]string{"consumerID": "a", "brokers": broker.Addr(), "authRequired": "false"}})
+	assert.Nil(t, err)
+
+	t.Run("invalid metadata keeps the current producer", func(t *testing.T) {
+		err := k.Reconfigure(pubsub.Metadata{Properties: map[string]string{"consumerID": "b", "authRequired": "false"}})
+		assert.NotNil(t, err)
+		assert.Equal(t, "a", k.consumerGroup)
+		assert.Nil(t, k.Publish(&pubsub.PublishRequest{Topic: "topic", Data: []byte("data")}))
+	})
+
+	t.Run("new brokers replace the producer", func(t *testing.T) {
+		next := newMockBroker(t, 2)
+		defer next.Close()
+
+		err := pubsub.Reconfigure(k, pubsub.Metadata{Properties: map[string]string{"consumerID": "b", "brokers": next.Addr(), "authRequired": "false"}})
+		assert.Nil(t, err)
+		assert.Equal(t, []string{next.Addr()}, k.brokers)
+		assert.Equal(t, "b", k.consumerGroup)
+
+		assert.Nil(t, k.Publish(&pubsub.PublishRequest{Topic: "topic", Data: []byte("data")}))
+		produced := false
+		for _, rr := range next.History() {
+			if _, ok := rr.Request.(*sarama.ProduceRequest); ok {
+				produced = true
+			}
+		}
+		assert.True(t, produced)
+	})
+}
+
+type fakeClaim struct {
+	sarama.ConsumerGroupClaim
+	topic    string
+	messages chan *sarama.ConsumerMessage
+}
+
+func (c *fakeClaim) Topic() string                            { return c.topic }
+func (c *fakeClaim) Messages() <-chan *sarama.ConsumerMessage { return c.messages }
+
+type fakeSession struct {
+	sarama.ConsumerGroupSession
+	marked int
+}
+
+func (s *fakeSession) MarkMessage(msg *sarama.ConsumerMessage, metadata string) { s.marked++ }
+
+func TestConsumeClaimDispatchesOnTopic(t *testing.T) {
+	received := map[string]string{}
+	c := &consumer{handlers: map[string]func(msg *pubsub.NewMessage) error{
+		"a": func(msg *pubsub.NewMessage) error { received["a"] = msg.Topic; return nil },
+		"b": func(msg *pubsub.NewMessage) error { received["b"] = msg.Topic; return nil },
+	}}
+
+	session := &fakeSession{}
+	for _, topic := range []string{"a", "b"} {
+		claim := &fakeClaim{topic: topic, messages: make(chan *sarama.ConsumerMessage, 1)}
+		claim.messages <- &sarama.ConsumerMessage{Topic: topic, Value: []byte("data")}
+		close(claim.messages)
+		assert.Nil(t, c.ConsumeClaim(session, claim))
+	}
+
+	assert.Equal(t, map[string]string{"a": "a", "b": "b"}, received)
+	assert.Equal(t, 2, session.marked)
+}
+
+func TestClassifyKafkaError(t *testing.T) {
+	for _, err := range []error{sarama.ErrBrokerNotAvailable, sarama.ErrOffsetsLoadInProgress, sarama.ErrRebalanceInProgress, sarama.ErrOutOfBrokers} {
+		assert.True(t, componenterrors.IsRetriable(classifyKafkaError(err)), err.Error())
+	}
+	assert.Equal(t, componenterrors.ClassUnauthorized, componenterrors.ClassOf(classifyKafkaError(sarama.ErrSASLAuthenticationFailed)))
+	assert.Equal(t, componenterrors.ClassPermanent, componenterrors.ClassOf(classifyKafkaError(sarama.ErrMessageSizeTooLarge)))
+
+	// Unlisted broker errors are left unclassified, which ShouldRetry retries
+	assert.Equal(t, componenterrors.ClassUnknown, componenterrors.ClassOf(classifyKafkaError(sarama.ErrDuplicateSequenceNumber)))
+}
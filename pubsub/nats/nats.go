@@ -9,6 +9,7 @@ import (
 	"errors"
 	"fmt"
 
+	"github.com/dapr/components-contrib/componenterrors"
 	"github.com/dapr/components-contrib/pubsub"
 	"github.com/dapr/dapr/pkg/logger"
 	nats "github.com/nats-io/go-nats"
@@ -68,7 +69,31 @@ func (n *natsPubSub) Init(metadata pubsub.Metadata) error {
 func (n *natsPubSub) Publish(req *pubsub.PublishRequest) error {
 	err := n.natsConn.Publish(req.Topic, req.Data)
 	if err != nil {
-		return fmt.Errorf("nats: error from publish: %s", err)
+		return classifyNATSError(fmt.Errorf("nats: error from publish: %w", err))
+	}
+	return nil
+}
+
+// classifyNATSError maps the errors of the NATS client to componenterrors classes
+func classifyNATSError(err error) error {
+	switch {
+	case errors.Is(err, nats.ErrConnectionClosed), errors.Is(err, nats.ErrConnectionDraining),
+		errors.Is(err, nats.ErrReconnectBufExceeded), errors.Is(err, nats.ErrNoServers),
+		errors.Is(err, nats.ErrTimeout), errors.Is(err, nats.ErrStaleConnection):
+		return componenterrors.Retriable(err)
+	case errors.Is(err, nats.ErrAuthorization):
+		return componenterrors.Unauthorized(err)
+	case errors.Is(err, nats.ErrBadSubject), errors.Is(err, nats.ErrMaxPayload):
+		return componenterrors.Permanent(err)
+	default:
+		return componenterrors.Classify(err)
+	}
+}
+
+// Close closes the connection, which ends the subscriptions.
+func (n *natsPubSub) Close() error {
+	if n.natsConn != nil {
+		n.natsConn.Close()
 	}
 	return nil
 }
@@ -8,10 +8,14 @@ package nats
 import (
 	"errors"
 	"testing"
+	"time"
 
+	"github.com/nats-io/gnatsd/server"
 	"github.com/stretchr/testify/assert"
 
 	"github.com/dapr/components-contrib/pubsub"
+	"github.com/dapr/components-contrib/pubsub/conformance"
+	"github.com/dapr/dapr/pkg/logger"
 )
 
 func TestParseNATSMetadata(t *testing.T) {
@@ -68,3 +72,21 @@ func TestParseNATSMetadata(t *testing.T) {
 		assert.Empty(t, m.natsURL)
 	})
 }
+
+func TestConformance(t *testing.T) {
+	s := server.New(&server.Options{Host: "127.0.0.1", Port: -1, NoLog: true, NoSigs: true})
+	go s.Start()
+	defer s.Shutdown()
+	if !s.ReadyForConnections(time.Second * 5) {
+		t.Fatal("nats server didn't start")
+	}
+
+	conformance.Run(t, conformance.Config{
+		NewPubSub: func() pubsub.PubSub { return NewNATSPubSub(logger.NewLogger("test")) },
+		Metadata: map[string]string{
+			natsURL:    "nats://" + s.Addr().String(),
+			consumerID: "conformance",
+		},
+		Ordered: true,
+	})
+}
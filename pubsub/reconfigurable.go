@@ -0,0 +1,25 @@
+// ------------------------------------------------------------
+// Copyright (c) Microsoft Corporation.
+// Licensed under the MIT License.
+// ------------------------------------------------------------
+
+package pubsub
+
+import "errors"
+
+// ErrNotReconfigurable is returned by Reconfigure for pub subs that have to be recreated to change their metadata
+var ErrNotReconfigurable = errors.New("pub sub doesn't support reconfiguration")
+
+// Reconfigurable is a pub sub that can change its metadata without dropping its subscriptions
+type Reconfigurable interface {
+	PubSub
+	Reconfigure(metadata Metadata) error
+}
+
+// Reconfigure applies new metadata to a pub sub that implements Reconfigurable, and returns ErrNotReconfigurable otherwise.
+func Reconfigure(ps PubSub, metadata Metadata) error {
+	if r, ok := ps.(Reconfigurable); ok {
+		return r.Reconfigure(metadata)
+	}
+	return ErrNotReconfigurable
+}
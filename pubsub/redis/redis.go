@@ -10,10 +10,12 @@ import (
 	"errors"
 	"fmt"
 	"strconv"
+	"sync"
 	"time"
 
 	"github.com/dapr/dapr/pkg/logger"
 
+	"github.com/dapr/components-contrib/componenterrors"
 	"github.com/dapr/components-contrib/pubsub"
 	"github.com/go-redis/redis/v7"
 )
@@ -26,8 +28,12 @@ const (
 )
 
 type redisStreams struct {
+	// lock is held for reading while publishing, and for writing while Reconfigure replaces the client
+	lock     sync.RWMutex
 	metadata metadata
 	client   *redis.Client
+	// closed stops the subscriptions from reading once Close closed the client
+	closed bool
 
 	logger logger.Logger
 }
@@ -71,8 +77,81 @@ func (r *redisStreams) Init(metadata pubsub.Metadata) error {
 	if err != nil {
 		return err
 	}
+
+	client, err := r.connect(m)
+	if err != nil {
+		return err
+	}
+	r.metadata = m
+	r.client = client
+	return nil
+}
+
+// Reconfigure connects with new metadata, such as a rotated password, and replaces the client.
+// Publishes in flight complete with the previous client before it is closed, and subscriptions
+// continue reading with the new client. The consumerID can't change, because subscriptions
+// read as members of the consumer group created for it.
+func (r *redisStreams) Reconfigure(metadata pubsub.Metadata) error {
+	m, err := parseRedisMetadata(metadata)
+	if err != nil {
+		return err
+	}
+
+	r.lock.RLock()
+	current := r.metadata.consumerID
+	r.lock.RUnlock()
+	if m.consumerID != current {
+		return fmt.Errorf("redis streams error: consumerID can't be changed from %s to %s", current, m.consumerID)
+	}
+
+	client, err := r.connect(m)
+	if err != nil {
+		return err
+	}
+
+	r.lock.Lock()
+	old := r.client
+	r.client = client
 	r.metadata = m
+	r.lock.Unlock()
+
+	// Closing the previous client interrupts the blocking reads of subscriptions, which then switch to the new one
+	if old != nil {
+		if err := old.Close(); err != nil {
+			r.logger.Warnf("redis streams: error closing previous client: %s", err)
+		}
+	}
+	return nil
+}
+
+// Close closes the client, which stops the subscriptions.
+func (r *redisStreams) Close() error {
+	r.lock.Lock()
+	defer r.lock.Unlock()
+
+	if r.closed || r.client == nil {
+		return nil
+	}
+	r.closed = true
+	return r.client.Close()
+}
 
+func (r *redisStreams) isClosed() bool {
+	r.lock.RLock()
+	defer r.lock.RUnlock()
+
+	return r.closed
+}
+
+func (r *redisStreams) getClient() *redis.Client {
+	r.lock.RLock()
+	defer r.lock.RUnlock()
+
+	return r.client
+}
+
+// connect creates a client for m and checks it can reach the server
+func (r *redisStreams) connect(m metadata) (*redis.Client, error) {
 	options := &redis.Options{
 		Addr:            m.host,
 		Password:        m.password,
@@ -82,46 +161,53 @@ func (r *redisStreams) Init(metadata pubsub.Metadata) error {
 	}
 
 	/* #nosec */
-	if r.metadata.enableTLS {
+	if m.enableTLS {
 		options.TLSConfig = &tls.Config{
-			InsecureSkipVerify: r.metadata.enableTLS,
+			InsecureSkipVerify: m.enableTLS,
 		}
 	}
 
 	client := redis.NewClient(options)
 
-	_, err = client.Ping().Result()
+	_, err := client.Ping().Result()
 	if err != nil {
-		return fmt.Errorf("redis streams: error connecting to redis at %s: %s", m.host, err)
+		client.Close()
+		return nil, fmt.Errorf("redis streams: error connecting to redis at %s: %s", m.host, err)
 	}
 
-	r.client = client
-	return nil
+	return client, nil
 }
 
 func (r *redisStreams) Publish(req *pubsub.PublishRequest) error {
+	r.lock.RLock()
+	defer r.lock.RUnlock()
+
 	_, err := r.client.XAdd(&redis.XAddArgs{
 		Stream: req.Topic,
 		Values: map[string]interface{}{"data": req.Data},
 	}).Result()
 	if err != nil {
-		return fmt.Errorf("redis streams: error from publish: %s", err)
+		return componenterrors.FromRedis(fmt.Errorf("redis streams: error from publish: %w", err))
 	}
 
 	return nil
 }
 
 func (r *redisStreams) Subscribe(req pubsub.SubscribeRequest, handler func(msg *pubsub.NewMessage) error) error {
-	err := r.client.XGroupCreateMkStream(req.Topic, r.metadata.consumerID, "0").Err()
+	r.lock.RLock()
+	consumerID := r.metadata.consumerID
+	err := r.client.XGroupCreateMkStream(req.Topic, consumerID, "0").Err()
+	r.lock.RUnlock()
+
 	if err != nil {
 		r.logger.Warnf("redis streams: %s", err)
 	}
-	go r.beginReadingFromStream(req.Topic, r.metadata.consumerID, handler)
+	go r.beginReadingFromStream(req.Topic, consumerID, handler)
 	return nil
 }
 
-func (r *redisStreams) readFromStream(stream, consumerID, start string) ([]redis.XStream, error) {
-	res, err := r.client.XReadGroup(&redis.XReadGroupArgs{
+func (r *redisStreams) readFromStream(client *redis.Client, stream, consumerID, start string) ([]redis.XStream, error) {
+	res, err := client.XReadGroup(&redis.XReadGroupArgs{
 		Group:    consumerID,
 		Consumer: consumerID,
 		Streams:  []string{stream, start},
@@ -148,7 +234,7 @@ func (r *redisStreams) processStreams(consumerID string, streams []redis.XStream
 
 				err := handler(&msg)
 				if err == nil {
-					r.client.XAck(stream, consumerID, message.ID).Result()
+					r.getClient().XAck(stream, consumerID, message.ID).Result()
 				}
 			}(s.Stream, m)
 		}
@@ -160,8 +246,18 @@ func (r *redisStreams) beginReadingFromStream(stream, consumerID string, handler
 	start := "0"
 
 	for {
-		streams, err := r.readFromStream(stream, consumerID, start)
+		client := r.getClient()
+		streams, err := r.readFromStream(client, stream, consumerID, start)
 		if err != nil {
+			if r.isClosed() {
+				return
+			}
+			if r.getClient() != client {
+				// The client was replaced by Reconfigure, read the pending items again in case
+				// the reply of the interrupted read was lost
+				start = "0"
+				continue
+			}
 			r.logger.Errorf("redis streams: error reading from stream %s: %s", stream, err)
 			return
 		}
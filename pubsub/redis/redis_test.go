@@ -11,10 +11,12 @@ import (
 	"testing"
 	"time"
 
+	"github.com/alicebob/miniredis/v2"
 	"github.com/go-redis/redis/v7"
 	"github.com/stretchr/testify/assert"
 
 	"github.com/dapr/components-contrib/pubsub"
+	"github.com/dapr/components-contrib/pubsub/conformance"
 	"github.com/dapr/dapr/pkg/logger"
 )
 
@@ -138,3 +140,56 @@ func generateRedisStreamTestData(topicCount, messageCount int, data string) []re
 	}
 	return redisStreams
 }
+
+func TestConformance(t *testing.T) {
+	s, err := miniredis.Run()
+	assert.Nil(t, err)
+	defer s.Close()
+
+	conformance.Run(t, conformance.Config{
+		NewPubSub: func() pubsub.PubSub { return NewRedisStreams(logger.NewLogger("test")) },
+		Metadata: map[string]string{
+			host:       s.Addr(),
+			consumerID: "conformance",
+		},
+	})
+}
+
+func TestReconfigure(t *testing.T) {
+	s, err := miniredis.Run()
+	assert.Nil(t, err)
+	defer s.Close()
+
+	ps := NewRedisStreams(logger.NewLogger("test")).(*redisStreams)
+	assert.Nil(t, ps.Init(pubsub.Metadata{Properties: map[string]string{host: s.Addr(), consumerID: "reconfigure"}}))
+	defer ps.Close()
+
+	received := make(chan string, 10)
+	err = ps.Subscribe(pubsub.SubscribeRequest{Topic: "topic"}, func(msg *pubsub.NewMessage) error {
+		received <- string(msg.Data)
+		return nil
+	})
+	assert.Nil(t, err)
+
+	assert.Nil(t, ps.Publish(&pubsub.PublishRequest{Topic: "topic", Data: []byte("before")}))
+	assert.Equal(t, "before", waitForMessage(received))
+
+	// The password is rotated on the server
+	s.RequireAuth("rotated")
+	assert.NotNil(t, pubsub.Reconfigure(ps, pubsub.Metadata{Properties: map[string]string{host: s.Addr(), consumerID: "other", password: "rotated"}}))
+	assert.NotNil(t, pubsub.Reconfigure(ps, pubsub.Metadata{Properties: map[string]string{host: s.Addr(), consumerID: "reconfigure", password: "wrong"}}))
+	assert.Nil(t, pubsub.Reconfigure(ps, pubsub.Metadata{Properties: map[string]string{host: s.Addr(), consumerID: "reconfigure", password: "rotated"}}))
+
+	// The subscription continues with the new client
+	assert.Nil(t, ps.Publish(&pubsub.PublishRequest{Topic: "topic", Data: []byte("after")}))
+	assert.Equal(t, "after", waitForMessage(received))
+}
+
+func waitForMessage(received chan string) string {
+	select {
+	case msg := <-received:
+		return msg
+	case <-time.After(time.Second * 5):
+		return ""
+	}
+}
@@ -7,13 +7,15 @@ package pubsub
 
 // PublishRequest is the request to publish a message
 type PublishRequest struct {
-	Data  []byte `json:"data"`
-	Topic string `json:"topic"`
+	Data     []byte            `json:"data"`
+	Topic    string            `json:"topic"`
+	Metadata map[string]string `json:"metadata"`
 }
 
 // SubscribeRequest is the request to subscribe to a topic
 type SubscribeRequest struct {
-	Topic string `json:"topic"`
+	Topic    string            `json:"topic"`
+	Metadata map[string]string `json:"metadata"`
 }
 
 // NewMessage is an event arriving from a message bus instance
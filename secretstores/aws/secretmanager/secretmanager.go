@@ -8,6 +8,7 @@ package secretmanager
 import (
 	"encoding/json"
 	"fmt"
+	"sync"
 
 	aws_auth "github.com/dapr/components-contrib/authentication/aws"
 
@@ -36,6 +37,8 @@ type secretManagerMetaData struct {
 }
 
 type smSecretStore struct {
+	// lock guards client, which Reconfigure replaces
+	lock   sync.RWMutex
 	client secretsmanageriface.SecretsManagerAPI
 	logger logger.Logger
 }
@@ -55,6 +58,25 @@ func (s *smSecretStore) Init(metadata secretstores.Metadata) error {
 	return nil
 }
 
+// Reconfigure creates a client with the new metadata, such as rotated access keys, and replaces the current one
+func (s *smSecretStore) Reconfigure(metadata secretstores.Metadata) error {
+	meta, err := s.getSecretManagerMetadata(metadata)
+	if err != nil {
+		return err
+	}
+
+	client, err := s.getClient(meta)
+	if err != nil {
+		return err
+	}
+
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	s.client = client
+	return nil
+}
+
 // GetSecret retrieves a secret using a key and returns a map of decrypted string/string values
 func (s *smSecretStore) GetSecret(req secretstores.GetSecretRequest) (secretstores.GetSecretResponse, error) {
 	var versionID *string
@@ -66,7 +88,11 @@ func (s *smSecretStore) GetSecret(req secretstores.GetSecretRequest) (secretstor
 		versionStage = &value
 	}
 
-	output, err := s.client.GetSecretValue(&secretsmanager.GetSecretValueInput{
+	s.lock.RLock()
+	client := s.client
+	s.lock.RUnlock()
+
+	output, err := client.GetSecretValue(&secretsmanager.GetSecretValueInput{
 		SecretId:     &req.Name,
 		VersionId:    versionID,
 		VersionStage: versionStage,
@@ -104,5 +130,9 @@ func (s *smSecretStore) getSecretManagerMetadata(spec secretstores.Metadata) (*s
 	if err != nil {
 		return nil, err
 	}
+	// A key without its secret would silently fall back to the default credentials
+	if (meta.AccessKey == "") != (meta.SecretKey == "") {
+		return nil, fmt.Errorf("accessKey and secretKey must be set together")
+	}
 	return &meta, nil
 }
@@ -136,3 +136,23 @@ func TestGetSecret(t *testing.T) {
 		assert.NotNil(t, err)
 	})
 }
+
+func TestReconfigure(t *testing.T) {
+	s := NewSecretManager(logger.NewLogger("test")).(*smSecretStore)
+	err := s.Init(secretstores.Metadata{Properties: map[string]string{"Region": "a", "AccessKey": "a", "SecretKey": "a"}})
+	assert.Nil(t, err)
+
+	t.Run("invalid metadata keeps the current client", func(t *testing.T) {
+		client := s.client
+		err := s.Reconfigure(secretstores.Metadata{Properties: map[string]string{"Region": "a", "AccessKey": "b"}})
+		assert.NotNil(t, err)
+		assert.True(t, client == s.client)
+	})
+
+	t.Run("new metadata replaces the client", func(t *testing.T) {
+		client := s.client
+		err := secretstores.Reconfigure(s, secretstores.Metadata{Properties: map[string]string{"Region": "a", "AccessKey": "b", "SecretKey": "b"}})
+		assert.Nil(t, err)
+		assert.False(t, client == s.client)
+	})
+}
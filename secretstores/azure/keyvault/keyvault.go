@@ -8,6 +8,7 @@ package keyvault
 import (
 	"context"
 	"fmt"
+	"sync"
 
 	"github.com/dapr/components-contrib/secretstores"
 	"github.com/dapr/dapr/pkg/logger"
@@ -26,6 +27,8 @@ const (
 )
 
 type keyvaultSecretStore struct {
+	// lock guards vaultName and vaultClient, which Reconfigure replaces
+	lock        sync.RWMutex
 	vaultName   string
 	vaultClient kv.BaseClient
 
@@ -43,23 +46,59 @@ func NewAzureKeyvaultSecretStore(logger logger.Logger) secretstores.SecretStore
 
 // Init creates a Kubernetes client
 func (k *keyvaultSecretStore) Init(metadata secretstores.Metadata) error {
+	client, vaultName, err := newVaultClient(metadata)
+	if err != nil {
+		return err
+	}
+
+	k.vaultClient = client
+	k.vaultName = vaultName
+	return nil
+}
+
+// Reconfigure authenticates with the new metadata, such as a rotated certificate, and replaces the vault client
+func (k *keyvaultSecretStore) Reconfigure(metadata secretstores.Metadata) error {
+	client, vaultName, err := newVaultClient(metadata)
+	if err != nil {
+		return err
+	}
+
+	k.lock.Lock()
+	defer k.lock.Unlock()
+
+	k.vaultClient = client
+	k.vaultName = vaultName
+	return nil
+}
+
+// newVaultClient returns a client authorized with the credentials of metadata and the name of its vault
+func newVaultClient(metadata secretstores.Metadata) (kv.BaseClient, string, error) {
 	settings := EnvironmentSettings{
 		Values: metadata.Properties,
 	}
 
-	authorizer, err := settings.GetAuthorizer()
-	if err == nil {
-		k.vaultClient.Authorizer = authorizer
+	vaultName := settings.Values[componentVaultName]
+	if vaultName == "" {
+		return kv.BaseClient{}, "", fmt.Errorf("missing %s", componentVaultName)
 	}
 
-	k.vaultName = settings.Values[componentVaultName]
+	authorizer, err := settings.GetAuthorizer()
+	if err != nil {
+		return kv.BaseClient{}, "", err
+	}
 
-	return err
+	client := kv.New()
+	client.Authorizer = authorizer
+	return client, vaultName, nil
 }
 
 // GetSecret retrieves a secret using a key and returns a map of decrypted string/string values
 func (k *keyvaultSecretStore) GetSecret(req secretstores.GetSecretRequest) (secretstores.GetSecretResponse, error) {
-	secretResp, err := k.vaultClient.GetSecret(context.Background(), k.getVaultURI(), req.Name, "")
+	k.lock.RLock()
+	client, vaultURI := k.vaultClient, k.getVaultURI()
+	k.lock.RUnlock()
+
+	secretResp, err := client.GetSecret(context.Background(), vaultURI, req.Name, "")
 	if err != nil {
 		return secretstores.GetSecretResponse{}, err
 	}
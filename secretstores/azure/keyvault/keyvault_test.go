@@ -0,0 +1,59 @@
+// ------------------------------------------------------------
+// Copyright (c) Microsoft Corporation.
+// Licensed under the MIT License.
+// ------------------------------------------------------------
+
+package keyvault
+
+import (
+	"testing"
+
+	"github.com/dapr/components-contrib/secretstores"
+	"github.com/dapr/dapr/pkg/logger"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestInit(t *testing.T) {
+	k := NewAzureKeyvaultSecretStore(logger.NewLogger("test")).(*keyvaultSecretStore)
+	err := k.Init(secretstores.Metadata{Properties: map[string]string{componentSPNClientID: fakeClientID}})
+	assert.NotNil(t, err)
+
+	err = k.Init(secretstores.Metadata{Properties: map[string]string{componentSPNClientID: fakeClientID, componentVaultName: "vault-a"}})
+	assert.Nil(t, err)
+	assert.Equal(t, "https://vault-a.vault.azure.net", k.getVaultURI())
+	assert.NotNil(t, k.vaultClient.Authorizer)
+}
+
+func TestReconfigure(t *testing.T) {
+	k := NewAzureKeyvaultSecretStore(logger.NewLogger("test")).(*keyvaultSecretStore)
+	err := k.Init(secretstores.Metadata{Properties: map[string]string{componentSPNClientID: fakeClientID, componentVaultName: "vault-a"}})
+	assert.Nil(t, err)
+
+	t.Run("invalid metadata keeps the current client", func(t *testing.T) {
+		client := k.vaultClient
+		err := k.Reconfigure(secretstores.Metadata{Properties: map[string]string{componentSPNClientID: fakeClientID}})
+		assert.NotNil(t, err)
+		assert.Equal(t, "vault-a", k.vaultName)
+		assert.Equal(t, client, k.vaultClient)
+
+		err = k.Reconfigure(secretstores.Metadata{Properties: map[string]string{
+			componentSPNCertificate: "invalid",
+			componentSPNClientID:    fakeClientID,
+			componentSPNTenantID:    fakeTenantID,
+			componentVaultName:      "vault-b",
+		}})
+		assert.NotNil(t, err)
+		assert.Equal(t, "vault-a", k.vaultName)
+	})
+
+	t.Run("new metadata replaces the client", func(t *testing.T) {
+		err := secretstores.Reconfigure(k, secretstores.Metadata{Properties: map[string]string{
+			componentSPNCertificate: string(getTestCert()),
+			componentSPNClientID:    fakeClientID,
+			componentSPNTenantID:    fakeTenantID,
+			componentVaultName:      "vault-b",
+		}})
+		assert.Nil(t, err)
+		assert.Equal(t, "https://vault-b.vault.azure.net", k.getVaultURI())
+	})
+}
@@ -0,0 +1,117 @@
+// ------------------------------------------------------------
+// Copyright (c) Microsoft Corporation.
+// Licensed under the MIT License.
+// ------------------------------------------------------------
+
+// Package conformance checks that secret stores behave the way Dapr expects.
+// Component tests call Run with a Config describing the store and the secrets it holds.
+package conformance
+
+import (
+	"fmt"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/dapr/components-contrib/secretstores"
+	"github.com/stretchr/testify/assert"
+)
+
+// Config describes a secret store under test
+type Config struct {
+	// NewSecretStore returns a new instance of the store, Run initializes it
+	NewSecretStore func() secretstores.SecretStore
+	// Metadata initializes the store
+	Metadata map[string]string
+	// Secrets holds the data each secret of the store is expected to return
+	Secrets map[string]map[string]string
+}
+
+// Run runs the conformance tests against the store described by config
+func Run(t *testing.T, config Config) {
+	store := config.NewSecretStore()
+	if err := store.Init(secretstores.Metadata{Properties: copyMap(config.Metadata)}); err != nil {
+		t.Fatalf("init failed: %s", err)
+	}
+
+	t.Run("get", func(t *testing.T) {
+		testGet(t, store, config)
+	})
+	t.Run("missing secret", func(t *testing.T) {
+		testMissingSecret(t, store)
+	})
+	t.Run("nil metadata", func(t *testing.T) {
+		testNilMetadata(t, store, config)
+	})
+	t.Run("response isolation", func(t *testing.T) {
+		testResponseIsolation(t, store, config)
+	})
+	t.Run("concurrent get", func(t *testing.T) {
+		testConcurrentGet(t, store, config)
+	})
+}
+
+func testGet(t *testing.T, store secretstores.SecretStore, config Config) {
+	for name, data := range config.Secrets {
+		res, err := store.GetSecret(secretstores.GetSecretRequest{Name: name, Metadata: map[string]string{}})
+		assert.Nil(t, err, "getting %s", name)
+		assert.Equal(t, data, res.Data, "data of %s", name)
+	}
+}
+
+func testMissingSecret(t *testing.T, store secretstores.SecretStore) {
+	name := fmt.Sprintf("conformance-missing-%d", time.Now().UnixNano())
+	_, err := store.GetSecret(secretstores.GetSecretRequest{Name: name, Metadata: map[string]string{}})
+	assert.NotNil(t, err, "getting a missing secret must fail")
+}
+
+func testNilMetadata(t *testing.T, store secretstores.SecretStore, config Config) {
+	for name, data := range config.Secrets {
+		res, err := store.GetSecret(secretstores.GetSecretRequest{Name: name})
+		assert.Nil(t, err, "getting %s without metadata", name)
+		assert.Equal(t, data, res.Data, "data of %s", name)
+		return
+	}
+}
+
+// testResponseIsolation checks that callers can modify the data of a response
+func testResponseIsolation(t *testing.T, store secretstores.SecretStore, config Config) {
+	for name, data := range config.Secrets {
+		res, err := store.GetSecret(secretstores.GetSecretRequest{Name: name, Metadata: map[string]string{}})
+		if !assert.Nil(t, err) {
+			return
+		}
+		for k := range res.Data {
+			res.Data[k] = "modified"
+		}
+
+		res, err = store.GetSecret(secretstores.GetSecretRequest{Name: name, Metadata: map[string]string{}})
+		assert.Nil(t, err)
+		assert.Equal(t, data, res.Data, "modifying a response must not modify the store")
+		return
+	}
+}
+
+func testConcurrentGet(t *testing.T, store secretstores.SecretStore, config Config) {
+	var wg sync.WaitGroup
+	for i := 0; i < 10; i++ {
+		for name, data := range config.Secrets {
+			wg.Add(1)
+			go func(name string, data map[string]string) {
+				defer wg.Done()
+				res, err := store.GetSecret(secretstores.GetSecretRequest{Name: name, Metadata: map[string]string{}})
+				assert.Nil(t, err)
+				assert.Equal(t, data, res.Data)
+			}(name, data)
+		}
+	}
+	wg.Wait()
+}
+
+func copyMap(m map[string]string) map[string]string {
+	result := make(map[string]string, len(m))
+	for k, v := range m {
+		result[k] = v
+	}
+	return result
+}
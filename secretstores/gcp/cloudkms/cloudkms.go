@@ -10,6 +10,7 @@ import (
 	"encoding/json"
 	"fmt"
 	"io/ioutil"
+	"sync"
 
 	cloudkms "cloud.google.com/go/kms/apiv1"
 	"cloud.google.com/go/storage"
@@ -21,6 +22,8 @@ import (
 
 // cloudkmsSecretStore is a secret store implementation of GCS KMS
 type cloudkmsSecretStore struct {
+	// lock is held for reading by GetSecret, and for writing while Reconfigure replaces the clients
+	lock           sync.RWMutex
 	cloudkmsclient *cloudkms.KeyManagementClient
 	storageclient  *storage.Client
 	metadata       *cloudkmsMetadata
@@ -82,8 +85,39 @@ func (c *cloudkmsSecretStore) Init(metadata secretstores.Metadata) error {
 	return nil
 }
 
+// Reconfigure creates clients with the new metadata, such as a rotated service account key, and replaces
+// the current ones. Secrets being retrieved are read with the previous clients before they are closed.
+func (c *cloudkmsSecretStore) Reconfigure(metadata secretstores.Metadata) error {
+	next := &cloudkmsSecretStore{logger: c.logger}
+	if err := next.Init(metadata); err != nil {
+		return err
+	}
+
+	c.lock.Lock()
+	oldKMS, oldStorage := c.cloudkmsclient, c.storageclient
+	c.cloudkmsclient = next.cloudkmsclient
+	c.storageclient = next.storageclient
+	c.metadata = next.metadata
+	c.lock.Unlock()
+
+	if oldKMS != nil {
+		if err := oldKMS.Close(); err != nil {
+			c.logger.Warnf("error closing previous cloudkms client: %s", err)
+		}
+	}
+	if oldStorage != nil {
+		if err := oldStorage.Close(); err != nil {
+			c.logger.Warnf("error closing previous cloud storage client: %s", err)
+		}
+	}
+	return nil
+}
+
 // GetSecret retrieves a secret using a key and returns a map of decrypted string
 func (c *cloudkmsSecretStore) GetSecret(req secretstores.GetSecretRequest) (secretstores.GetSecretResponse, error) {
+	c.lock.RLock()
+	defer c.lock.RUnlock()
+
 	gcpStorageBucket := c.metadata.GCPStorageBucket
 	secretObject := c.metadata.SecretObject
 
@@ -42,3 +42,43 @@ func TestInit(t *testing.T) {
 		assert.Equal(t, err, fmt.Errorf("error creating cloudkms client: missing 'type' field in credentials"))
 	})
 }
+
+func TestReconfigure(t *testing.T) {
+	properties := map[string]string{
+		"type":                        "service_account",
+		"project_id":                  "a",
+		"private_key_id":              "a",
+		"private_key":                 "a",
+		"client_email":                "a",
+		"client_id":                   "a",
+		"auth_uri":                    "a",
+		"token_uri":                   "a",
+		"auth_provider_x509_cert_url": "a",
+		"client_x509_cert_url":        "a",
+		"secret_object":               "a",
+		"gcp_storage_bucket":          "a",
+		"key_ring_id":                 "a",
+		"crypto_key_id":               "a",
+	}
+	s := NewCloudKMSSecretStore(logger.NewLogger("test")).(*cloudkmsSecretStore)
+	err := s.Init(secretstores.Metadata{Properties: properties})
+	assert.Nil(t, err)
+
+	t.Run("invalid metadata keeps the current client", func(t *testing.T) {
+		kmsClient, storageClient := s.cloudkmsclient, s.storageclient
+		err := s.Reconfigure(secretstores.Metadata{Properties: map[string]string{"secret_object": "b"}})
+		assert.NotNil(t, err)
+		assert.Equal(t, "a", s.metadata.SecretObject)
+		assert.True(t, kmsClient == s.cloudkmsclient)
+		assert.True(t, storageClient == s.storageclient)
+	})
+
+	t.Run("new metadata replaces the client", func(t *testing.T) {
+		kmsClient := s.cloudkmsclient
+		properties["secret_object"] = "b"
+		err := secretstores.Reconfigure(s, secretstores.Metadata{Properties: properties})
+		assert.Nil(t, err)
+		assert.Equal(t, "b", s.metadata.SecretObject)
+		assert.False(t, kmsClient == s.cloudkmsclient)
+	})
+}
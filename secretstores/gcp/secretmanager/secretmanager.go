@@ -4,6 +4,7 @@ import (
 	"context"
 	"encoding/json"
 	"fmt"
+	"sync"
 
 	secretmanager "cloud.google.com/go/secretmanager/apiv1beta1"
 	"github.com/dapr/components-contrib/secretstores"
@@ -33,6 +34,8 @@ type secretManagerMetadata struct {
 
 // Store contains and GCP secret manager client and project id
 type Store struct {
+	// lock is held for reading by GetSecret, and for writing while Reconfigure replaces the client
+	lock      sync.RWMutex
 	client    *secretmanager.Client
 	ProjectID string
 
@@ -62,6 +65,33 @@ func (s *Store) Init(metadataRaw secretstores.Metadata) error {
 	return nil
 }
 
+// Reconfigure creates a client with the new metadata, such as a rotated service account key, and replaces
+// the current one. Secrets being retrieved are read with the previous client before it is closed.
+func (s *Store) Reconfigure(metadataRaw secretstores.Metadata) error {
+	metadata, err := s.parseSecretManagerMetadata(metadataRaw)
+	if err != nil {
+		return err
+	}
+
+	client, err := s.getClient(metadata)
+	if err != nil {
+		return fmt.Errorf("failed to setup secretmanager client: %s", err)
+	}
+
+	s.lock.Lock()
+	old := s.client
+	s.client = client
+	s.ProjectID = metadata.ProjectID
+	s.lock.Unlock()
+
+	if old != nil {
+		if err := old.Close(); err != nil {
+			s.logger.Warnf("error closing previous secretmanager client: %s", err)
+		}
+	}
+	return nil
+}
+
 func (s *Store) getClient(metadata *secretManagerMetadata) (*secretmanager.Client, error) {
 	b, _ := json.Marshal(metadata)
 	clientOptions := option.WithCredentialsJSON(b)
@@ -79,6 +109,9 @@ func (s *Store) getClient(metadata *secretManagerMetadata) (*secretmanager.Clien
 func (s *Store) GetSecret(req secretstores.GetSecretRequest) (secretstores.GetSecretResponse, error) {
 	res := secretstores.GetSecretResponse{Data: nil}
 
+	s.lock.RLock()
+	defer s.lock.RUnlock()
+
 	if s.client == nil {
 		return res, fmt.Errorf("client is not initialized")
 	}
@@ -81,3 +81,38 @@ func TestGetSecret(t *testing.T) {
 		assert.Equal(t, secretstores.GetSecretResponse{Data: nil}, v)
 	})
 }
+
+func TestReconfigure(t *testing.T) {
+	properties := map[string]string{
+		"type":                        "service_account",
+		"project_id":                  "a",
+		"private_key_id":              "a",
+		"private_key":                 "a",
+		"client_email":                "a",
+		"client_id":                   "a",
+		"auth_uri":                    "a",
+		"token_uri":                   "a",
+		"auth_provider_x509_cert_url": "a",
+		"client_x509_cert_url":        "a",
+	}
+	sm := NewSecreteManager(logger.NewLogger("test"))
+	err := sm.Init(secretstores.Metadata{Properties: properties})
+	assert.Nil(t, err)
+
+	t.Run("invalid metadata keeps the current client", func(t *testing.T) {
+		client := sm.client
+		err := sm.Reconfigure(secretstores.Metadata{Properties: map[string]string{"type": "service_account"}})
+		assert.NotNil(t, err)
+		assert.Equal(t, "a", sm.ProjectID)
+		assert.True(t, client == sm.client)
+	})
+
+	t.Run("new metadata replaces the client", func(t *testing.T) {
+		client := sm.client
+		properties["project_id"] = "b"
+		err := secretstores.Reconfigure(sm, secretstores.Metadata{Properties: properties})
+		assert.Nil(t, err)
+		assert.Equal(t, "b", sm.ProjectID)
+		assert.False(t, client == sm.client)
+	})
+}
@@ -17,6 +17,7 @@ import (
 	"net/http"
 	"os"
 	"path/filepath"
+	"sync"
 
 	"golang.org/x/net/http2"
 
@@ -41,6 +42,8 @@ const (
 
 // vaultSecretStore is a secret store implementation for HashiCorp Vault
 type vaultSecretStore struct {
+	// lock is held for reading by GetSecret, and for writing while Reconfigure replaces the client
+	lock                sync.RWMutex
 	client              *http.Client
 	vaultAddress        string
 	vaultTokenMountPath string
@@ -112,6 +115,26 @@ func (v *vaultSecretStore) Init(metadata secretstores.Metadata) error {
 	return nil
 }
 
+// Reconfigure creates a client with the new metadata, such as a rotated CA certificate, and replaces the
+// current one. Secrets being retrieved are read with the previous client before its connections are closed.
+func (v *vaultSecretStore) Reconfigure(metadata secretstores.Metadata) error {
+	next := &vaultSecretStore{logger: v.logger}
+	if err := next.Init(metadata); err != nil {
+		return err
+	}
+
+	v.lock.Lock()
+	old := v.client
+	v.client = next.client
+	v.vaultAddress = next.vaultAddress
+	v.vaultTokenMountPath = next.vaultTokenMountPath
+	v.vaultKVPrefix = next.vaultKVPrefix
+	v.lock.Unlock()
+
+	old.CloseIdleConnections()
+	return nil
+}
+
 func metadataToTLSConfig(props map[string]string) *tlsConfig {
 	tlsConf := tlsConfig{}
 
@@ -132,6 +155,9 @@ func metadataToTLSConfig(props map[string]string) *tlsConfig {
 
 // GetSecret retrieves a secret using a key and returns a map of decrypted string/string values
 func (v *vaultSecretStore) GetSecret(req secretstores.GetSecretRequest) (secretstores.GetSecretResponse, error) {
+	v.lock.RLock()
+	defer v.lock.RUnlock()
+
 	token, err := v.readVaultToken()
 	if err != nil {
 		return secretstores.GetSecretResponse{Data: nil}, err
@@ -13,6 +13,7 @@ import (
 	"testing"
 
 	"github.com/dapr/components-contrib/secretstores"
+	"github.com/dapr/dapr/pkg/logger"
 	"github.com/stretchr/testify/assert"
 )
 
@@ -89,3 +90,32 @@ func getCertificate() []byte {
 
 	return certificateBytes
 }
+
+func TestReconfigure(t *testing.T) {
+	v := NewHashiCorpVaultSecretStore(logger.NewLogger("test")).(*vaultSecretStore)
+	err := v.Init(secretstores.Metadata{Properties: map[string]string{
+		componentVaultAddress:        "https://vault-a:8200",
+		componentVaultTokenMountPath: "/var/run/token",
+	}})
+	assert.Nil(t, err)
+
+	t.Run("invalid metadata keeps the current client", func(t *testing.T) {
+		client := v.client
+		err := v.Reconfigure(secretstores.Metadata{Properties: map[string]string{componentVaultAddress: "https://vault-b:8200"}})
+		assert.NotNil(t, err)
+		assert.Equal(t, "https://vault-a:8200", v.vaultAddress)
+		assert.Equal(t, client, v.client)
+	})
+
+	t.Run("new metadata replaces the client", func(t *testing.T) {
+		err := secretstores.Reconfigure(v, secretstores.Metadata{Properties: map[string]string{
+			componentVaultAddress:        "https://vault-b:8200",
+			componentVaultTokenMountPath: "/var/run/rotated-token",
+			componentVaultKVPrefix:       "tenant",
+		}})
+		assert.Nil(t, err)
+		assert.Equal(t, "https://vault-b:8200", v.vaultAddress)
+		assert.Equal(t, "/var/run/rotated-token", v.vaultTokenMountPath)
+		assert.Equal(t, "tenant", v.vaultKVPrefix)
+	})
+}
@@ -13,6 +13,7 @@ import (
 	"os"
 	"strconv"
 	"strings"
+	"sync"
 
 	"github.com/dapr/components-contrib/secretstores"
 	"github.com/dapr/dapr/pkg/logger"
@@ -24,6 +25,8 @@ type localSecretStoreMetaData struct {
 }
 
 type localSecretStore struct {
+	// lock guards secrets, which Reconfigure replaces
+	lock            sync.RWMutex
 	secretsFile     string
 	nestedSeparator string
 	currenContext   []string
@@ -67,9 +70,31 @@ func (j *localSecretStore) Init(metadata secretstores.Metadata) error {
 	return nil
 }
 
+// Reconfigure reads the secrets file of the new metadata and replaces the secrets
+func (j *localSecretStore) Reconfigure(metadata secretstores.Metadata) error {
+	next := &localSecretStore{
+		readLocalFileFn: j.readLocalFileFn,
+		logger:          j.logger,
+	}
+	if err := next.Init(metadata); err != nil {
+		return err
+	}
+
+	j.lock.Lock()
+	defer j.lock.Unlock()
+
+	j.secretsFile = next.secretsFile
+	j.nestedSeparator = next.nestedSeparator
+	j.secrets = next.secrets
+	return nil
+}
+
 // GetSecret retrieves a secret using a key and returns a map of decrypted string/string values
 func (j *localSecretStore) GetSecret(req secretstores.GetSecretRequest) (secretstores.GetSecretResponse, error) {
+	j.lock.RLock()
 	secretValue, exists := j.secrets[req.Name]
+	j.lock.RUnlock()
+
 	if !exists {
 		return secretstores.GetSecretResponse{}, fmt.Errorf("secret %s not found", req.Name)
 	}
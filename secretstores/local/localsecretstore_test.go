@@ -6,9 +6,12 @@ package localsecretstore
 
 import (
 	"fmt"
+	"io/ioutil"
+	"os"
 	"testing"
 
 	"github.com/dapr/components-contrib/secretstores"
+	"github.com/dapr/components-contrib/secretstores/conformance"
 	"github.com/dapr/dapr/pkg/logger"
 	"github.com/stretchr/testify/assert"
 )
@@ -78,3 +81,52 @@ func TestGetSecret(t *testing.T) {
 		assert.Equal(t, err, fmt.Errorf("secret %s not found", req.Name))
 	})
 }
+
+func TestConformance(t *testing.T) {
+	f, err := ioutil.TempFile("", "secrets.json")
+	assert.Nil(t, err)
+	defer os.Remove(f.Name())
+	f.WriteString(`{"apikey": "abc", "db": {"password": "p@ss", "users": ["admin"]}}`) //nolint:errcheck
+	f.Close()
+
+	conformance.Run(t, conformance.Config{
+		NewSecretStore: func() secretstores.SecretStore { return NewLocalSecretStore(logger.NewLogger("test")) },
+		Metadata:       map[string]string{"secretsFile": f.Name()},
+		Secrets: map[string]map[string]string{
+			"apikey":      {"apikey": "abc"},
+			"db:password": {"db:password": "p@ss"},
+			"db:users:0":  {"db:users:0": "admin"},
+		},
+	})
+}
+
+func TestReconfigure(t *testing.T) {
+	files := map[string]map[string]interface{}{
+		"current.json": {"secret": "current"},
+		"rotated.json": {"secret": "rotated"},
+	}
+	s := localSecretStore{
+		logger: logger.NewLogger("test"),
+		readLocalFileFn: func(secretsFile string) (map[string]interface{}, error) {
+			secrets, ok := files[secretsFile]
+			if !ok {
+				return nil, fmt.Errorf("%s not found", secretsFile)
+			}
+			return secrets, nil
+		},
+	}
+	assert.Nil(t, s.Init(secretstores.Metadata{Properties: map[string]string{"secretsFile": "current.json"}}))
+
+	req := secretstores.GetSecretRequest{Name: "secret"}
+	for _, invalid := range []map[string]string{{}, {"secretsFile": "missing.json"}} {
+		assert.NotNil(t, s.Reconfigure(secretstores.Metadata{Properties: invalid}))
+		output, err := s.GetSecret(req)
+		assert.Nil(t, err)
+		assert.Equal(t, "current", output.Data["secret"])
+	}
+
+	assert.Nil(t, secretstores.Reconfigure(&s, secretstores.Metadata{Properties: map[string]string{"secretsFile": "rotated.json"}}))
+	output, err := s.GetSecret(req)
+	assert.Nil(t, err)
+	assert.Equal(t, "rotated", output.Data["secret"])
+}
@@ -0,0 +1,25 @@
+// ------------------------------------------------------------
+// Copyright (c) Microsoft Corporation.
+// Licensed under the MIT License.
+// ------------------------------------------------------------
+
+package secretstores
+
+import "errors"
+
+// ErrNotReconfigurable is returned by Reconfigure for stores that have to be recreated to change their metadata
+var ErrNotReconfigurable = errors.New("secret store doesn't support reconfiguration")
+
+// Reconfigurable is a secret store that can switch to new credentials without being recreated
+type Reconfigurable interface {
+	SecretStore
+	Reconfigure(metadata Metadata) error
+}
+
+// Reconfigure applies new metadata to a store that implements Reconfigurable, and returns ErrNotReconfigurable otherwise.
+func Reconfigure(store SecretStore, metadata Metadata) error {
+	if r, ok := store.(Reconfigurable); ok {
+		return r.Reconfigure(metadata)
+	}
+	return ErrNotReconfigurable
+}
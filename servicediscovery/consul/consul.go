@@ -0,0 +1,453 @@
+// ------------------------------------------------------------
+// Copyright (c) Microsoft Corporation.
+// Licensed under the MIT License.
+// ------------------------------------------------------------
+
+package consul
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net"
+	"strconv"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/dapr/components-contrib/servicediscovery"
+	"github.com/dapr/dapr/pkg/logger"
+	"github.com/hashicorp/consul/api"
+	"github.com/pkg/errors"
+)
+
+const (
+	// DatacenterKey is the ResolveRequest data key to resolve an app in another datacenter
+	DatacenterKey = "datacenter"
+
+	// Defaults
+	defaultCheckInterval   = time.Second * 10
+	defaultCheckTimeout    = time.Second * 5
+	defaultDeregisterAfter = time.Minute
+	defaultWaitTime        = time.Minute
+	defaultIdleTimeout     = time.Minute * 10
+	retryInterval          = time.Second
+)
+
+type consulConfig struct {
+	Datacenter      string `json:"datacenter"`
+	HTTPAddr        string `json:"httpAddr"`
+	ACLToken        string `json:"aclToken"`
+	Scheme          string `json:"scheme"`
+	QueryTags       string `json:"queryTags"`
+	SelfRegister    string `json:"selfRegister"`
+	Tags            string `json:"tags"`
+	CheckHTTP       string `json:"checkHTTP"`
+	CheckInterval   string `json:"checkInterval"`
+	CheckTimeout    string `json:"checkTimeout"`
+	DeregisterAfter string `json:"deregisterAfter"`
+	WaitTime        string `json:"waitTime"`
+	IdleTimeout     string `json:"idleTimeout"`
+}
+
+// NewConsulResolver creates a Consul service discovery resolver.
+func NewConsulResolver(logger logger.Logger) servicediscovery.Resolver {
+	return &resolver{
+		logger:      logger,
+		waitTime:    defaultWaitTime,
+		idleTimeout: defaultIdleTimeout,
+		cache:       map[string]*serviceWatch{},
+	}
+}
+
+type resolver struct {
+	logger      logger.Logger
+	client      *api.Client
+	datacenter  string
+	queryTags   []string
+	waitTime    time.Duration
+	idleTimeout time.Duration
+	serviceID   string
+
+	lock   sync.Mutex
+	cache  map[string]*serviceWatch
+	ctx    context.Context
+	cancel context.CancelFunc
+}
+
+// serviceWatch holds the healthy endpoints of an app, kept up to date with blocking queries.
+// ready is closed once the first query finished, err holds its error.
+type serviceWatch struct {
+	ready    chan struct{}
+	err      error
+	lastUsed time.Time
+
+	lock      sync.RWMutex
+	endpoints []servicediscovery.Endpoint
+	counter   uint32
+}
+
+// Init creates the Consul client and registers the app with a health check when its ID and port are set.
+func (c *resolver) Init(metadata servicediscovery.Metadata) error {
+	config, err := metadataToConfig(metadata.Properties)
+	if err != nil {
+		return fmt.Errorf("couldn't convert metadata properties: %s", err)
+	}
+
+	client, err := api.NewClient(&api.Config{
+		Datacenter: config.Datacenter,
+		Address:    config.HTTPAddr,
+		Token:      config.ACLToken,
+		Scheme:     config.Scheme,
+	})
+	if err != nil {
+		return errors.Wrap(err, "initializing consul client")
+	}
+
+	if config.WaitTime != "" {
+		d, err := time.ParseDuration(config.WaitTime)
+		if err != nil || d <= 0 {
+			return fmt.Errorf("consul resolver: waitTime must be a positive duration, got %s", config.WaitTime)
+		}
+		c.waitTime = d
+	}
+	if config.IdleTimeout != "" {
+		d, err := time.ParseDuration(config.IdleTimeout)
+		if err != nil || d <= 0 {
+			return fmt.Errorf("consul resolver: idleTimeout must be a positive duration, got %s", config.IdleTimeout)
+		}
+		c.idleTimeout = d
+	}
+
+	c.client = client
+	c.datacenter = config.Datacenter
+	c.queryTags = splitList(config.QueryTags)
+	c.ctx, c.cancel = context.WithCancel(context.Background())
+
+	selfRegister := true
+	if config.SelfRegister != "" {
+		selfRegister, err = strconv.ParseBool(config.SelfRegister)
+		if err != nil {
+			return fmt.Errorf("consul resolver: invalid selfRegister value %s: %s", config.SelfRegister, err)
+		}
+	}
+
+	registration, err := getRegistration(metadata.Properties, config)
+	if err != nil {
+		return err
+	}
+	if !selfRegister || registration == nil {
+		return nil
+	}
+
+	if err = client.Agent().ServiceRegister(registration); err != nil {
+		return errors.Wrap(err, "registering with consul")
+	}
+	c.serviceID = registration.ID
+	c.logger.Infof("consul resolver: registered %s", registration.ID)
+	return nil
+}
+
+// Close stops watching services and deregisters the app.
+func (c *resolver) Close() error {
+	if c.cancel != nil {
+		c.cancel()
+	}
+	c.lock.Lock()
+	c.cache = map[string]*serviceWatch{}
+	c.lock.Unlock()
+	if c.serviceID == "" {
+		return nil
+	}
+
+	err := c.client.Agent().ServiceDeregister(c.serviceID)
+	c.serviceID = ""
+	return err
+}
+
+// ResolveID returns the healthy instances of an app round-robin.
+func (c *resolver) ResolveID(req servicediscovery.ResolveRequest) (string, error) {
+	watch, err := c.watch(req.ID, c.requestDatacenter(req))
+	if err != nil {
+		return "", err
+	}
+
+	watch.lock.RLock()
+	defer watch.lock.RUnlock()
+
+	if len(watch.endpoints) == 0 {
+		return "", fmt.Errorf("no healthy instances of service: %s", req.ID)
+	}
+	i := atomic.AddUint32(&watch.counter, 1) - 1
+	return watch.endpoints[i%uint32(len(watch.endpoints))].Address, nil
+}
+
+// ResolveEndpoints returns every healthy instance of an app.
+func (c *resolver) ResolveEndpoints(req servicediscovery.ResolveRequest) ([]servicediscovery.Endpoint, error) {
+	watch, err := c.watch(req.ID, c.requestDatacenter(req))
+	if err != nil {
+		return nil, err
+	}
+
+	watch.lock.RLock()
+	defer watch.lock.RUnlock()
+
+	if len(watch.endpoints) == 0 {
+		return nil, fmt.Errorf("no healthy instances of service: %s", req.ID)
+	}
+	endpoints := make([]servicediscovery.Endpoint, len(watch.endpoints))
+	copy(endpoints, watch.endpoints)
+	return endpoints, nil
+}
+
+// requestDatacenter returns the datacenter requested in the data of req, or the configured one
+func (c *resolver) requestDatacenter(req servicediscovery.ResolveRequest) string {
+	if dc := req.Data[DatacenterKey]; dc != "" {
+		return dc
+	}
+	return c.datacenter
+}
+
+// watch returns the cached instances of an app. The first lookup of an app queries
+// Consul and starts a blocking query loop that keeps the cache up to date. The query
+// runs outside the resolver lock, so a slow app doesn't delay lookups of other apps,
+// and concurrent first lookups of an app wait for the same query.
+func (c *resolver) watch(id string, datacenter string) (*serviceWatch, error) {
+	if c.client == nil {
+		return nil, errors.New("consul resolver is not initialized")
+	}
+
+	key := datacenter + "/" + id
+	c.lock.Lock()
+	watch, ok := c.cache[key]
+	if !ok {
+		watch = &serviceWatch{ready: make(chan struct{})}
+		c.cache[key] = watch
+	}
+	watch.lastUsed = time.Now()
+	c.lock.Unlock()
+
+	if ok {
+		<-watch.ready
+		if watch.err != nil {
+			return nil, watch.err
+		}
+		return watch, nil
+	}
+
+	endpoints, index, err := c.query(c.ctx, id, datacenter, 0)
+	if err != nil {
+		// Drop the failed entry so the next lookup queries Consul again
+		c.lock.Lock()
+		if c.cache[key] == watch {
+			delete(c.cache, key)
+		}
+		c.lock.Unlock()
+		watch.err = err
+		close(watch.ready)
+		return nil, err
+	}
+
+	watch.endpoints = endpoints
+	close(watch.ready)
+	go c.watchLoop(key, id, datacenter, watch, index)
+	return watch, nil
+}
+
+// evictIdle removes the watch of an app that wasn't resolved within the idle
+// timeout from the cache, and returns true when it was removed
+func (c *resolver) evictIdle(key string, watch *serviceWatch) bool {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+
+	if c.cache[key] != watch {
+		return true
+	}
+	if time.Since(watch.lastUsed) < c.idleTimeout {
+		return false
+	}
+	delete(c.cache, key)
+	return true
+}
+
+// watchLoop runs blocking queries for the instances of an app until the resolver
+// is closed or the app isn't resolved within the idle timeout
+func (c *resolver) watchLoop(key string, id string, datacenter string, watch *serviceWatch, index uint64) {
+	for !c.evictIdle(key, watch) {
+		endpoints, newIndex, err := c.query(c.ctx, id, datacenter, index)
+		if c.ctx.Err() != nil {
+			return
+		}
+		if err != nil {
+			c.logger.Warnf("consul resolver: failed to watch %s: %s", id, err)
+			select {
+			case <-time.After(retryInterval):
+			case <-c.ctx.Done():
+				return
+			}
+			continue
+		}
+
+		// The index can go backwards when Consul restarts, start over in that case
+		if newIndex < index {
+			newIndex = 0
+		}
+		index = newIndex
+
+		watch.lock.Lock()
+		watch.endpoints = endpoints
+		watch.lock.Unlock()
+	}
+	c.logger.Debugf("consul resolver: stopped watching idle app %s", id)
+}
+
+// query returns the healthy instances of an app that have all the query tags,
+// blocking until the index changes when index is not 0
+func (c *resolver) query(ctx context.Context, id string, datacenter string, index uint64) ([]servicediscovery.Endpoint, uint64, error) {
+	options := &api.QueryOptions{
+		Datacenter: datacenter,
+		WaitIndex:  index,
+		WaitTime:   c.waitTime,
+	}
+	entries, meta, err := c.client.Health().Service(id, "", true, options.WithContext(ctx))
+	if err != nil {
+		return nil, 0, errors.Wrapf(err, "querying consul for %s", id)
+	}
+
+	endpoints := make([]servicediscovery.Endpoint, 0, len(entries))
+	for _, entry := range entries {
+		if !hasTags(entry.Service.Tags, c.queryTags) {
+			continue
+		}
+		endpoints = append(endpoints, entryEndpoint(entry))
+	}
+	return endpoints, meta.LastIndex, nil
+}
+
+// entryEndpoint converts a Consul health entry to an endpoint. The service address
+// falls back to the node address, as Consul does for DNS.
+func entryEndpoint(entry *api.ServiceEntry) servicediscovery.Endpoint {
+	addr := entry.Service.Address
+	if addr == "" {
+		addr = entry.Node.Address
+	}
+
+	endpoint := servicediscovery.NewEndpoint(net.JoinHostPort(addr, strconv.Itoa(entry.Service.Port)))
+	endpoint.Metadata = entry.Service.Meta
+	endpoint.Zone = entry.Service.Meta[servicediscovery.ZoneKey]
+	if entry.Service.Weights.Passing > 0 {
+		endpoint.Weight = entry.Service.Weights.Passing
+	}
+	return endpoint
+}
+
+// getRegistration returns the registration of the app, or nil when its ID or port aren't set
+func getRegistration(props map[string]string, config *consulConfig) (*api.AgentServiceRegistration, error) {
+	appID := props[servicediscovery.AppIDKey]
+	portStr := props[servicediscovery.DaprPortKey]
+	if appID == "" || portStr == "" {
+		return nil, nil
+	}
+	port, err := strconv.Atoi(portStr)
+	if err != nil || port <= 0 {
+		return nil, fmt.Errorf("consul resolver: invalid %s %s", servicediscovery.DaprPortKey, portStr)
+	}
+	host := props[servicediscovery.HostAddressKey]
+
+	durations := map[string]time.Duration{
+		"checkInterval":   defaultCheckInterval,
+		"checkTimeout":    defaultCheckTimeout,
+		"deregisterAfter": defaultDeregisterAfter,
+	}
+	for name, val := range map[string]string{
+		"checkInterval":   config.CheckInterval,
+		"checkTimeout":    config.CheckTimeout,
+		"deregisterAfter": config.DeregisterAfter,
+	} {
+		if val == "" {
+			continue
+		}
+		d, err := time.ParseDuration(val)
+		if err != nil || d <= 0 {
+			return nil, fmt.Errorf("consul resolver: %s must be a positive duration, got %s", name, val)
+		}
+		durations[name] = d
+	}
+
+	check := &api.AgentServiceCheck{
+		Interval:                       durations["checkInterval"].String(),
+		Timeout:                        durations["checkTimeout"].String(),
+		DeregisterCriticalServiceAfter: durations["deregisterAfter"].String(),
+	}
+	if config.CheckHTTP != "" {
+		check.HTTP = config.CheckHTTP
+	} else {
+		checkHost := host
+		if checkHost == "" {
+			checkHost = "127.0.0.1"
+		}
+		check.TCP = net.JoinHostPort(checkHost, portStr)
+	}
+
+	meta := map[string]string{}
+	if zone := props[servicediscovery.ZoneKey]; zone != "" {
+		meta[servicediscovery.ZoneKey] = zone
+	}
+
+	id := appID
+	if host != "" {
+		id = fmt.Sprintf("%s-%s-%d", appID, host, port)
+	}
+
+	return &api.AgentServiceRegistration{
+		ID:      id,
+		Name:    appID,
+		Tags:    splitList(config.Tags),
+		Port:    port,
+		Address: host,
+		Meta:    meta,
+		Check:   check,
+	}, nil
+}
+
+func hasTags(tags []string, required []string) bool {
+	for _, r := range required {
+		found := false
+		for _, t := range tags {
+			if t == r {
+				found = true
+				break
+			}
+		}
+		if !found {
+			return false
+		}
+	}
+	return true
+}
+
+func splitList(val string) []string {
+	var result []string
+	for _, s := range strings.Split(val, ",") {
+		if s = strings.TrimSpace(s); s != "" {
+			result = append(result, s)
+		}
+	}
+	return result
+}
+
+func metadataToConfig(connInfo map[string]string) (*consulConfig, error) {
+	b, err := json.Marshal(connInfo)
+	if err != nil {
+		return nil, err
+	}
+
+	var config consulConfig
+	err = json.Unmarshal(b, &config)
+	if err != nil {
+		return nil, err
+	}
+
+	return &config, nil
+}
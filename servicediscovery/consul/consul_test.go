@@ -0,0 +1,321 @@
+// ------------------------------------------------------------
+// Copyright (c) Microsoft Corporation.
+// Licensed under the MIT License.
+// ------------------------------------------------------------
+
+package consul
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/dapr/components-contrib/servicediscovery"
+	"github.com/dapr/dapr/pkg/logger"
+	"github.com/hashicorp/consul/api"
+	"github.com/stretchr/testify/assert"
+)
+
+// fakeConsul serves the subset of the Consul HTTP API used by the resolver
+type fakeConsul struct {
+	lock         sync.Mutex
+	index        uint64
+	entries      []*api.ServiceEntry
+	changed      chan struct{}
+	datacenters  []string
+	blocked      map[string]chan struct{}
+	registered   chan *api.AgentServiceRegistration
+	deregistered chan string
+}
+
+func newFakeConsul(entries ...*api.ServiceEntry) *fakeConsul {
+	return &fakeConsul{
+		index:        1,
+		entries:      entries,
+		changed:      make(chan struct{}),
+		blocked:      map[string]chan struct{}{},
+		registered:   make(chan *api.AgentServiceRegistration, 1),
+		deregistered: make(chan string, 1),
+	}
+}
+
+func (f *fakeConsul) setEntries(entries ...*api.ServiceEntry) {
+	f.lock.Lock()
+	defer f.lock.Unlock()
+
+	f.index++
+	f.entries = entries
+	close(f.changed)
+	f.changed = make(chan struct{})
+}
+
+// block holds the health queries for service until the returned func is called
+func (f *fakeConsul) block(service string) func() {
+	f.lock.Lock()
+	defer f.lock.Unlock()
+
+	unblock := make(chan struct{})
+	f.blocked[service] = unblock
+	return func() { close(unblock) }
+}
+
+func (f *fakeConsul) queriedDatacenters() []string {
+	f.lock.Lock()
+	defer f.lock.Unlock()
+
+	return append([]string{}, f.datacenters...)
+}
+
+func (f *fakeConsul) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	switch {
+	case strings.HasPrefix(r.URL.Path, "/v1/health/service/"):
+		f.lock.Lock()
+		f.datacenters = append(f.datacenters, r.URL.Query().Get("dc"))
+		index, changed := f.index, f.changed
+		blocked := f.blocked[strings.TrimPrefix(r.URL.Path, "/v1/health/service/")]
+		f.lock.Unlock()
+
+		if blocked != nil {
+			select {
+			case <-blocked:
+			case <-r.Context().Done():
+				return
+			}
+		}
+		if wait, _ := strconv.ParseUint(r.URL.Query().Get("index"), 10, 64); wait >= index {
+			waitTime, err := time.ParseDuration(r.URL.Query().Get("wait"))
+			if err != nil {
+				waitTime = time.Minute
+			}
+			select {
+			case <-changed:
+			case <-time.After(waitTime):
+			case <-r.Context().Done():
+				return
+			}
+		}
+
+		f.lock.Lock()
+		defer f.lock.Unlock()
+		w.Header().Set("X-Consul-Index", strconv.FormatUint(f.index, 10))
+		json.NewEncoder(w).Encode(f.entries) //nolint:errcheck
+	case r.URL.Path == "/v1/agent/service/register":
+		var reg api.AgentServiceRegistration
+		json.NewDecoder(r.Body).Decode(&reg) //nolint:errcheck
+		f.registered <- &reg
+	case strings.HasPrefix(r.URL.Path, "/v1/agent/service/deregister/"):
+		f.deregistered <- strings.TrimPrefix(r.URL.Path, "/v1/agent/service/deregister/")
+	default:
+		w.WriteHeader(http.StatusNotFound)
+	}
+}
+
+// waitFor polls condition until it is true or timeout expires
+func waitFor(timeout time.Duration, condition func() bool) bool {
+	deadline := time.Now().Add(timeout)
+	for time.Now().Before(deadline) {
+		if condition() {
+			return true
+		}
+		time.Sleep(time.Millisecond * 10)
+	}
+	return false
+}
+
+func serviceEntry(address string, port int, tags ...string) *api.ServiceEntry {
+	return &api.ServiceEntry{
+		Node:    &api.Node{Address: "10.0.0.100"},
+		Service: &api.AgentService{Service: "myapp", Address: address, Port: port, Tags: tags},
+	}
+}
+
+func TestRegistration(t *testing.T) {
+	fake := newFakeConsul()
+	server := httptest.NewServer(fake)
+	defer server.Close()
+
+	r := NewConsulResolver(logger.NewLogger("test")).(*resolver)
+	err := r.Init(servicediscovery.Metadata{Properties: map[string]string{
+		"httpAddr":                      strings.TrimPrefix(server.URL, "http://"),
+		"tags":                          "v1, dapr",
+		"checkInterval":                 "5s",
+		servicediscovery.AppIDKey:       "myapp",
+		servicediscovery.HostAddressKey: "10.0.0.5",
+		servicediscovery.DaprPortKey:    "50002",
+		servicediscovery.ZoneKey:        "eu-1",
+	}})
+	assert.Nil(t, err)
+
+	reg := <-fake.registered
+	assert.Equal(t, "myapp-10.0.0.5-50002", reg.ID)
+	assert.Equal(t, "myapp", reg.Name)
+	assert.Equal(t, []string{"v1", "dapr"}, reg.Tags)
+	assert.Equal(t, "10.0.0.5:50002", reg.Check.TCP)
+	assert.Equal(t, "5s", reg.Check.Interval)
+	assert.Equal(t, "eu-1", reg.Meta[servicediscovery.ZoneKey])
+
+	assert.Nil(t, r.Close())
+	assert.Equal(t, "myapp-10.0.0.5-50002", <-fake.deregistered)
+}
+
+func TestResolve(t *testing.T) {
+	fake := newFakeConsul(
+		serviceEntry("10.0.0.1", 50002, "v1"),
+		serviceEntry("", 50002, "v1"),
+		serviceEntry("10.0.0.3", 50002, "v2"),
+	)
+	server := httptest.NewServer(fake)
+	defer server.Close()
+
+	r := NewConsulResolver(logger.NewLogger("test")).(*resolver)
+	err := r.Init(servicediscovery.Metadata{Properties: map[string]string{
+		"httpAddr":   strings.TrimPrefix(server.URL, "http://"),
+		"datacenter": "dc1",
+		"queryTags":  "v1",
+	}})
+	assert.Nil(t, err)
+	defer r.Close()
+
+	req := servicediscovery.ResolveRequest{ID: "myapp"}
+	seen := map[string]bool{}
+	for i := 0; i < 4; i++ {
+		address, err := r.ResolveID(req)
+		assert.Nil(t, err)
+		seen[address] = true
+	}
+	// Instances without a service address use the node address
+	assert.Equal(t, map[string]bool{"10.0.0.1:50002": true, "10.0.0.100:50002": true}, seen)
+	assert.Equal(t, "dc1", fake.queriedDatacenters()[0])
+
+	// The blocking query picks up changes
+	fake.setEntries(serviceEntry("10.0.0.4", 50002, "v1"))
+	assert.True(t, waitFor(time.Second*5, func() bool {
+		endpoints, err := r.ResolveEndpoints(req)
+		return err == nil && len(endpoints) == 1 && endpoints[0].Address == "10.0.0.4:50002"
+	}))
+
+	// Another datacenter can be requested per call
+	_, err = r.ResolveID(servicediscovery.ResolveRequest{ID: "myapp", Data: map[string]string{DatacenterKey: "dc2"}})
+	assert.Nil(t, err)
+	assert.Contains(t, fake.queriedDatacenters(), "dc2")
+}
+
+func TestSlowAppDoesNotBlockOthers(t *testing.T) {
+	fake := newFakeConsul(serviceEntry("10.0.0.1", 50002))
+	server := httptest.NewServer(fake)
+	defer server.Close()
+
+	r := NewConsulResolver(logger.NewLogger("test")).(*resolver)
+	err := r.Init(servicediscovery.Metadata{Properties: map[string]string{
+		"httpAddr": strings.TrimPrefix(server.URL, "http://"),
+	}})
+	assert.Nil(t, err)
+	defer r.Close()
+
+	unblock := fake.block("slowapp")
+	slow := make(chan error, 2)
+	for i := 0; i < 2; i++ {
+		go func() {
+			_, err := r.ResolveID(servicediscovery.ResolveRequest{ID: "slowapp"})
+			slow <- err
+		}()
+	}
+
+	// Other apps resolve while the first query of slowapp is pending
+	resolved := make(chan error, 1)
+	go func() {
+		_, err := r.ResolveID(servicediscovery.ResolveRequest{ID: "myapp"})
+		resolved <- err
+	}()
+	select {
+	case err := <-resolved:
+		assert.Nil(t, err)
+	case <-time.After(time.Second * 5):
+		t.Fatal("resolving myapp waited for slowapp")
+	}
+
+	// Both lookups of slowapp share the pending query
+	unblock()
+	for i := 0; i < 2; i++ {
+		assert.Nil(t, <-slow)
+	}
+}
+
+func TestIdleWatchesAreEvicted(t *testing.T) {
+	fake := newFakeConsul(serviceEntry("10.0.0.1", 50002))
+	server := httptest.NewServer(fake)
+	defer server.Close()
+
+	r := NewConsulResolver(logger.NewLogger("test")).(*resolver)
+	err := r.Init(servicediscovery.Metadata{Properties: map[string]string{
+		"httpAddr":    strings.TrimPrefix(server.URL, "http://"),
+		"waitTime":    "50ms",
+		"idleTimeout": "100ms",
+	}})
+	assert.Nil(t, err)
+	defer r.Close()
+
+	_, err = r.ResolveID(servicediscovery.ResolveRequest{ID: "myapp"})
+	assert.Nil(t, err)
+
+	cached := func() int {
+		r.lock.Lock()
+		defer r.lock.Unlock()
+		return len(r.cache)
+	}
+	assert.Equal(t, 1, cached())
+	assert.True(t, waitFor(time.Second*5, func() bool { return cached() == 0 }))
+
+	// An evicted app is watched again on its next lookup
+	_, err = r.ResolveID(servicediscovery.ResolveRequest{ID: "myapp"})
+	assert.Nil(t, err)
+	assert.Equal(t, 1, cached())
+}
+
+func TestInvalidMetadata(t *testing.T) {
+	invalid := map[string]map[string]string{
+		"wait time":     {"waitTime": "forever"},
+		"idle timeout":  {"idleTimeout": "0s"},
+		"self register": {"selfRegister": "maybe"},
+		"port":          {servicediscovery.AppIDKey: "myapp", servicediscovery.DaprPortKey: "http"},
+		"interval":      {servicediscovery.AppIDKey: "myapp", servicediscovery.DaprPortKey: "50002", "checkInterval": "0s"},
+	}
+	for name, props := range invalid {
+		t.Run(name, func(t *testing.T) {
+			r := NewConsulResolver(logger.NewLogger("test"))
+			assert.NotNil(t, servicediscovery.Init(r, servicediscovery.Metadata{Properties: props}))
+		})
+	}
+}
+
+// TestDevAgent runs against a Consul dev agent, started with `consul agent -dev`,
+// when DAPR_TEST_CONSUL_HTTP_ADDR is set to its HTTP address.
+func TestDevAgent(t *testing.T) {
+	addr := os.Getenv("DAPR_TEST_CONSUL_HTTP_ADDR")
+	if addr == "" {
+		t.Skip("DAPR_TEST_CONSUL_HTTP_ADDR is not set")
+	}
+
+	r := NewConsulResolver(logger.NewLogger("test")).(*resolver)
+	err := r.Init(servicediscovery.Metadata{Properties: map[string]string{
+		"httpAddr":                      addr,
+		"checkHTTP":                     "http://" + addr + "/v1/status/leader",
+		"checkInterval":                 "1s",
+		servicediscovery.AppIDKey:       "dapr-consul-test",
+		servicediscovery.HostAddressKey: "127.0.0.1",
+		servicediscovery.DaprPortKey:    "50002",
+	}})
+	assert.Nil(t, err)
+	defer r.Close()
+
+	assert.True(t, waitFor(time.Second*30, func() bool {
+		address, err := r.ResolveID(servicediscovery.ResolveRequest{ID: "dapr-consul-test"})
+		return err == nil && address == "127.0.0.1:50002"
+	}))
+}
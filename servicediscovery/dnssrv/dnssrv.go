@@ -0,0 +1,363 @@
+// ------------------------------------------------------------
+// Copyright (c) Microsoft Corporation.
+// Licensed under the MIT License.
+// ------------------------------------------------------------
+
+package dnssrv
+
+import (
+	"encoding/json"
+	"fmt"
+	"math"
+	"net"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/dapr/components-contrib/servicediscovery"
+	"github.com/dapr/dapr/pkg/logger"
+	"github.com/miekg/dns"
+	"github.com/pkg/errors"
+)
+
+const (
+	resolvConfPath = "/etc/resolv.conf"
+
+	// Defaults
+	defaultTimeout = time.Second * 2
+	defaultDNSPort = "53"
+)
+
+type dnsConfig struct {
+	Domain       string `json:"domain"`
+	Server       string `json:"server"`
+	FallbackPort string `json:"fallbackPort"`
+	Timeout      string `json:"timeout"`
+}
+
+// NewDNSResolver creates a DNS SRV service discovery resolver.
+func NewDNSResolver(logger logger.Logger) servicediscovery.Resolver {
+	return &resolver{
+		logger: logger,
+		cache:  map[string]*cacheEntry{},
+	}
+}
+
+type resolver struct {
+	logger       logger.Logger
+	domain       string
+	server       string
+	fallbackPort int
+	udp          *dns.Client
+	tcp          *dns.Client
+
+	lock  sync.Mutex
+	cache map[string]*cacheEntry
+}
+
+// cacheEntry holds the endpoints of an app until the shortest TTL of their records expires
+type cacheEntry struct {
+	endpoints []servicediscovery.Endpoint
+	expires   time.Time
+}
+
+// addresses holds the IPs of a host and the shortest TTL of their records
+type addresses struct {
+	ips []string
+	ttl uint32
+}
+
+// Init configures the domain apps are looked up in and the DNS server to query.
+// Both default to the system resolver configuration.
+func (d *resolver) Init(metadata servicediscovery.Metadata) error {
+	config, err := metadataToConfig(metadata.Properties)
+	if err != nil {
+		return fmt.Errorf("couldn't convert metadata properties: %s", err)
+	}
+
+	timeout := defaultTimeout
+	if config.Timeout != "" {
+		timeout, err = time.ParseDuration(config.Timeout)
+		if err != nil || timeout <= 0 {
+			return fmt.Errorf("dns resolver: timeout must be a positive duration, got %s", config.Timeout)
+		}
+	}
+
+	if config.FallbackPort != "" {
+		port, err := strconv.Atoi(config.FallbackPort)
+		if err != nil || port <= 0 || port > math.MaxUint16 {
+			return fmt.Errorf("dns resolver: invalid fallbackPort %s", config.FallbackPort)
+		}
+		d.fallbackPort = port
+	}
+
+	domain, server := config.Domain, config.Server
+	if domain == "" || server == "" {
+		clientConfig, err := dns.ClientConfigFromFile(resolvConfPath)
+		if err != nil {
+			return fmt.Errorf("dns resolver: domain or server isn't set and %s can't be read: %s", resolvConfPath, err)
+		}
+		if domain == "" && len(clientConfig.Search) > 0 {
+			domain = clientConfig.Search[0]
+		}
+		if server == "" && len(clientConfig.Servers) > 0 {
+			server = net.JoinHostPort(clientConfig.Servers[0], clientConfig.Port)
+		}
+	}
+
+	domain = strings.Trim(domain, ".")
+	if domain == "" {
+		return errors.New("dns resolver: domain is required")
+	}
+	if server == "" {
+		return errors.New("dns resolver: server is required")
+	}
+	if _, _, err := net.SplitHostPort(server); err != nil {
+		server = net.JoinHostPort(server, defaultDNSPort)
+	}
+
+	d.domain = domain
+	d.server = server
+	d.udp = &dns.Client{Net: "udp", Timeout: timeout}
+	d.tcp = &dns.Client{Net: "tcp", Timeout: timeout}
+	return nil
+}
+
+// ResolveID returns an instance of an app, chosen at random in proportion to the
+// weight of its SRV record.
+func (d *resolver) ResolveID(req servicediscovery.ResolveRequest) (string, error) {
+	endpoints, err := d.ResolveEndpoints(req)
+	if err != nil {
+		return "", err
+	}
+	return servicediscovery.PickWeighted(endpoints).Address, nil
+}
+
+// ResolveEndpoints returns the instances of an app from the lowest priority SRV
+// records of _<app id>._tcp.<domain>. Higher priorities are backups for
+// unreachable targets, which DNS doesn't know about, so they aren't returned.
+// Without SRV records, the A and AAAA records of <app id>.<domain> are used with
+// the fallback port, or the port of the request when none is configured.
+// Results are cached for the shortest TTL of the records they come from.
+func (d *resolver) ResolveEndpoints(req servicediscovery.ResolveRequest) ([]servicediscovery.Endpoint, error) {
+	if d.udp == nil {
+		return nil, errors.New("dns resolver is not initialized")
+	}
+
+	key := fmt.Sprintf("%s:%d", req.ID, req.Port)
+	d.lock.Lock()
+	entry, ok := d.cache[key]
+	d.lock.Unlock()
+	if ok && time.Now().Before(entry.expires) {
+		return copyEndpoints(entry.endpoints), nil
+	}
+
+	endpoints, ttl, err := d.lookup(req)
+	if err != nil {
+		return nil, err
+	}
+
+	d.lock.Lock()
+	if ttl > 0 {
+		d.cache[key] = &cacheEntry{endpoints: endpoints, expires: time.Now().Add(time.Duration(ttl) * time.Second)}
+	} else {
+		delete(d.cache, key)
+	}
+	d.lock.Unlock()
+	return copyEndpoints(endpoints), nil
+}
+
+// lookup queries the SRV records of an app, falling back to its address records,
+// and returns its endpoints with the shortest TTL of the records used
+func (d *resolver) lookup(req servicediscovery.ResolveRequest) ([]servicediscovery.Endpoint, uint32, error) {
+	name := fmt.Sprintf("_%s._tcp.%s", req.ID, d.domain)
+	res, err := d.query(name, dns.TypeSRV)
+	if err != nil {
+		return nil, 0, err
+	}
+	if endpoints, ttl := d.srvEndpoints(res); len(endpoints) > 0 {
+		return endpoints, ttl, nil
+	}
+
+	port := d.fallbackPort
+	if port == 0 {
+		port = req.Port
+	}
+	if port <= 0 {
+		return nil, 0, fmt.Errorf("couldn't find SRV records %s and no fallback port is set", name)
+	}
+
+	host := fmt.Sprintf("%s.%s", req.ID, d.domain)
+	addrs, err := d.lookupHost(host)
+	if err != nil {
+		return nil, 0, err
+	}
+	if len(addrs.ips) == 0 {
+		return nil, 0, fmt.Errorf("couldn't find service: %s", req.ID)
+	}
+
+	endpoints := make([]servicediscovery.Endpoint, 0, len(addrs.ips))
+	for _, ip := range addrs.ips {
+		endpoints = append(endpoints, servicediscovery.NewEndpoint(net.JoinHostPort(ip, strconv.Itoa(port))))
+	}
+	return endpoints, addrs.ttl, nil
+}
+
+// srvEndpoints returns an endpoint for each lowest priority SRV record of res.
+// Targets are resolved from the additional section when the server included
+// their addresses, and with a separate query otherwise.
+func (d *resolver) srvEndpoints(res *dns.Msg) ([]servicediscovery.Endpoint, uint32) {
+	var records []*dns.SRV
+	ttl := uint32(math.MaxUint32)
+	for _, rr := range res.Answer {
+		// A target of "." means the service is decidedly not available
+		if srv, ok := rr.(*dns.SRV); ok && srv.Target != "." {
+			records = append(records, srv)
+			ttl = minTTL(ttl, srv.Hdr.Ttl)
+		}
+	}
+	if len(records) == 0 {
+		return nil, 0
+	}
+
+	best := records[0].Priority
+	for _, srv := range records[1:] {
+		if srv.Priority < best {
+			best = srv.Priority
+		}
+	}
+
+	extra := addressRecords(res.Extra)
+	endpoints := make([]servicediscovery.Endpoint, 0, len(records))
+	for _, srv := range records {
+		if srv.Priority != best {
+			continue
+		}
+
+		host := strings.TrimSuffix(srv.Target, ".")
+		addrs, ok := extra[strings.ToLower(srv.Target)]
+		if !ok {
+			var err error
+			if addrs, err = d.lookupHost(srv.Target); err != nil {
+				d.logger.Warnf("dns resolver: failed to resolve %s: %s", host, err)
+			}
+		}
+		// Unresolved targets are left for the dialer to resolve
+		if len(addrs.ips) > 0 {
+			host = addrs.ips[0]
+			ttl = minTTL(ttl, addrs.ttl)
+		}
+
+		endpoint := servicediscovery.NewEndpoint(net.JoinHostPort(host, strconv.Itoa(int(srv.Port))))
+		endpoint.Weight = int(srv.Weight)
+		endpoints = append(endpoints, endpoint)
+	}
+	return endpoints, ttl
+}
+
+// lookupHost returns the IPv4 addresses of a host, or its IPv6 addresses when it has none
+func (d *resolver) lookupHost(host string) (addresses, error) {
+	for _, qtype := range []uint16{dns.TypeA, dns.TypeAAAA} {
+		res, err := d.query(host, qtype)
+		if err != nil {
+			return addresses{}, err
+		}
+		if addrs := addressRecords(res.Answer)[strings.ToLower(dns.Fqdn(host))]; len(addrs.ips) > 0 {
+			// Any CNAME the answer went through expires with it
+			for _, rr := range res.Answer {
+				addrs.ttl = minTTL(addrs.ttl, rr.Header().Ttl)
+			}
+			return addrs, nil
+		}
+	}
+	return addresses{}, nil
+}
+
+// query sends a question to the DNS server, over TCP when the UDP answer is truncated
+func (d *resolver) query(name string, qtype uint16) (*dns.Msg, error) {
+	msg := new(dns.Msg)
+	msg.SetQuestion(dns.Fqdn(name), qtype)
+
+	res, _, err := d.udp.Exchange(msg, d.server)
+	if err == nil && res.Truncated {
+		res, _, err = d.tcp.Exchange(msg, d.server)
+	}
+	if err != nil {
+		return nil, errors.Wrapf(err, "querying %s for %s", d.server, name)
+	}
+	if res.Rcode != dns.RcodeSuccess && res.Rcode != dns.RcodeNameError {
+		return nil, fmt.Errorf("querying %s for %s: %s", d.server, name, dns.RcodeToString[res.Rcode])
+	}
+	return res, nil
+}
+
+// addressRecords groups the A and AAAA records of rrs by lower case owner name.
+// A CNAME answer is attributed to the name it was asked for.
+func addressRecords(rrs []dns.RR) map[string]addresses {
+	aliases := map[string]string{}
+	for _, rr := range rrs {
+		if cname, ok := rr.(*dns.CNAME); ok {
+			aliases[strings.ToLower(cname.Target)] = strings.ToLower(cname.Hdr.Name)
+		}
+	}
+
+	result := map[string]addresses{}
+	for _, rr := range rrs {
+		var ip net.IP
+		switch r := rr.(type) {
+		case *dns.A:
+			ip = r.A
+		case *dns.AAAA:
+			ip = r.AAAA
+		default:
+			continue
+		}
+
+		name := strings.ToLower(rr.Header().Name)
+		for i := 0; i < len(aliases); i++ {
+			alias, ok := aliases[name]
+			if !ok {
+				break
+			}
+			name = alias
+		}
+
+		addrs, ok := result[name]
+		if !ok {
+			addrs.ttl = math.MaxUint32
+		}
+		addrs.ips = append(addrs.ips, ip.String())
+		addrs.ttl = minTTL(addrs.ttl, rr.Header().Ttl)
+		result[name] = addrs
+	}
+	return result
+}
+
+func copyEndpoints(endpoints []servicediscovery.Endpoint) []servicediscovery.Endpoint {
+	result := make([]servicediscovery.Endpoint, len(endpoints))
+	copy(result, endpoints)
+	return result
+}
+
+func minTTL(a, b uint32) uint32 {
+	if a < b {
+		return a
+	}
+	return b
+}
+
+func metadataToConfig(connInfo map[string]string) (*dnsConfig, error) {
+	b, err := json.Marshal(connInfo)
+	if err != nil {
+		return nil, err
+	}
+
+	var config dnsConfig
+	err = json.Unmarshal(b, &config)
+	if err != nil {
+		return nil, err
+	}
+
+	return &config, nil
+}
@@ -0,0 +1,205 @@
+// ------------------------------------------------------------
+// Copyright (c) Microsoft Corporation.
+// Licensed under the MIT License.
+// ------------------------------------------------------------
+
+package dnssrv
+
+import (
+	"net"
+	"sync"
+	"testing"
+
+	"github.com/dapr/components-contrib/servicediscovery"
+	"github.com/dapr/dapr/pkg/logger"
+	"github.com/miekg/dns"
+	"github.com/stretchr/testify/assert"
+)
+
+// dnsStub answers questions from records keyed by "<name> <type>"
+type dnsStub struct {
+	lock    sync.Mutex
+	answers map[string][]string
+	extra   map[string][]string
+	queries map[string]int
+}
+
+func newDNSStub() *dnsStub {
+	return &dnsStub{answers: map[string][]string{}, extra: map[string][]string{}, queries: map[string]int{}}
+}
+
+func (s *dnsStub) ServeDNS(w dns.ResponseWriter, r *dns.Msg) {
+	q := r.Question[0]
+	key := q.Name + " " + dns.TypeToString[q.Qtype]
+
+	s.lock.Lock()
+	s.queries[key]++
+	answers, extra := s.answers[key], s.extra[key]
+	s.lock.Unlock()
+
+	res := new(dns.Msg)
+	res.SetReply(r)
+	if len(answers) == 0 {
+		res.Rcode = dns.RcodeNameError
+	}
+	for _, a := range answers {
+		rr, _ := dns.NewRR(a)
+		res.Answer = append(res.Answer, rr)
+	}
+	for _, e := range extra {
+		rr, _ := dns.NewRR(e)
+		res.Extra = append(res.Extra, rr)
+	}
+	w.WriteMsg(res) //nolint:errcheck
+}
+
+func (s *dnsStub) queryCount(key string) int {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	return s.queries[key]
+}
+
+// start serves the stub on a local UDP port and returns its address
+func (s *dnsStub) start(t *testing.T) (string, func()) {
+	pc, err := net.ListenPacket("udp", "127.0.0.1:0")
+	assert.Nil(t, err)
+
+	started := make(chan struct{})
+	server := &dns.Server{PacketConn: pc, Handler: s, NotifyStartedFunc: func() { close(started) }}
+	go server.ActivateAndServe() //nolint:errcheck
+	<-started
+
+	return pc.LocalAddr().String(), func() { server.Shutdown() } //nolint:errcheck
+}
+
+func newTestResolver(t *testing.T, server string, props map[string]string) *resolver {
+	metadata := map[string]string{"domain": "example.com", "server": server}
+	for k, v := range props {
+		metadata[k] = v
+	}
+	r := NewDNSResolver(logger.NewLogger("test")).(*resolver)
+	assert.Nil(t, r.Init(servicediscovery.Metadata{Properties: metadata}))
+	return r
+}
+
+func TestResolveSRV(t *testing.T) {
+	stub := newDNSStub()
+	stub.answers["_myapp._tcp.example.com. SRV"] = []string{
+		"_myapp._tcp.example.com. 60 IN SRV 10 3 50002 a.example.com.",
+		"_myapp._tcp.example.com. 60 IN SRV 10 1 50003 b.example.com.",
+		"_myapp._tcp.example.com. 60 IN SRV 20 1 50004 c.example.com.",
+	}
+	stub.extra["_myapp._tcp.example.com. SRV"] = []string{"a.example.com. 60 IN A 10.0.0.1"}
+	stub.answers["b.example.com. A"] = []string{"b.example.com. 60 IN A 10.0.0.2"}
+	stub.answers["c.example.com. A"] = []string{"c.example.com. 60 IN A 10.0.0.3"}
+	addr, stop := stub.start(t)
+	defer stop()
+
+	r := newTestResolver(t, addr, nil)
+	req := servicediscovery.ResolveRequest{ID: "myapp"}
+
+	endpoints, err := r.ResolveEndpoints(req)
+	assert.Nil(t, err)
+	assert.Len(t, endpoints, 2)
+	assert.Equal(t, "10.0.0.1:50002", endpoints[0].Address)
+	assert.Equal(t, 3, endpoints[0].Weight)
+	assert.Equal(t, "10.0.0.2:50003", endpoints[1].Address)
+	assert.Equal(t, 1, endpoints[1].Weight)
+	// The target in the additional section isn't queried again
+	assert.Equal(t, 0, stub.queryCount("a.example.com. A"))
+
+	seen := map[string]bool{}
+	for i := 0; i < 50; i++ {
+		address, err := r.ResolveID(req)
+		assert.Nil(t, err)
+		seen[address] = true
+	}
+	assert.Equal(t, map[string]bool{"10.0.0.1:50002": true, "10.0.0.2:50003": true}, seen)
+	// Records are cached for their TTL
+	assert.Equal(t, 1, stub.queryCount("_myapp._tcp.example.com. SRV"))
+}
+
+func TestZeroTTLIsNotCached(t *testing.T) {
+	stub := newDNSStub()
+	stub.answers["_myapp._tcp.example.com. SRV"] = []string{"_myapp._tcp.example.com. 0 IN SRV 10 1 50002 a.example.com."}
+	stub.answers["a.example.com. A"] = []string{"a.example.com. 60 IN A 10.0.0.1"}
+	addr, stop := stub.start(t)
+	defer stop()
+
+	r := newTestResolver(t, addr, nil)
+	for i := 0; i < 2; i++ {
+		address, err := r.ResolveID(servicediscovery.ResolveRequest{ID: "myapp"})
+		assert.Nil(t, err)
+		assert.Equal(t, "10.0.0.1:50002", address)
+	}
+	assert.Equal(t, 2, stub.queryCount("_myapp._tcp.example.com. SRV"))
+}
+
+func TestFallbackToAddressRecords(t *testing.T) {
+	stub := newDNSStub()
+	stub.answers["myapp.example.com. AAAA"] = []string{"myapp.example.com. 60 IN AAAA fd00::1"}
+	addr, stop := stub.start(t)
+	defer stop()
+
+	t.Run("fallback port", func(t *testing.T) {
+		r := newTestResolver(t, addr, map[string]string{"fallbackPort": "50002"})
+		address, err := r.ResolveID(servicediscovery.ResolveRequest{ID: "myapp", Port: 3500})
+		assert.Nil(t, err)
+		assert.Equal(t, "[fd00::1]:50002", address)
+	})
+
+	t.Run("request port", func(t *testing.T) {
+		r := newTestResolver(t, addr, nil)
+		address, err := r.ResolveID(servicediscovery.ResolveRequest{ID: "myapp", Port: 3500})
+		assert.Nil(t, err)
+		assert.Equal(t, "[fd00::1]:3500", address)
+	})
+
+	t.Run("no port", func(t *testing.T) {
+		r := newTestResolver(t, addr, nil)
+		_, err := r.ResolveID(servicediscovery.ResolveRequest{ID: "myapp"})
+		assert.NotNil(t, err)
+	})
+
+	t.Run("unknown app", func(t *testing.T) {
+		r := newTestResolver(t, addr, map[string]string{"fallbackPort": "50002"})
+		_, err := r.ResolveID(servicediscovery.ResolveRequest{ID: "otherapp"})
+		assert.NotNil(t, err)
+	})
+}
+
+func TestPickWeighted(t *testing.T) {
+	endpoints := []servicediscovery.Endpoint{
+		{Address: "a", Weight: 0},
+		{Address: "b", Weight: 5},
+	}
+	for i := 0; i < 20; i++ {
+		assert.Equal(t, "b", servicediscovery.PickWeighted(endpoints).Address)
+	}
+
+	endpoints[1].Weight = 0
+	seen := map[string]bool{}
+	for i := 0; i < 100; i++ {
+		seen[servicediscovery.PickWeighted(endpoints).Address] = true
+	}
+	assert.Len(t, seen, 2)
+}
+
+func TestInvalidMetadata(t *testing.T) {
+	invalid := map[string]map[string]string{
+		"timeout":       {"domain": "example.com", "server": "127.0.0.1", "timeout": "0s"},
+		"fallback port": {"domain": "example.com", "server": "127.0.0.1", "fallbackPort": "70000"},
+	}
+	for name, props := range invalid {
+		t.Run(name, func(t *testing.T) {
+			r := NewDNSResolver(logger.NewLogger("test"))
+			assert.NotNil(t, servicediscovery.Init(r, servicediscovery.Metadata{Properties: props}))
+		})
+	}
+
+	r := NewDNSResolver(logger.NewLogger("test")).(*resolver)
+	assert.Nil(t, r.Init(servicediscovery.Metadata{Properties: map[string]string{"domain": "example.com.", "server": "127.0.0.1"}}))
+	assert.Equal(t, "example.com", r.domain)
+	assert.Equal(t, "127.0.0.1:53", r.server)
+}
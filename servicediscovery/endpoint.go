@@ -0,0 +1,76 @@
+// ------------------------------------------------------------
+// Copyright (c) Microsoft Corporation.
+// Licensed under the MIT License.
+// ------------------------------------------------------------
+
+package servicediscovery
+
+import "math/rand"
+
+// Endpoint is a resolved instance of an app.
+type Endpoint struct {
+	// Address is the host:port the instance can be reached on
+	Address string
+	// Zone is the failure domain of the instance, empty when unknown
+	Zone string
+	// Weight is the relative share of traffic the instance should receive
+	Weight int
+	// Healthy is false when the resolver knows the instance can't serve requests
+	Healthy bool
+	// Metadata holds additional resolver specific properties of the instance
+	Metadata map[string]string
+}
+
+// EndpointResolver is a resolver that returns every instance of an app.
+type EndpointResolver interface {
+	Resolver
+	ResolveEndpoints(req ResolveRequest) ([]Endpoint, error)
+}
+
+// NewEndpoint creates a healthy endpoint with the default weight.
+func NewEndpoint(address string) Endpoint {
+	return Endpoint{Address: address, Weight: 1, Healthy: true}
+}
+
+// ResolveEndpoints returns the endpoints of an app using the richer interface when
+// the resolver implements it, and the single address from ResolveID otherwise.
+func ResolveEndpoints(resolver Resolver, req ResolveRequest) ([]Endpoint, error) {
+	if r, ok := resolver.(EndpointResolver); ok {
+		return r.ResolveEndpoints(req)
+	}
+
+	address, err := resolver.ResolveID(req)
+	if err != nil {
+		return nil, err
+	}
+	return []Endpoint{NewEndpoint(address)}, nil
+}
+
+// PickWeighted chooses an endpoint at random in proportion to its weight. As for SRV
+// records (RFC 2782), endpoints with a weight of 0 are only chosen when every weight is 0.
+func PickWeighted(endpoints []Endpoint) Endpoint {
+	total := 0
+	for _, e := range endpoints {
+		total += weightOf(e)
+	}
+	if total == 0 {
+		return endpoints[rand.Intn(len(endpoints))] //nolint:gosec
+	}
+
+	n := rand.Intn(total) //nolint:gosec
+	for _, e := range endpoints {
+		if n < weightOf(e) {
+			return e
+		}
+		n -= weightOf(e)
+	}
+	return endpoints[len(endpoints)-1]
+}
+
+// weightOf returns the weight of an endpoint, treating negative weights as 0
+func weightOf(e Endpoint) int {
+	if e.Weight < 0 {
+		return 0
+	}
+	return e.Weight
+}
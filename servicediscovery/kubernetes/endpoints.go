@@ -0,0 +1,106 @@
+// ------------------------------------------------------------
+// Copyright (c) Microsoft Corporation.
+// Licensed under the MIT License.
+// ------------------------------------------------------------
+
+package kubernetes
+
+import (
+	"fmt"
+	"net"
+	"strconv"
+
+	"github.com/dapr/components-contrib/servicediscovery"
+	discovery "k8s.io/api/discovery/v1beta1"
+	"k8s.io/apimachinery/pkg/labels"
+)
+
+// zoneTopologyKey is the EndpointSlice topology key holding the zone of an endpoint
+const zoneTopologyKey = "topology.kubernetes.io/zone"
+
+// endpointsOf returns the ready addresses of a service from its Endpoints
+func (z *resolver) endpointsOf(c *namespaceCache, namespace string, service string, port int) ([]servicediscovery.Endpoint, error) {
+	endpoints, err := c.endpointsLister.Get(service)
+	if err != nil {
+		return nil, fmt.Errorf("kubernetes resolver: couldn't get endpoints of %s/%s: %s", namespace, service, err)
+	}
+
+	var result []servicediscovery.Endpoint
+	for _, subset := range endpoints.Subsets {
+		ports := map[string]int32{}
+		for _, p := range subset.Ports {
+			ports[p.Name] = p.Port
+		}
+		subsetPort, ok := z.endpointPort(ports, port)
+		if !ok {
+			continue
+		}
+
+		// Pods that aren't ready are in NotReadyAddresses
+		for _, address := range subset.Addresses {
+			result = append(result, servicediscovery.NewEndpoint(net.JoinHostPort(address.IP, strconv.Itoa(subsetPort))))
+		}
+	}
+	return result, nil
+}
+
+// sliceEndpointsOf returns the ready addresses of a service from its EndpointSlices
+func (z *resolver) sliceEndpointsOf(c *namespaceCache, namespace string, service string, port int) ([]servicediscovery.Endpoint, error) {
+	selector := labels.SelectorFromSet(labels.Set{discovery.LabelServiceName: service})
+	slices, err := c.slicesLister.List(selector)
+	if err != nil {
+		return nil, fmt.Errorf("kubernetes resolver: couldn't list endpoint slices of %s/%s: %s", namespace, service, err)
+	}
+
+	var result []servicediscovery.Endpoint
+	seen := map[string]bool{}
+	for _, slice := range slices {
+		if slice.AddressType == discovery.AddressTypeFQDN {
+			continue
+		}
+
+		ports := map[string]int32{}
+		for _, p := range slice.Ports {
+			if p.Port == nil {
+				continue
+			}
+			name := ""
+			if p.Name != nil {
+				name = *p.Name
+			}
+			ports[name] = *p.Port
+		}
+		slicePort, ok := z.endpointPort(ports, port)
+		if !ok {
+			continue
+		}
+
+		for _, e := range slice.Endpoints {
+			// A nil ready condition means the readiness is unknown, which is treated as ready
+			if (e.Conditions.Ready != nil && !*e.Conditions.Ready) || len(e.Addresses) == 0 {
+				continue
+			}
+
+			// An endpoint can be in two slices while it moves between them
+			address := net.JoinHostPort(e.Addresses[0], strconv.Itoa(slicePort))
+			if seen[address] {
+				continue
+			}
+			seen[address] = true
+
+			endpoint := servicediscovery.NewEndpoint(address)
+			endpoint.Zone = e.Topology[zoneTopologyKey]
+			result = append(result, endpoint)
+		}
+	}
+	return result, nil
+}
+
+// endpointPort returns the port of the configured name, or the request port when no name is configured
+func (z *resolver) endpointPort(ports map[string]int32, port int) (int, bool) {
+	if z.portName == "" {
+		return port, true
+	}
+	p, ok := ports[z.portName]
+	return int(p), ok
+}
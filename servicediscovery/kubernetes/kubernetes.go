@@ -6,21 +6,303 @@
 package kubernetes
 
 import (
+	"context"
 	"fmt"
+	"io/ioutil"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
 
 	"github.com/dapr/components-contrib/servicediscovery"
 	"github.com/dapr/dapr/pkg/logger"
+	"k8s.io/client-go/informers"
+	"k8s.io/client-go/kubernetes"
+	corelisters "k8s.io/client-go/listers/core/v1"
+	discoverylisters "k8s.io/client-go/listers/discovery/v1beta1"
+	"k8s.io/client-go/rest"
+	"k8s.io/client-go/tools/cache"
+	"k8s.io/client-go/tools/clientcmd"
+)
+
+const (
+	// ClusterDomainKey is the metadata key for the cluster domain of service names
+	ClusterDomainKey = "clusterDomain"
+	// ServiceSuffixKey is the metadata key for the suffix of the dapr service name of an app
+	ServiceSuffixKey = "serviceSuffix"
+	// ModeKey is the metadata key for how apps are resolved
+	ModeKey = "mode"
+	// PortNameKey is the metadata key for the name of the endpoint port to use instead of the request port
+	PortNameKey = "portName"
+	// NamespacesKey is the metadata key for a comma separated list of the namespaces apps are
+	// resolved in, in the endpoints modes. Their caches are filled in Init, and other
+	// namespaces are rejected. When unset, a namespace is cached on its first lookup.
+	NamespacesKey = "namespaces"
+
+	// ModeService resolves apps to the DNS name of their dapr service
+	ModeService = "service"
+	// ModeEndpoints resolves apps to their ready pod IPs using the Endpoints API
+	ModeEndpoints = "endpoints"
+	// ModeEndpointSlices resolves apps to their ready pod IPs using the EndpointSlice API
+	ModeEndpointSlices = "endpointslices"
+
+	resolvConfPath   = "/etc/resolv.conf"
+	cacheSyncTimeout = time.Second * 30
+
+	// Defaults
+	defaultClusterDomain = "cluster.local"
+	defaultServiceSuffix = "-dapr"
 )
 
 type resolver struct {
-	logger logger.Logger
+	logger        logger.Logger
+	clusterDomain string
+	serviceSuffix string
+	mode          string
+	portName      string
+	resolvConf    string
+
+	// client is set before Init in tests
+	client     kubernetes.Interface
+	namespaces map[string]bool
+	counters   sync.Map
+
+	lock   sync.Mutex
+	caches map[string]*namespaceCache
+}
+
+// namespaceCache holds the informer of the Endpoints or EndpointSlices of one namespace,
+// so that only namespaced list and watch permissions are needed. ready is closed once
+// the cache synced or failed to, err holds the failure.
+type namespaceCache struct {
+	ready           chan struct{}
+	err             error
+	stopCh          chan struct{}
+	endpointsLister corelisters.EndpointsNamespaceLister
+	slicesLister    discoverylisters.EndpointSliceNamespaceLister
 }
 
 func NewKubernetesResolver(logger logger.Logger) servicediscovery.Resolver {
-	return &resolver{logger: logger}
+	return &resolver{
+		logger:        logger,
+		clusterDomain: defaultClusterDomain,
+		serviceSuffix: defaultServiceSuffix,
+		mode:          ModeService,
+		resolvConf:    resolvConfPath,
+		caches:        map[string]*namespaceCache{},
+	}
+}
+
+// Init configures how service names are formatted. The cluster domain defaults to
+// the one in the search domains of the pod. In the endpoints modes, an informer per
+// namespace caches the ready pods of its services.
+func (z *resolver) Init(metadata servicediscovery.Metadata) error {
+	props := metadata.Properties
+	z.clusterDomain = strings.Trim(props[ClusterDomainKey], ".")
+	if z.clusterDomain == "" {
+		z.clusterDomain = clusterDomainFromResolvConf(z.resolvConf)
+	}
+	if suffix, ok := props[ServiceSuffixKey]; ok {
+		z.serviceSuffix = suffix
+	}
+	z.portName = props[PortNameKey]
+
+	mode := strings.ToLower(props[ModeKey])
+	switch mode {
+	case "", ModeService:
+		z.mode = ModeService
+		return z.Close()
+	case ModeEndpoints, ModeEndpointSlices:
+	default:
+		return fmt.Errorf("kubernetes resolver: unknown mode %s", props[ModeKey])
+	}
+
+	if z.client == nil {
+		client, err := getKubeClient()
+		if err != nil {
+			return fmt.Errorf("kubernetes resolver: couldn't create client: %s", err)
+		}
+		z.client = client
+	}
+
+	z.Close()
+	z.mode = mode
+	z.namespaces = nil
+	for _, ns := range strings.Split(props[NamespacesKey], ",") {
+		if ns = strings.TrimSpace(ns); ns == "" {
+			continue
+		}
+		if z.namespaces == nil {
+			z.namespaces = map[string]bool{}
+		}
+		z.namespaces[ns] = true
+		if _, err := z.namespaceCache(ns); err != nil {
+			z.Close()
+			return err
+		}
+	}
+	return nil
+}
+
+// Close stops the informers.
+func (z *resolver) Close() error {
+	z.lock.Lock()
+	caches := z.caches
+	z.caches = map[string]*namespaceCache{}
+	z.lock.Unlock()
+
+	for _, c := range caches {
+		<-c.ready
+		if c.err == nil {
+			close(c.stopCh)
+		}
+	}
+	return nil
 }
 
 func (z *resolver) ResolveID(req servicediscovery.ResolveRequest) (string, error) {
-	// Dapr requires this formatting for Kubernetes services
-	return fmt.Sprintf("%s-dapr.%s.svc.cluster.local:%d", req.ID, req.Namespace, req.Port), nil
+	if z.mode == ModeService {
+		// Dapr requires this formatting for Kubernetes services
+		return fmt.Sprintf("%s.%s.svc.%s:%d", z.serviceName(req.ID), req.Namespace, z.clusterDomain, req.Port), nil
+	}
+
+	endpoints, err := z.ResolveEndpoints(req)
+	if err != nil {
+		return "", err
+	}
+	counter, _ := z.counters.LoadOrStore(req.Namespace+"/"+req.ID, new(uint32))
+	i := atomic.AddUint32(counter.(*uint32), 1) - 1
+	return endpoints[i%uint32(len(endpoints))].Address, nil
+}
+
+// ResolveEndpoints returns the Kubernetes service of the app as its only endpoint,
+// the service load balances between the pods. In the endpoints modes, the ready
+// pods of the service are returned instead for client side load balancing.
+func (z *resolver) ResolveEndpoints(req servicediscovery.ResolveRequest) ([]servicediscovery.Endpoint, error) {
+	if z.mode == ModeService {
+		address, err := z.ResolveID(req)
+		if err != nil {
+			return nil, err
+		}
+		return []servicediscovery.Endpoint{servicediscovery.NewEndpoint(address)}, nil
+	}
+
+	namespace := req.Namespace
+	if namespace == "" {
+		namespace = servicediscovery.DefaultNamespace
+	}
+
+	c, err := z.namespaceCache(namespace)
+	if err != nil {
+		return nil, err
+	}
+
+	var endpoints []servicediscovery.Endpoint
+	if z.mode == ModeEndpoints {
+		endpoints, err = z.endpointsOf(c, namespace, z.serviceName(req.ID), req.Port)
+	} else {
+		endpoints, err = z.sliceEndpointsOf(c, namespace, z.serviceName(req.ID), req.Port)
+	}
+	if err != nil {
+		return nil, err
+	}
+	if len(endpoints) == 0 {
+		return nil, fmt.Errorf("no ready pods of service: %s", req.ID)
+	}
+	return endpoints, nil
+}
+
+func (z *resolver) serviceName(id string) string {
+	return id + z.serviceSuffix
+}
+
+// namespaceCache returns the cache of a namespace, and starts its informer and waits for
+// it to sync on the first lookup. Lookups in other namespaces don't wait for the sync.
+func (z *resolver) namespaceCache(namespace string) (*namespaceCache, error) {
+	if z.namespaces != nil && !z.namespaces[namespace] {
+		return nil, fmt.Errorf("kubernetes resolver: namespace %s isn't in %s", namespace, NamespacesKey)
+	}
+
+	z.lock.Lock()
+	c, ok := z.caches[namespace]
+	if !ok {
+		c = &namespaceCache{ready: make(chan struct{}), stopCh: make(chan struct{})}
+		z.caches[namespace] = c
+	}
+	z.lock.Unlock()
+
+	if !ok {
+		c.err = z.startInformer(c, namespace)
+		if c.err != nil {
+			// Drop the failed cache so the next lookup tries again
+			z.lock.Lock()
+			if z.caches[namespace] == c {
+				delete(z.caches, namespace)
+			}
+			z.lock.Unlock()
+		}
+		close(c.ready)
+	}
+
+	<-c.ready
+	if c.err != nil {
+		return nil, c.err
+	}
+	return c, nil
+}
+
+// startInformer starts caching the Endpoints or EndpointSlices of a namespace and waits for the cache to fill
+func (z *resolver) startInformer(c *namespaceCache, namespace string) error {
+	factory := informers.NewSharedInformerFactoryWithOptions(z.client, 0, informers.WithNamespace(namespace))
+	var informer cache.SharedIndexInformer
+	if z.mode == ModeEndpoints {
+		endpoints := factory.Core().V1().Endpoints()
+		informer, c.endpointsLister = endpoints.Informer(), endpoints.Lister().Endpoints(namespace)
+	} else {
+		slices := factory.Discovery().V1beta1().EndpointSlices()
+		informer, c.slicesLister = slices.Informer(), slices.Lister().EndpointSlices(namespace)
+	}
+	factory.Start(c.stopCh)
+
+	ctx, cancel := context.WithTimeout(context.Background(), cacheSyncTimeout)
+	defer cancel()
+	if !cache.WaitForCacheSync(ctx.Done(), informer.HasSynced) {
+		close(c.stopCh)
+		return fmt.Errorf("kubernetes resolver: timed out waiting for the endpoints cache of namespace %s to sync", namespace)
+	}
+	return nil
+}
+
+// clusterDomainFromResolvConf returns the cluster domain from the search domains of
+// a pod, which include svc.<cluster domain>
+func clusterDomainFromResolvConf(path string) string {
+	b, err := ioutil.ReadFile(path)
+	if err != nil {
+		return defaultClusterDomain
+	}
+
+	for _, line := range strings.Split(string(b), "\n") {
+		fields := strings.Fields(line)
+		if len(fields) == 0 || fields[0] != "search" {
+			continue
+		}
+		for _, domain := range fields[1:] {
+			if strings.HasPrefix(domain, "svc.") {
+				return strings.Trim(strings.TrimPrefix(domain, "svc."), ".")
+			}
+		}
+	}
+	return defaultClusterDomain
+}
+
+// getKubeClient returns a client using the in-cluster config, or the local kubeconfig outside a cluster
+func getKubeClient() (kubernetes.Interface, error) {
+	config, err := rest.InClusterConfig()
+	if err != nil {
+		loader := clientcmd.NewNonInteractiveDeferredLoadingClientConfig(clientcmd.NewDefaultClientConfigLoadingRules(), &clientcmd.ConfigOverrides{})
+		if config, err = loader.ClientConfig(); err != nil {
+			return nil, err
+		}
+	}
+	return kubernetes.NewForConfig(config)
 }
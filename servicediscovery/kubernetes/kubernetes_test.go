@@ -6,11 +6,17 @@
 package kubernetes
 
 import (
+	"io/ioutil"
+	"os"
 	"testing"
 
 	"github.com/dapr/components-contrib/servicediscovery"
 	"github.com/dapr/dapr/pkg/logger"
 	"github.com/stretchr/testify/assert"
+	corev1 "k8s.io/api/core/v1"
+	discoveryv1beta1 "k8s.io/api/discovery/v1beta1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes/fake"
 )
 
 func TestResolve(t *testing.T) {
@@ -23,3 +29,143 @@ func TestResolve(t *testing.T) {
 	assert.Nil(t, err)
 	assert.Equal(t, target, u)
 }
+
+func TestResolveEndpoints(t *testing.T) {
+	resolver := NewKubernetesResolver(logger.NewLogger("test")).(servicediscovery.EndpointResolver)
+	request := servicediscovery.ResolveRequest{ID: "myid", Namespace: "abc", Port: 1234}
+
+	endpoints, err := resolver.ResolveEndpoints(request)
+
+	assert.Nil(t, err)
+	assert.Len(t, endpoints, 1)
+	assert.Equal(t, "myid-dapr.abc.svc.cluster.local:1234", endpoints[0].Address)
+	assert.True(t, endpoints[0].Healthy)
+}
+
+func TestResolveWithClusterDomain(t *testing.T) {
+	resolver := NewKubernetesResolver(logger.NewLogger("test"))
+	err := servicediscovery.Init(resolver, servicediscovery.Metadata{Properties: map[string]string{
+		ClusterDomainKey: "corp.internal.",
+		ServiceSuffixKey: "",
+	}})
+	assert.Nil(t, err)
+
+	target, err := resolver.ResolveID(servicediscovery.ResolveRequest{ID: "myid", Namespace: "abc", Port: 1234})
+	assert.Nil(t, err)
+	assert.Equal(t, "myid.abc.svc.corp.internal:1234", target)
+}
+
+func TestClusterDomainFromResolvConf(t *testing.T) {
+	f, err := ioutil.TempFile("", "resolv.conf")
+	assert.Nil(t, err)
+	defer os.Remove(f.Name())
+	f.WriteString("nameserver 10.96.0.10\nsearch abc.svc.k8s.example svc.k8s.example k8s.example\noptions ndots:5\n") //nolint:errcheck
+	f.Close()
+
+	assert.Equal(t, "k8s.example", clusterDomainFromResolvConf(f.Name()))
+	assert.Equal(t, defaultClusterDomain, clusterDomainFromResolvConf(f.Name()+".missing"))
+}
+
+func boolPtr(b bool) *bool {
+	return &b
+}
+
+func TestResolveEndpointsMode(t *testing.T) {
+	endpoints := &corev1.Endpoints{
+		ObjectMeta: metav1.ObjectMeta{Name: "myid-dapr", Namespace: "abc"},
+		Subsets: []corev1.EndpointSubset{{
+			Addresses:         []corev1.EndpointAddress{{IP: "10.1.0.1"}, {IP: "10.1.0.2"}},
+			NotReadyAddresses: []corev1.EndpointAddress{{IP: "10.1.0.3"}},
+			Ports:             []corev1.EndpointPort{{Name: "dapr-internal", Port: 50002}},
+		}},
+	}
+	r := NewKubernetesResolver(logger.NewLogger("test")).(*resolver)
+	r.client = fake.NewSimpleClientset(endpoints)
+	err := r.Init(servicediscovery.Metadata{Properties: map[string]string{ModeKey: ModeEndpoints}})
+	assert.Nil(t, err)
+	defer r.Close()
+
+	request := servicediscovery.ResolveRequest{ID: "myid", Namespace: "abc", Port: 50002}
+	resolved, err := r.ResolveEndpoints(request)
+	assert.Nil(t, err)
+	assert.Len(t, resolved, 2)
+	assert.Equal(t, "10.1.0.1:50002", resolved[0].Address)
+	assert.Equal(t, "10.1.0.2:50002", resolved[1].Address)
+
+	seen := map[string]bool{}
+	for i := 0; i < 4; i++ {
+		address, err := r.ResolveID(request)
+		assert.Nil(t, err)
+		seen[address] = true
+	}
+	assert.Len(t, seen, 2)
+
+	_, err = r.ResolveID(servicediscovery.ResolveRequest{ID: "otherid", Namespace: "abc", Port: 50002})
+	assert.NotNil(t, err)
+}
+
+func TestInformersAreNamespaced(t *testing.T) {
+	client := fake.NewSimpleClientset(&corev1.Endpoints{
+		ObjectMeta: metav1.ObjectMeta{Name: "myid-dapr", Namespace: "abc"},
+		Subsets: []corev1.EndpointSubset{{
+			Addresses: []corev1.EndpointAddress{{IP: "10.1.0.1"}},
+		}},
+	})
+	r := NewKubernetesResolver(logger.NewLogger("test")).(*resolver)
+	r.client = client
+	err := r.Init(servicediscovery.Metadata{Properties: map[string]string{ModeKey: ModeEndpoints, NamespacesKey: "abc, def"}})
+	assert.Nil(t, err)
+	defer r.Close()
+
+	address, err := r.ResolveID(servicediscovery.ResolveRequest{ID: "myid", Namespace: "abc", Port: 50002})
+	assert.Nil(t, err)
+	assert.Equal(t, "10.1.0.1:50002", address)
+
+	// Namespaces that aren't configured are rejected instead of being watched
+	_, err = r.ResolveID(servicediscovery.ResolveRequest{ID: "myid", Namespace: "other", Port: 50002})
+	assert.NotNil(t, err)
+
+	// Only namespaced list and watch calls are made, which don't need cluster-wide permissions
+	namespaces := map[string]bool{}
+	for _, action := range client.Actions() {
+		assert.NotEmpty(t, action.GetNamespace(), "%s %s isn't namespaced", action.GetVerb(), action.GetResource().Resource)
+		namespaces[action.GetNamespace()] = true
+	}
+	assert.Equal(t, map[string]bool{"abc": true, "def": true}, namespaces)
+}
+
+func TestResolveEndpointSlicesMode(t *testing.T) {
+	portName, port := "dapr-internal", int32(50002)
+	slice := &discoveryv1beta1.EndpointSlice{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "myid-dapr-x1",
+			Namespace: "abc",
+			Labels:    map[string]string{discoveryv1beta1.LabelServiceName: "myid-dapr"},
+		},
+		AddressType: discoveryv1beta1.AddressTypeIPv4,
+		Ports:       []discoveryv1beta1.EndpointPort{{Name: &portName, Port: &port}},
+		Endpoints: []discoveryv1beta1.Endpoint{
+			{Addresses: []string{"10.1.0.1"}, Conditions: discoveryv1beta1.EndpointConditions{Ready: boolPtr(true)}, Topology: map[string]string{zoneTopologyKey: "eu-1a"}},
+			{Addresses: []string{"10.1.0.2"}, Conditions: discoveryv1beta1.EndpointConditions{Ready: boolPtr(false)}},
+			{Addresses: []string{"10.1.0.3"}},
+		},
+	}
+	r := NewKubernetesResolver(logger.NewLogger("test")).(*resolver)
+	r.client = fake.NewSimpleClientset(slice)
+	err := r.Init(servicediscovery.Metadata{Properties: map[string]string{ModeKey: ModeEndpointSlices, PortNameKey: portName}})
+	assert.Nil(t, err)
+	defer r.Close()
+
+	resolved, err := r.ResolveEndpoints(servicediscovery.ResolveRequest{ID: "myid", Namespace: "abc", Port: 3500})
+	assert.Nil(t, err)
+	assert.Len(t, resolved, 2)
+	assert.Equal(t, "10.1.0.1:50002", resolved[0].Address)
+	assert.Equal(t, "eu-1a", resolved[0].Zone)
+	assert.Equal(t, "10.1.0.3:50002", resolved[1].Address)
+}
+
+func TestInvalidMode(t *testing.T) {
+	resolver := NewKubernetesResolver(logger.NewLogger("test"))
+	err := servicediscovery.Init(resolver, servicediscovery.Metadata{Properties: map[string]string{ModeKey: "pods"}})
+	assert.NotNil(t, err)
+}
@@ -7,7 +7,14 @@ package mdns
 
 import (
 	"context"
+	"errors"
 	"fmt"
+	"net"
+	"os"
+	"strconv"
+	"strings"
+	"sync"
+	"sync/atomic"
 	"time"
 
 	"github.com/dapr/components-contrib/servicediscovery"
@@ -15,72 +22,307 @@ import (
 	"github.com/grandcat/zeroconf"
 )
 
-const browseTimeout = time.Second * 1
+const (
+	// BrowseTimeoutKey is the metadata key for how long a browse collects instances
+	BrowseTimeoutKey = "browseTimeout"
+	// CacheTTLKey is the metadata key for how long discovered instances are used before they are refreshed
+	CacheTTLKey = "cacheTTL"
+
+	// weightText is the TXT record key for the weight of an instance
+	weightText = "weight"
+
+	domain = "local."
+
+	// Defaults
+	defaultBrowseTimeout = time.Second * 1
+	defaultCacheTTL      = time.Second * 30
+)
 
 // NewMDNSResolver creates the instance of mDNS service discovery resolver.
 func NewMDNSResolver(logger logger.Logger) servicediscovery.Resolver {
-	return &resolver{logger: logger}
+	return &resolver{
+		logger:        logger,
+		browseTimeout: defaultBrowseTimeout,
+		cacheTTL:      defaultCacheTTL,
+		cache:         map[string]*endpointList{},
+		browse:        browseMDNS,
+	}
 }
 
 type resolver struct {
-	logger logger.Logger
+	logger        logger.Logger
+	browseTimeout time.Duration
+	cacheTTL      time.Duration
+	server        *zeroconf.Server
+
+	cacheLock sync.Mutex
+	cache     map[string]*endpointList
+
+	// browse is replaced in tests
+	browse browseFunc
+}
+
+// browseFunc browses for the instances of an app until ctx is done, sending each
+// endpoint to found as it is discovered
+type browseFunc func(ctx context.Context, id string, found func(endpoint servicediscovery.Endpoint)) error
+
+// endpointList holds the discovered endpoints of an app
+type endpointList struct {
+	endpoints   []servicediscovery.Endpoint
+	counter     uint32
+	refreshedAt time.Time
+	refreshing  bool
+}
+
+// Init configures the resolver and advertises the app when its ID and port are set.
+func (m *resolver) Init(metadata servicediscovery.Metadata) error {
+	props := metadata.Properties
+	for _, d := range []struct {
+		key    string
+		target *time.Duration
+	}{
+		{BrowseTimeoutKey, &m.browseTimeout},
+		{CacheTTLKey, &m.cacheTTL},
+	} {
+		val, ok := props[d.key]
+		if !ok || val == "" {
+			continue
+		}
+		v, err := time.ParseDuration(val)
+		if err != nil || v <= 0 {
+			return fmt.Errorf("mdns resolver: %s must be a positive duration, got %s", d.key, val)
+		}
+		*d.target = v
+	}
+
+	appID := props[servicediscovery.AppIDKey]
+	portStr := props[servicediscovery.DaprPortKey]
+	if appID == "" || portStr == "" {
+		return nil
+	}
+	port, err := strconv.Atoi(portStr)
+	if err != nil || port <= 0 {
+		return fmt.Errorf("mdns resolver: invalid %s %s", servicediscovery.DaprPortKey, portStr)
+	}
+
+	text := []string{appID}
+	if zone := props[servicediscovery.ZoneKey]; zone != "" {
+		text = append(text, fmt.Sprintf("%s=%s", servicediscovery.ZoneKey, zone))
+	}
+	return m.register(appID, port, text)
+}
+
+// register advertises the app on the local network. The app ID is used as the
+// service name and TXT record, matching how instances are browsed.
+func (m *resolver) register(appID string, port int, text []string) error {
+	host, err := os.Hostname()
+	if err != nil {
+		host = "localhost"
+	}
+	instance := fmt.Sprintf("%s-%s-%d", appID, host, port)
+
+	server, err := zeroconf.Register(instance, appID, domain, port, text, nil)
+	if err != nil {
+		return fmt.Errorf("mdns resolver: failed to register %s: %s", appID, err)
+	}
+
+	if m.server != nil {
+		m.server.Shutdown()
+	}
+	m.server = server
+	m.logger.Infof("mdns resolver: registered %s on port %d", instance, port)
+	return nil
+}
+
+// Close stops advertising the app.
+func (m *resolver) Close() error {
+	if m.server != nil {
+		m.server.Shutdown()
+		m.server = nil
+	}
+	return nil
 }
 
 // ResolveID discovers address by app ID.
-func (z *resolver) ResolveID(req servicediscovery.ResolveRequest) (string, error) {
-	address, err := lookupAddressMDNS(req.ID)
+// Cached addresses are returned round-robin and refreshed in the background once
+// they are older than the cache TTL. On a cache miss, the first discovered
+// address is returned while browsing continues to fill the cache.
+func (m *resolver) ResolveID(req servicediscovery.ResolveRequest) (string, error) {
+	if endpoints, counter, ok := m.cached(req.ID); ok {
+		return endpoints[counter%uint32(len(endpoints))].Address, nil
+	}
+
+	first := make(chan servicediscovery.Endpoint, 1)
+	done := make(chan error, 1)
+	go func() {
+		done <- m.refresh(req.ID, first)
+	}()
+
+	select {
+	case endpoint := <-first:
+		return endpoint.Address, nil
+	case err := <-done:
+		if err != nil {
+			return "", err
+		}
+		// The browse may have found addresses after a concurrent lookup filled the cache
+		if endpoints, counter, ok := m.cached(req.ID); ok {
+			return endpoints[counter%uint32(len(endpoints))].Address, nil
+		}
+		return "", fmt.Errorf("couldn't find service: %s", req.ID)
+	}
+}
+
+// ResolveEndpoints returns every discovered instance of an app. Unlike ResolveID,
+// a cache miss waits for the whole browse so all instances are returned.
+func (m *resolver) ResolveEndpoints(req servicediscovery.ResolveRequest) ([]servicediscovery.Endpoint, error) {
+	if endpoints, _, ok := m.cached(req.ID); ok {
+		return endpoints, nil
+	}
+
+	if err := m.refresh(req.ID, nil); err != nil {
+		return nil, err
+	}
+	if endpoints, _, ok := m.cached(req.ID); ok {
+		return endpoints, nil
+	}
+	return nil, fmt.Errorf("couldn't find service: %s", req.ID)
+}
+
+// cached returns a copy of the cached endpoints of an app and the next round-robin
+// counter, and starts a background refresh when the endpoints are stale
+func (m *resolver) cached(id string) ([]servicediscovery.Endpoint, uint32, bool) {
+	m.cacheLock.Lock()
+	defer m.cacheLock.Unlock()
+
+	list, ok := m.cache[id]
+	if !ok || len(list.endpoints) == 0 {
+		return nil, 0, false
+	}
+
+	if time.Since(list.refreshedAt) > m.cacheTTL && !list.refreshing {
+		list.refreshing = true
+		go func() {
+			if err := m.refresh(id, nil); err != nil {
+				m.logger.Warnf("mdns resolver: failed to refresh %s: %s", id, err)
+			}
+		}()
+	}
+
+	endpoints := make([]servicediscovery.Endpoint, len(list.endpoints))
+	copy(endpoints, list.endpoints)
+	return endpoints, atomic.AddUint32(&list.counter, 1) - 1, true
+}
+
+// refresh browses for the instances of an app and replaces its cached endpoints.
+// The first endpoint found is sent to first, when it is not nil.
+func (m *resolver) refresh(id string, first chan<- servicediscovery.Endpoint) error {
+	ctx, cancel := context.WithTimeout(context.Background(), m.browseTimeout)
+	defer cancel()
+
+	var endpoints []servicediscovery.Endpoint
+	seen := map[string]bool{}
+	var lock sync.Mutex
+	err := m.browse(ctx, id, func(endpoint servicediscovery.Endpoint) {
+		lock.Lock()
+		defer lock.Unlock()
+
+		if seen[endpoint.Address] {
+			return
+		}
+		seen[endpoint.Address] = true
+		endpoints = append(endpoints, endpoint)
+		if first != nil {
+			first <- endpoint
+			first = nil
+		}
+	})
+
+	lock.Lock()
+	defer lock.Unlock()
+
+	m.cacheLock.Lock()
+	defer m.cacheLock.Unlock()
+
 	if err != nil {
-		return "", err
+		if list, ok := m.cache[id]; ok {
+			list.refreshing = false
+		}
+		return err
+	}
+	if len(endpoints) == 0 {
+		// All instances are gone
+		delete(m.cache, id)
+		return nil
 	}
-	return address, nil
+
+	counter := uint32(0)
+	if list, ok := m.cache[id]; ok {
+		counter = atomic.LoadUint32(&list.counter)
+	}
+	m.cache[id] = &endpointList{endpoints: endpoints, counter: counter, refreshedAt: time.Now()}
+	return nil
 }
 
-// lookupAddressMDNS uses mdns to find the port of a given service entry on a local network
-func lookupAddressMDNS(id string) (string, error) {
+// browseMDNS uses mdns to find the instances of an app on a local network
+func browseMDNS(ctx context.Context, id string, found func(endpoint servicediscovery.Endpoint)) error {
 	resolver, err := zeroconf.NewResolver(nil)
 	if err != nil {
-		return "", fmt.Errorf("failed to initialize resolver: %e", err)
-	}
-
-	port := -1
-	var addr string
-	entries := make(chan *zeroconf.ServiceEntry)
-
-	ctx, cancel := context.WithTimeout(context.Background(), browseTimeout)
-
-	go func(results <-chan *zeroconf.ServiceEntry) {
-		for entry := range results {
-			for _, text := range entry.Text {
-				if text == id {
-					port = entry.Port
-					if len(entry.AddrIPv4) > 0 {
-						addr = entry.AddrIPv4[0].String() // entry has IPv4
-					} else if len(entry.AddrIPv6) > 0 {
-						addr = entry.AddrIPv6[0].String() // entry has IPv6
-					} else {
-						addr = "localhost" // default
-					}
-
-					// cancel timeout because it found the service
-					cancel()
-					return
-				}
+		return fmt.Errorf("failed to initialize resolver: %s", err)
+	}
+
+	entries := make(chan *zeroconf.ServiceEntry, 10)
+	if err = resolver.Browse(ctx, id, domain, entries); err != nil {
+		return fmt.Errorf("failed to browse: %s", err)
+	}
+
+	// Read until ctx is done so the browser never blocks on a full channel
+	for {
+		select {
+		case entry, ok := <-entries:
+			if !ok {
+				entries = nil
+				continue
+			}
+			if endpoint, err := entryEndpoint(entry, id); err == nil {
+				found(endpoint)
 			}
+		case <-ctx.Done():
+			return nil
 		}
-	}(entries)
-
-	if err = resolver.Browse(ctx, id, "local.", entries); err != nil {
-		// cancel context
-		cancel()
-		return "", fmt.Errorf("failed to browse: %s", err.Error())
 	}
+}
 
-	// wait until context is cancelled or timeed out.
-	<-ctx.Done()
+// entryEndpoint returns the endpoint of a service entry advertising the app.
+// TXT records of the form key=value become endpoint metadata.
+func entryEndpoint(entry *zeroconf.ServiceEntry, id string) (servicediscovery.Endpoint, error) {
+	advertised := false
+	metadata := map[string]string{}
+	for _, text := range entry.Text {
+		if text == id {
+			advertised = true
+			continue
+		}
+		if kv := strings.SplitN(text, "=", 2); len(kv) == 2 {
+			metadata[kv[0]] = kv[1]
+		}
+	}
+	if !advertised {
+		return servicediscovery.Endpoint{}, errors.New("entry doesn't advertise the app")
+	}
 
-	if port == -1 || addr == "" {
-		return "", fmt.Errorf("couldn't find service: %s", id)
+	addr := "localhost" // default
+	if len(entry.AddrIPv4) > 0 {
+		addr = entry.AddrIPv4[0].String() // entry has IPv4
+	} else if len(entry.AddrIPv6) > 0 {
+		addr = entry.AddrIPv6[0].String() // entry has IPv6
 	}
 
-	return fmt.Sprintf("%s:%d", addr, port), nil
+	endpoint := servicediscovery.NewEndpoint(net.JoinHostPort(addr, strconv.Itoa(entry.Port)))
+	endpoint.Zone = metadata[servicediscovery.ZoneKey]
+	if w, err := strconv.Atoi(metadata[weightText]); err == nil && w > 0 {
+		endpoint.Weight = w
+	}
+	endpoint.Metadata = metadata
+	return endpoint, nil
 }
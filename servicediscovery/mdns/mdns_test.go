@@ -0,0 +1,132 @@
+// ------------------------------------------------------------
+// Copyright (c) Microsoft Corporation.
+// Licensed under the MIT License.
+// ------------------------------------------------------------
+
+package mdns
+
+import (
+	"context"
+	"net"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/dapr/components-contrib/servicediscovery"
+	"github.com/dapr/dapr/pkg/logger"
+	"github.com/grandcat/zeroconf"
+	"github.com/stretchr/testify/assert"
+)
+
+func newTestResolver(addresses []string, browses *int32) *resolver {
+	r := NewMDNSResolver(logger.NewLogger("test")).(*resolver)
+	r.browseTimeout = time.Millisecond * 50
+	r.browse = func(ctx context.Context, id string, found func(endpoint servicediscovery.Endpoint)) error {
+		atomic.AddInt32(browses, 1)
+		for _, a := range addresses {
+			found(servicediscovery.NewEndpoint(a))
+		}
+		<-ctx.Done()
+		return nil
+	}
+	return r
+}
+
+func TestInit(t *testing.T) {
+	r := NewMDNSResolver(logger.NewLogger("test")).(*resolver)
+	err := r.Init(servicediscovery.Metadata{Properties: map[string]string{
+		BrowseTimeoutKey: "200ms",
+		CacheTTLKey:      "1m",
+	}})
+	assert.Nil(t, err)
+	assert.Equal(t, time.Millisecond*200, r.browseTimeout)
+	assert.Equal(t, time.Minute, r.cacheTTL)
+
+	err = r.Init(servicediscovery.Metadata{Properties: map[string]string{BrowseTimeoutKey: "soon"}})
+	assert.NotNil(t, err)
+
+	err = r.Init(servicediscovery.Metadata{Properties: map[string]string{
+		servicediscovery.AppIDKey:    "myapp",
+		servicediscovery.DaprPortKey: "-1",
+	}})
+	assert.NotNil(t, err)
+}
+
+func TestResolveRoundRobin(t *testing.T) {
+	var browses int32
+	r := newTestResolver([]string{"10.0.0.1:50002", "10.0.0.2:50002"}, &browses)
+	req := servicediscovery.ResolveRequest{ID: "myapp"}
+
+	// The first lookup returns as soon as an instance is found
+	start := time.Now()
+	first, err := r.ResolveID(req)
+	assert.Nil(t, err)
+	assert.Equal(t, "10.0.0.1:50002", first)
+	assert.True(t, time.Since(start) < r.browseTimeout)
+
+	// Wait for the browse to fill the cache
+	time.Sleep(r.browseTimeout * 2)
+
+	seen := map[string]int{}
+	for i := 0; i < 4; i++ {
+		address, err := r.ResolveID(req)
+		assert.Nil(t, err)
+		seen[address]++
+	}
+	assert.Equal(t, map[string]int{"10.0.0.1:50002": 2, "10.0.0.2:50002": 2}, seen)
+	assert.Equal(t, int32(1), atomic.LoadInt32(&browses))
+}
+
+func TestResolveRefreshesStaleCache(t *testing.T) {
+	var browses int32
+	r := newTestResolver([]string{"10.0.0.1:50002"}, &browses)
+	r.cache["myapp"] = &endpointList{
+		endpoints:   []servicediscovery.Endpoint{servicediscovery.NewEndpoint("10.0.0.9:50002")},
+		refreshedAt: time.Now().Add(-time.Hour),
+	}
+
+	// The stale address is still returned while the cache refreshes
+	address, err := r.ResolveID(servicediscovery.ResolveRequest{ID: "myapp"})
+	assert.Nil(t, err)
+	assert.Equal(t, "10.0.0.9:50002", address)
+
+	time.Sleep(r.browseTimeout * 2)
+	address, err = r.ResolveID(servicediscovery.ResolveRequest{ID: "myapp"})
+	assert.Nil(t, err)
+	assert.Equal(t, "10.0.0.1:50002", address)
+}
+
+func TestResolveNotFound(t *testing.T) {
+	var browses int32
+	r := newTestResolver(nil, &browses)
+	_, err := r.ResolveID(servicediscovery.ResolveRequest{ID: "myapp"})
+	assert.NotNil(t, err)
+}
+
+func TestResolveEndpoints(t *testing.T) {
+	var browses int32
+	r := newTestResolver([]string{"10.0.0.1:50002", "10.0.0.2:50002"}, &browses)
+
+	// A cache miss waits for every instance
+	endpoints, err := r.ResolveEndpoints(servicediscovery.ResolveRequest{ID: "myapp"})
+	assert.Nil(t, err)
+	assert.Len(t, endpoints, 2)
+	assert.True(t, endpoints[0].Healthy)
+	assert.Equal(t, int32(1), atomic.LoadInt32(&browses))
+}
+
+func TestEntryEndpoint(t *testing.T) {
+	entry := zeroconf.NewServiceEntry("myapp-host", "myapp", domain)
+	entry.Port = 50002
+	entry.Text = []string{"myapp", "zone=eu-1", "weight=3"}
+	entry.AddrIPv6 = []net.IP{net.ParseIP("fe80::1")}
+
+	endpoint, err := entryEndpoint(entry, "myapp")
+	assert.Nil(t, err)
+	assert.Equal(t, "[fe80::1]:50002", endpoint.Address)
+	assert.Equal(t, "eu-1", endpoint.Zone)
+	assert.Equal(t, 3, endpoint.Weight)
+
+	_, err = entryEndpoint(entry, "otherapp")
+	assert.NotNil(t, err)
+}
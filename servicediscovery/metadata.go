@@ -0,0 +1,22 @@
+// ------------------------------------------------------------
+// Copyright (c) Microsoft Corporation.
+// Licensed under the MIT License.
+// ------------------------------------------------------------
+
+package servicediscovery
+
+const (
+	// AppIDKey is the metadata key for the ID of the app the resolver runs for
+	AppIDKey = "appID"
+	// HostAddressKey is the metadata key for the address the app can be reached on
+	HostAddressKey = "hostAddress"
+	// DaprPortKey is the metadata key for the dapr internal port the app can be reached on
+	DaprPortKey = "daprPort"
+	// ZoneKey is the metadata key for the zone the app runs in
+	ZoneKey = "zone"
+)
+
+// Metadata represents a set of resolver specific properties
+type Metadata struct {
+	Properties map[string]string `json:"properties"`
+}
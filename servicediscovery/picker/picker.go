@@ -0,0 +1,101 @@
+// ------------------------------------------------------------
+// Copyright (c) Microsoft Corporation.
+// Licensed under the MIT License.
+// ------------------------------------------------------------
+
+package picker
+
+import (
+	"fmt"
+
+	"github.com/dapr/components-contrib/servicediscovery"
+)
+
+const (
+	// RoundRobin is the name of the round-robin picker
+	RoundRobin = "roundrobin"
+	// Random is the name of the weighted random picker
+	Random = "random"
+	// LeastPending is the name of the least pending calls picker
+	LeastPending = "leastpending"
+	// ZoneAffinity is the name of the zone affinity picker, which uses round-robin within a zone
+	ZoneAffinity = "zoneaffinity"
+)
+
+// Picker selects the endpoint a call is sent to.
+type Picker interface {
+	// Pick selects one of the endpoints, which is never empty. The returned func
+	// must be called once the call to the endpoint has finished.
+	Pick(endpoints []servicediscovery.Endpoint) (servicediscovery.Endpoint, func())
+}
+
+// New returns the picker with the given name. zone is only used by the zone affinity picker.
+func New(name string, zone string) (Picker, error) {
+	switch name {
+	case "", RoundRobin:
+		return NewRoundRobin(), nil
+	case Random:
+		return NewRandom(), nil
+	case LeastPending:
+		return NewLeastPending(), nil
+	case ZoneAffinity:
+		return NewZoneAffinity(zone, NewRoundRobin()), nil
+	default:
+		return nil, fmt.Errorf("unknown picker %s", name)
+	}
+}
+
+// Resolver wraps a resolver and uses a picker to choose between the endpoints it returns.
+type Resolver struct {
+	resolver servicediscovery.Resolver
+	picker   Picker
+}
+
+// NewResolver creates a resolver that load balances the endpoints of resolver with picker.
+// Resolvers that don't implement servicediscovery.EndpointResolver have a single endpoint.
+func NewResolver(resolver servicediscovery.Resolver, picker Picker) *Resolver {
+	return &Resolver{resolver: resolver, picker: picker}
+}
+
+// Init initializes the wrapped resolver when it implements servicediscovery.Initializer.
+func (r *Resolver) Init(metadata servicediscovery.Metadata) error {
+	return servicediscovery.Init(r.resolver, metadata)
+}
+
+// ResolveID returns the address of the picked endpoint. The call is considered
+// finished immediately, use Resolve for pickers that track pending calls.
+func (r *Resolver) ResolveID(req servicediscovery.ResolveRequest) (string, error) {
+	endpoint, done, err := r.Resolve(req)
+	if err != nil {
+		return "", err
+	}
+	done()
+	return endpoint.Address, nil
+}
+
+// ResolveEndpoints returns every endpoint of the wrapped resolver.
+func (r *Resolver) ResolveEndpoints(req servicediscovery.ResolveRequest) ([]servicediscovery.Endpoint, error) {
+	return servicediscovery.ResolveEndpoints(r.resolver, req)
+}
+
+// Resolve picks one of the healthy endpoints of an app. The returned func must be
+// called once the call to the endpoint has finished.
+func (r *Resolver) Resolve(req servicediscovery.ResolveRequest) (servicediscovery.Endpoint, func(), error) {
+	endpoints, err := r.ResolveEndpoints(req)
+	if err != nil {
+		return servicediscovery.Endpoint{}, nil, err
+	}
+
+	healthy := make([]servicediscovery.Endpoint, 0, len(endpoints))
+	for _, e := range endpoints {
+		if e.Healthy {
+			healthy = append(healthy, e)
+		}
+	}
+	if len(healthy) == 0 {
+		return servicediscovery.Endpoint{}, nil, fmt.Errorf("no healthy endpoints for app %s", req.ID)
+	}
+
+	endpoint, done := r.picker.Pick(healthy)
+	return endpoint, done, nil
+}
@@ -0,0 +1,189 @@
+// ------------------------------------------------------------
+// Copyright (c) Microsoft Corporation.
+// Licensed under the MIT License.
+// ------------------------------------------------------------
+
+package picker
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/dapr/components-contrib/servicediscovery"
+	"github.com/stretchr/testify/assert"
+)
+
+type fakeResolver struct {
+	endpoints []servicediscovery.Endpoint
+	err       error
+	metadata  servicediscovery.Metadata
+}
+
+func (f *fakeResolver) Init(metadata servicediscovery.Metadata) error {
+	f.metadata = metadata
+	return f.err
+}
+
+func (f *fakeResolver) ResolveID(req servicediscovery.ResolveRequest) (string, error) {
+	return f.endpoints[0].Address, f.err
+}
+
+func (f *fakeResolver) ResolveEndpoints(req servicediscovery.ResolveRequest) ([]servicediscovery.Endpoint, error) {
+	return f.endpoints, f.err
+}
+
+type singleResolver struct{}
+
+func (s *singleResolver) ResolveID(req servicediscovery.ResolveRequest) (string, error) {
+	return "10.0.0.1:50002", nil
+}
+
+func endpoints(addresses ...string) []servicediscovery.Endpoint {
+	result := make([]servicediscovery.Endpoint, len(addresses))
+	for i, a := range addresses {
+		result[i] = servicediscovery.NewEndpoint(a)
+	}
+	return result
+}
+
+func TestRoundRobin(t *testing.T) {
+	p := NewRoundRobin()
+	eps := endpoints("a", "b", "c")
+	var picked []string
+	for i := 0; i < 4; i++ {
+		e, done := p.Pick(eps)
+		done()
+		picked = append(picked, e.Address)
+	}
+	assert.Equal(t, []string{"a", "b", "c", "a"}, picked)
+}
+
+func TestRandomRespectsWeight(t *testing.T) {
+	p := NewRandom()
+	eps := endpoints("a", "b")
+	eps[0].Weight = 0
+	eps[1].Weight = 1000
+
+	counts := map[string]int{}
+	for i := 0; i < 100; i++ {
+		e, _ := p.Pick(eps)
+		counts[e.Address]++
+	}
+	assert.True(t, counts["b"] > counts["a"])
+}
+
+func TestRandomSkipsZeroWeights(t *testing.T) {
+	p := NewRandom()
+	eps := endpoints("a", "b", "c")
+	eps[0].Weight = 0
+	eps[1].Weight = 1
+	eps[2].Weight = 2
+
+	counts := map[string]int{}
+	for i := 0; i < 300; i++ {
+		e, _ := p.Pick(eps)
+		counts[e.Address]++
+	}
+	assert.Equal(t, 0, counts["a"])
+	assert.NotEqual(t, 0, counts["b"])
+	assert.NotEqual(t, 0, counts["c"])
+
+	// Zero weight endpoints are picked when no endpoint has a weight
+	eps[1].Weight = 0
+	eps[2].Weight = 0
+	counts = map[string]int{}
+	for i := 0; i < 300; i++ {
+		e, _ := p.Pick(eps)
+		counts[e.Address]++
+	}
+	assert.Len(t, counts, 3)
+}
+
+func TestLeastPending(t *testing.T) {
+	p := NewLeastPending()
+	eps := endpoints("a", "b")
+
+	first, doneFirst := p.Pick(eps)
+	second, doneSecond := p.Pick(eps)
+	assert.NotEqual(t, first.Address, second.Address)
+
+	// Once the first call finishes, its endpoint has the fewest pending calls
+	doneFirst()
+	doneFirst()
+	third, _ := p.Pick(eps)
+	assert.Equal(t, first.Address, third.Address)
+	doneSecond()
+}
+
+func TestZoneAffinity(t *testing.T) {
+	p := NewZoneAffinity("eu-1", NewRoundRobin())
+	eps := endpoints("a", "b", "c")
+	eps[1].Zone = "eu-1"
+
+	for i := 0; i < 3; i++ {
+		e, _ := p.Pick(eps)
+		assert.Equal(t, "b", e.Address)
+	}
+
+	// Other zones are used when there are no local endpoints
+	e, _ := p.Pick(eps[:1])
+	assert.Equal(t, "a", e.Address)
+}
+
+func TestNew(t *testing.T) {
+	for _, name := range []string{"", RoundRobin, Random, LeastPending, ZoneAffinity} {
+		p, err := New(name, "eu-1")
+		assert.Nil(t, err)
+		assert.NotNil(t, p)
+	}
+	_, err := New("fastest", "")
+	assert.NotNil(t, err)
+}
+
+func TestResolver(t *testing.T) {
+	t.Run("skips unhealthy endpoints", func(t *testing.T) {
+		eps := endpoints("a", "b")
+		eps[0].Healthy = false
+		r := NewResolver(&fakeResolver{endpoints: eps}, NewRoundRobin())
+
+		for i := 0; i < 2; i++ {
+			address, err := r.ResolveID(servicediscovery.ResolveRequest{ID: "myapp"})
+			assert.Nil(t, err)
+			assert.Equal(t, "b", address)
+		}
+	})
+
+	t.Run("no healthy endpoints", func(t *testing.T) {
+		eps := endpoints("a")
+		eps[0].Healthy = false
+		r := NewResolver(&fakeResolver{endpoints: eps}, NewRoundRobin())
+		_, _, err := r.Resolve(servicediscovery.ResolveRequest{ID: "myapp"})
+		assert.NotNil(t, err)
+	})
+
+	t.Run("resolver error", func(t *testing.T) {
+		r := NewResolver(&fakeResolver{endpoints: endpoints("a"), err: errors.New("unavailable")}, NewRoundRobin())
+		_, err := r.ResolveID(servicediscovery.ResolveRequest{ID: "myapp"})
+		assert.NotNil(t, err)
+	})
+
+	t.Run("wraps single address resolvers", func(t *testing.T) {
+		r := NewResolver(&singleResolver{}, NewRoundRobin())
+		eps, err := r.ResolveEndpoints(servicediscovery.ResolveRequest{ID: "myapp"})
+		assert.Nil(t, err)
+		assert.Equal(t, endpoints("10.0.0.1:50002"), eps)
+	})
+
+	t.Run("init", func(t *testing.T) {
+		metadata := servicediscovery.Metadata{Properties: map[string]string{"key": "value"}}
+		fake := &fakeResolver{}
+		assert.Nil(t, NewResolver(fake, NewRoundRobin()).Init(metadata))
+		assert.Equal(t, metadata, fake.metadata)
+
+		failing := &fakeResolver{err: errors.New("unavailable")}
+		assert.NotNil(t, NewResolver(failing, NewRoundRobin()).Init(metadata))
+
+		// Resolvers without Init need no initialization
+		assert.Nil(t, NewResolver(&singleResolver{}, NewRoundRobin()).Init(metadata))
+	})
+}
@@ -0,0 +1,108 @@
+// ------------------------------------------------------------
+// Copyright (c) Microsoft Corporation.
+// Licensed under the MIT License.
+// ------------------------------------------------------------
+
+package picker
+
+import (
+	"sync"
+	"sync/atomic"
+
+	"github.com/dapr/components-contrib/servicediscovery"
+)
+
+func noop() {}
+
+type roundRobin struct {
+	counter uint32
+}
+
+// NewRoundRobin returns a picker that cycles through the endpoints.
+func NewRoundRobin() Picker {
+	return &roundRobin{}
+}
+
+func (p *roundRobin) Pick(endpoints []servicediscovery.Endpoint) (servicediscovery.Endpoint, func()) {
+	i := atomic.AddUint32(&p.counter, 1) - 1
+	return endpoints[i%uint32(len(endpoints))], noop
+}
+
+type random struct{}
+
+// NewRandom returns a picker that selects endpoints at random, in proportion to their weight.
+// Endpoints with a weight of 0 are only selected when every weight is 0.
+func NewRandom() Picker {
+	return &random{}
+}
+
+func (p *random) Pick(endpoints []servicediscovery.Endpoint) (servicediscovery.Endpoint, func()) {
+	return servicediscovery.PickWeighted(endpoints), noop
+}
+
+type leastPending struct {
+	lock    sync.Mutex
+	pending map[string]int
+	counter int
+}
+
+// NewLeastPending returns a picker that selects the endpoint with the fewest calls in
+// progress. Ties are broken round-robin.
+func NewLeastPending() Picker {
+	return &leastPending{pending: map[string]int{}}
+}
+
+func (p *leastPending) Pick(endpoints []servicediscovery.Endpoint) (servicediscovery.Endpoint, func()) {
+	p.lock.Lock()
+	defer p.lock.Unlock()
+
+	start := p.counter
+	p.counter++
+	best := start % len(endpoints)
+	for i := 1; i < len(endpoints); i++ {
+		idx := (start + i) % len(endpoints)
+		if p.pending[endpoints[idx].Address] < p.pending[endpoints[best].Address] {
+			best = idx
+		}
+	}
+
+	endpoint := endpoints[best]
+	p.pending[endpoint.Address]++
+
+	var once sync.Once
+	return endpoint, func() {
+		once.Do(func() {
+			p.lock.Lock()
+			defer p.lock.Unlock()
+
+			p.pending[endpoint.Address]--
+			if p.pending[endpoint.Address] <= 0 {
+				delete(p.pending, endpoint.Address)
+			}
+		})
+	}
+}
+
+type zoneAffinity struct {
+	zone     string
+	fallback Picker
+}
+
+// NewZoneAffinity returns a picker that prefers endpoints in zone, and uses fallback
+// to choose among them. Endpoints in other zones are only used when there are none in zone.
+func NewZoneAffinity(zone string, fallback Picker) Picker {
+	return &zoneAffinity{zone: zone, fallback: fallback}
+}
+
+func (p *zoneAffinity) Pick(endpoints []servicediscovery.Endpoint) (servicediscovery.Endpoint, func()) {
+	local := make([]servicediscovery.Endpoint, 0, len(endpoints))
+	for _, e := range endpoints {
+		if e.Zone == p.zone {
+			local = append(local, e)
+		}
+	}
+	if len(local) == 0 {
+		return p.fallback.Pick(endpoints)
+	}
+	return p.fallback.Pick(local)
+}
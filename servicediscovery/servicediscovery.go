@@ -9,3 +9,18 @@ package servicediscovery
 type Resolver interface {
 	ResolveID(req ResolveRequest) (string, error)
 }
+
+// Initializer is a resolver that has to be initialized with metadata before it resolves,
+// and registers the app when the resolver supports it.
+type Initializer interface {
+	Resolver
+	Init(metadata Metadata) error
+}
+
+// Init initializes a resolver that implements Initializer. Other resolvers need no initialization.
+func Init(resolver Resolver, metadata Metadata) error {
+	if i, ok := resolver.(Initializer); ok {
+		return i.Init(metadata)
+	}
+	return nil
+}
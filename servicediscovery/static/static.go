@@ -0,0 +1,266 @@
+// ------------------------------------------------------------
+// Copyright (c) Microsoft Corporation.
+// Licensed under the MIT License.
+// ------------------------------------------------------------
+
+package static
+
+import (
+	"bytes"
+	"fmt"
+	"io/ioutil"
+	"net"
+	"path/filepath"
+	"strings"
+	"sync"
+	"sync/atomic"
+
+	"github.com/dapr/components-contrib/servicediscovery"
+	"github.com/dapr/dapr/pkg/logger"
+	"github.com/fsnotify/fsnotify"
+	"github.com/ghodss/yaml"
+)
+
+const (
+	// FileKey is the metadata key for the path of a JSON or YAML file with app addresses
+	FileKey = "file"
+	// AppKeyPrefix prefixes inline metadata entries. The key is app.<appID> or
+	// app.<namespace>/<appID>, and the value a comma separated list of addresses.
+	AppKeyPrefix = "app."
+)
+
+// fileConfig is the format of the addresses file. Apps apply to every namespace,
+// entries in Namespaces take precedence for their namespace.
+type fileConfig struct {
+	Apps       map[string][]string            `json:"apps"`
+	Namespaces map[string]map[string][]string `json:"namespaces"`
+}
+
+// table maps namespaces to app IDs to addresses. The empty namespace matches any namespace.
+type table map[string]map[string][]string
+
+func (t table) add(namespace string, id string, addresses []string) {
+	if _, ok := t[namespace]; !ok {
+		t[namespace] = map[string][]string{}
+	}
+	t[namespace][id] = addresses
+}
+
+func (t table) lookup(namespace string, id string) []string {
+	if addresses, ok := t[namespace][id]; ok {
+		return addresses
+	}
+	return t[""][id]
+}
+
+// NewStaticResolver creates a resolver that resolves app IDs from configured addresses.
+func NewStaticResolver(logger logger.Logger) servicediscovery.Resolver {
+	return &resolver{logger: logger}
+}
+
+type resolver struct {
+	logger  logger.Logger
+	inline  table
+	path    string
+	watcher *fsnotify.Watcher
+	// content is the last read content of the file, events that don't change it are ignored
+	content []byte
+
+	lock    sync.RWMutex
+	entries table
+
+	// counters holds the round-robin counter of each namespace/app ID
+	counters sync.Map
+}
+
+// Init reads the inline addresses and the addresses file, and watches the file for changes.
+func (s *resolver) Init(metadata servicediscovery.Metadata) error {
+	inline, err := parseInline(metadata.Properties)
+	if err != nil {
+		return err
+	}
+	s.inline = inline
+	s.path = metadata.Properties[FileKey]
+
+	if _, err = s.reload(); err != nil {
+		return err
+	}
+	if s.path == "" {
+		return nil
+	}
+
+	watcher, err := fsnotify.NewWatcher()
+	if err != nil {
+		return fmt.Errorf("static resolver: failed to watch %s: %s", s.path, err)
+	}
+	// Watch the directory, editors replace files rather than writing to them, and config
+	// maps swap the ..data symlink the file points through without an event for the file
+	if err = watcher.Add(filepath.Dir(s.path)); err != nil {
+		watcher.Close()
+		return fmt.Errorf("static resolver: failed to watch %s: %s", s.path, err)
+	}
+	s.watcher = watcher
+	go s.watch(watcher)
+	return nil
+}
+
+// Close stops watching the addresses file.
+func (s *resolver) Close() error {
+	if s.watcher == nil {
+		return nil
+	}
+	err := s.watcher.Close()
+	s.watcher = nil
+	return err
+}
+
+// ResolveID returns the addresses of an app round-robin.
+func (s *resolver) ResolveID(req servicediscovery.ResolveRequest) (string, error) {
+	s.lock.RLock()
+	addresses := s.entries.lookup(req.Namespace, req.ID)
+	s.lock.RUnlock()
+
+	if len(addresses) == 0 {
+		return "", fmt.Errorf("couldn't find service: %s", req.ID)
+	}
+
+	counter, _ := s.counters.LoadOrStore(req.Namespace+"/"+req.ID, new(uint32))
+	i := atomic.AddUint32(counter.(*uint32), 1) - 1
+	return addresses[i%uint32(len(addresses))], nil
+}
+
+// ResolveEndpoints returns every configured address of an app.
+func (s *resolver) ResolveEndpoints(req servicediscovery.ResolveRequest) ([]servicediscovery.Endpoint, error) {
+	s.lock.RLock()
+	defer s.lock.RUnlock()
+
+	addresses := s.entries.lookup(req.Namespace, req.ID)
+	if len(addresses) == 0 {
+		return nil, fmt.Errorf("couldn't find service: %s", req.ID)
+	}
+
+	endpoints := make([]servicediscovery.Endpoint, len(addresses))
+	for i, a := range addresses {
+		endpoints[i] = servicediscovery.NewEndpoint(a)
+	}
+	return endpoints, nil
+}
+
+// watch reloads the addresses file when any entry of its directory is created,
+// written or renamed and the file content changed. A file that fails to parse
+// is logged and the previous addresses are kept.
+func (s *resolver) watch(watcher *fsnotify.Watcher) {
+	for {
+		select {
+		case event, ok := <-watcher.Events:
+			if !ok {
+				return
+			}
+			if event.Op&(fsnotify.Write|fsnotify.Create|fsnotify.Rename) == 0 {
+				continue
+			}
+			changed, err := s.reload()
+			if err != nil {
+				s.logger.Errorf("static resolver: keeping previous addresses: %s", err)
+				continue
+			}
+			if changed {
+				s.logger.Infof("static resolver: reloaded %s", s.path)
+			}
+		case err, ok := <-watcher.Errors:
+			if !ok {
+				return
+			}
+			s.logger.Warnf("static resolver: error watching %s: %s", s.path, err)
+		}
+	}
+}
+
+// reload merges the inline addresses with the ones in the file, which take precedence.
+// It returns false when the file content didn't change since the last reload.
+func (s *resolver) reload() (bool, error) {
+	entries := table{}
+	for namespace, apps := range s.inline {
+		for id, addresses := range apps {
+			entries.add(namespace, id, addresses)
+		}
+	}
+
+	if s.path != "" {
+		b, err := ioutil.ReadFile(s.path)
+		if err != nil {
+			return false, fmt.Errorf("static resolver: failed to read %s: %s", s.path, err)
+		}
+		if s.content != nil && bytes.Equal(b, s.content) {
+			return false, nil
+		}
+		s.content = b
+
+		var config fileConfig
+		if err = yaml.Unmarshal(b, &config); err != nil {
+			return false, fmt.Errorf("static resolver: failed to parse %s: %s", s.path, err)
+		}
+
+		for id, addresses := range config.Apps {
+			if err = validateAddresses(id, addresses); err != nil {
+				return false, err
+			}
+			entries.add("", id, addresses)
+		}
+		for namespace, apps := range config.Namespaces {
+			for id, addresses := range apps {
+				if err = validateAddresses(id, addresses); err != nil {
+					return false, err
+				}
+				entries.add(namespace, id, addresses)
+			}
+		}
+	}
+
+	s.lock.Lock()
+	s.entries = entries
+	s.lock.Unlock()
+	return true, nil
+}
+
+// parseInline reads the app.<appID> and app.<namespace>/<appID> metadata entries
+func parseInline(props map[string]string) (table, error) {
+	inline := table{}
+	for key, val := range props {
+		if !strings.HasPrefix(key, AppKeyPrefix) {
+			continue
+		}
+
+		namespace, id := "", strings.TrimPrefix(key, AppKeyPrefix)
+		if i := strings.Index(id, "/"); i >= 0 {
+			namespace, id = id[:i], id[i+1:]
+		}
+		if id == "" {
+			return nil, fmt.Errorf("static resolver: missing app ID in %s", key)
+		}
+
+		var addresses []string
+		for _, a := range strings.Split(val, ",") {
+			if a = strings.TrimSpace(a); a != "" {
+				addresses = append(addresses, a)
+			}
+		}
+		if err := validateAddresses(id, addresses); err != nil {
+			return nil, err
+		}
+		inline.add(namespace, id, addresses)
+	}
+	return inline, nil
+}
+
+func validateAddresses(id string, addresses []string) error {
+	if len(addresses) == 0 {
+		return fmt.Errorf("static resolver: no addresses for %s", id)
+	}
+	for _, a := range addresses {
+		if _, _, err := net.SplitHostPort(a); err != nil {
+			return fmt.Errorf("static resolver: invalid address %s for %s: %s", a, id, err)
+		}
+	}
+	return nil
+}
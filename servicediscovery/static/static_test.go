@@ -0,0 +1,162 @@
+// ------------------------------------------------------------
+// Copyright (c) Microsoft Corporation.
+// Licensed under the MIT License.
+// ------------------------------------------------------------
+
+package static
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/dapr/components-contrib/servicediscovery"
+	"github.com/dapr/dapr/pkg/logger"
+	"github.com/stretchr/testify/assert"
+)
+
+const testFile = `
+apps:
+  myapp:
+    - 127.0.0.1:50002
+    - 127.0.0.1:50003
+namespaces:
+  prod:
+    myapp:
+      - 10.0.0.1:50002
+`
+
+func writeFile(t *testing.T, path string, content string) {
+	// Write to a temporary file and rename it, like editors do
+	tmp := path + ".tmp"
+	assert.Nil(t, ioutil.WriteFile(tmp, []byte(content), 0644))
+	assert.Nil(t, os.Rename(tmp, path))
+}
+
+func TestInline(t *testing.T) {
+	r := NewStaticResolver(logger.NewLogger("test"))
+	err := servicediscovery.Init(r, servicediscovery.Metadata{Properties: map[string]string{
+		"app.myapp":      "127.0.0.1:50002, 127.0.0.1:50003",
+		"app.prod/myapp": "10.0.0.1:50002",
+		"appID":          "self",
+	}})
+	assert.Nil(t, err)
+
+	req := servicediscovery.ResolveRequest{ID: "myapp", Namespace: "default"}
+	var addresses []string
+	for i := 0; i < 3; i++ {
+		address, err := r.ResolveID(req)
+		assert.Nil(t, err)
+		addresses = append(addresses, address)
+	}
+	assert.Equal(t, []string{"127.0.0.1:50002", "127.0.0.1:50003", "127.0.0.1:50002"}, addresses)
+
+	address, err := r.ResolveID(servicediscovery.ResolveRequest{ID: "myapp", Namespace: "prod"})
+	assert.Nil(t, err)
+	assert.Equal(t, "10.0.0.1:50002", address)
+
+	_, err = r.ResolveID(servicediscovery.ResolveRequest{ID: "other", Namespace: "default"})
+	assert.NotNil(t, err)
+}
+
+func TestInvalidInline(t *testing.T) {
+	invalid := map[string]map[string]string{
+		"no port":      {"app.myapp": "127.0.0.1"},
+		"no addresses": {"app.myapp": " , "},
+		"no app ID":    {"app.prod/": "127.0.0.1:50002"},
+	}
+	for name, props := range invalid {
+		t.Run(name, func(t *testing.T) {
+			r := NewStaticResolver(logger.NewLogger("test"))
+			assert.NotNil(t, servicediscovery.Init(r, servicediscovery.Metadata{Properties: props}))
+		})
+	}
+}
+
+func TestFileReload(t *testing.T) {
+	dir, err := ioutil.TempDir("", "static")
+	assert.Nil(t, err)
+	defer os.RemoveAll(dir)
+	path := filepath.Join(dir, "apps.yaml")
+	writeFile(t, path, testFile)
+
+	r := NewStaticResolver(logger.NewLogger("test")).(*resolver)
+	err = r.Init(servicediscovery.Metadata{Properties: map[string]string{FileKey: path}})
+	assert.Nil(t, err)
+	defer r.Close()
+
+	endpoints, err := r.ResolveEndpoints(servicediscovery.ResolveRequest{ID: "myapp", Namespace: "default"})
+	assert.Nil(t, err)
+	assert.Len(t, endpoints, 2)
+
+	endpoints, err = r.ResolveEndpoints(servicediscovery.ResolveRequest{ID: "myapp", Namespace: "prod"})
+	assert.Nil(t, err)
+	assert.Equal(t, "10.0.0.1:50002", endpoints[0].Address)
+
+	// JSON is accepted as well
+	writeFile(t, path, `{"apps": {"myapp": ["127.0.0.1:60000"]}}`)
+	assert.True(t, waitFor(time.Second*5, func() bool {
+		address, err := r.ResolveID(servicediscovery.ResolveRequest{ID: "myapp", Namespace: "prod"})
+		return err == nil && address == "127.0.0.1:60000"
+	}))
+
+	// An invalid file keeps the previous addresses
+	writeFile(t, path, `apps: [`)
+	time.Sleep(time.Millisecond * 100)
+	address, err := r.ResolveID(servicediscovery.ResolveRequest{ID: "myapp", Namespace: "default"})
+	assert.Nil(t, err)
+	assert.Equal(t, "127.0.0.1:60000", address)
+}
+
+// writeConfigMap updates dir the way the kubelet updates a mounted config map: the
+// files are written to a new timestamped directory, the ..data symlink is swapped to
+// it, and the file in dir is a symlink through ..data that never changes itself
+func writeConfigMap(t *testing.T, dir string, version string, content string) {
+	versionDir := filepath.Join(dir, "..version_"+version)
+	assert.Nil(t, os.Mkdir(versionDir, 0755))
+	assert.Nil(t, ioutil.WriteFile(filepath.Join(versionDir, "apps.yaml"), []byte(content), 0644))
+
+	assert.Nil(t, os.Symlink(filepath.Base(versionDir), filepath.Join(dir, "..data_tmp")))
+	assert.Nil(t, os.Rename(filepath.Join(dir, "..data_tmp"), filepath.Join(dir, "..data")))
+
+	link := filepath.Join(dir, "apps.yaml")
+	if _, err := os.Lstat(link); os.IsNotExist(err) {
+		assert.Nil(t, os.Symlink(filepath.Join("..data", "apps.yaml"), link))
+	}
+}
+
+func TestConfigMapReload(t *testing.T) {
+	dir, err := ioutil.TempDir("", "static")
+	assert.Nil(t, err)
+	defer os.RemoveAll(dir)
+	writeConfigMap(t, dir, "1", `{"apps": {"myapp": ["127.0.0.1:50002"]}}`)
+
+	r := NewStaticResolver(logger.NewLogger("test")).(*resolver)
+	err = r.Init(servicediscovery.Metadata{Properties: map[string]string{FileKey: filepath.Join(dir, "apps.yaml")}})
+	assert.Nil(t, err)
+	defer r.Close()
+
+	address, err := r.ResolveID(servicediscovery.ResolveRequest{ID: "myapp", Namespace: "default"})
+	assert.Nil(t, err)
+	assert.Equal(t, "127.0.0.1:50002", address)
+
+	writeConfigMap(t, dir, "2", `{"apps": {"myapp": ["127.0.0.1:60000"]}}`)
+	assert.True(t, waitFor(time.Second*5, func() bool {
+		address, err := r.ResolveID(servicediscovery.ResolveRequest{ID: "myapp", Namespace: "default"})
+		return err == nil && address == "127.0.0.1:60000"
+	}))
+}
+
+// waitFor polls condition until it is true or timeout expires
+func waitFor(timeout time.Duration, condition func() bool) bool {
+	deadline := time.Now().Add(timeout)
+	for time.Now().Before(deadline) {
+		if condition() {
+			return true
+		}
+		time.Sleep(time.Millisecond * 10)
+	}
+	return false
+}
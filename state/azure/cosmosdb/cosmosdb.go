@@ -12,6 +12,7 @@ import (
 	"github.com/dapr/dapr/pkg/logger"
 	jsoniter "github.com/json-iterator/go"
 
+	"github.com/dapr/components-contrib/componenterrors"
 	"github.com/dapr/components-contrib/state"
 
 	"github.com/a8m/documentdb"
@@ -19,11 +20,23 @@ import (
 
 // StateStore is a CosmosDB state store
 type StateStore struct {
-	client     *documentdb.DocumentDB
+	client     documentDB
 	collection *documentdb.Collection
 	db         *documentdb.Database
 
 	logger logger.Logger
+
+	// newClient creates the client in Init, tests replace it with a fake
+	newClient func(creds *credentials) documentDB
+}
+
+// documentDB is the subset of the CosmosDB client used by the state store
+type documentDB interface {
+	QueryDatabases(query *documentdb.Query, opts ...documentdb.CallOption) (documentdb.Databases, error)
+	QueryCollections(db string, query *documentdb.Query, opts ...documentdb.CallOption) ([]documentdb.Collection, error)
+	QueryDocuments(coll string, query *documentdb.Query, docs interface{}, opts ...documentdb.CallOption) (*documentdb.Response, error)
+	UpsertDocument(coll string, doc interface{}, opts ...documentdb.CallOption) (*documentdb.Response, error)
+	DeleteDocument(link string, opts ...documentdb.CallOption) (*documentdb.Response, error)
 }
 
 type credentials struct {
@@ -42,7 +55,7 @@ type CosmosItem struct {
 
 // NewCosmosDBStateStore returns a new CosmosDB state store
 func NewCosmosDBStateStore(logger logger.Logger) *StateStore {
-	return &StateStore{logger: logger}
+	return &StateStore{logger: logger, newClient: newDocumentDB}
 }
 
 // Init does metadata and connection parsing
@@ -59,12 +72,7 @@ func (c *StateStore) Init(metadata state.Metadata) error {
 		return err
 	}
 
-	client := documentdb.New(creds.URL, &documentdb.Config{
-		MasterKey: &documentdb.Key{
-			Key: creds.MasterKey,
-		},
-	})
-
+	client := c.newClient(&creds)
 	dbs, err := client.QueryDatabases(&documentdb.Query{
 		Query: "SELECT * FROM ROOT r WHERE r.id=@id",
 		Parameters: []documentdb.Parameter{
@@ -72,7 +80,7 @@ func (c *StateStore) Init(metadata state.Metadata) error {
 		},
 	})
 	if err != nil {
-		return err
+		return componenterrors.FromAzure(err)
 	} else if len(dbs) == 0 {
 		return fmt.Errorf("database %s for CosmosDB state store not found", creds.Database)
 	}
@@ -85,7 +93,7 @@ func (c *StateStore) Init(metadata state.Metadata) error {
 		},
 	})
 	if err != nil {
-		return err
+		return componenterrors.FromAzure(err)
 	} else if len(colls) == 0 {
 		return fmt.Errorf("collection %s for CosmosDB state store not found", creds.Collection)
 	}
@@ -96,6 +104,14 @@ func (c *StateStore) Init(metadata state.Metadata) error {
 	return nil
 }
 
+func newDocumentDB(creds *credentials) documentDB {
+	return documentdb.New(creds.URL, &documentdb.Config{
+		MasterKey: &documentdb.Key{
+			Key: creds.MasterKey,
+		},
+	})
+}
+
 // Get retrieves a CosmosDB item
 func (c *StateStore) Get(req *state.GetRequest) (*state.GetResponse, error) {
 	key := req.Key
@@ -116,7 +132,7 @@ func (c *StateStore) Get(req *state.GetRequest) (*state.GetResponse, error) {
 		options...,
 	)
 	if err != nil {
-		return nil, err
+		return nil, componenterrors.FromAzure(err)
 	} else if len(items) == 0 {
 		return &state.GetResponse{}, nil
 	}
@@ -154,7 +170,7 @@ func (c *StateStore) Set(req *state.SetRequest) error {
 	_, err = c.client.UpsertDocument(c.collection.Self, CosmosItem{ID: req.Key, Value: req.Value}, options...)
 
 	if err != nil {
-		return err
+		return componenterrors.FromAzure(err)
 	}
 
 	return nil
@@ -194,7 +210,7 @@ func (c *StateStore) Delete(req *state.DeleteRequest) error {
 	}
 
 	_, err = c.client.DeleteDocument(selfLink, options...)
-	return err
+	return componenterrors.FromAzure(err)
 }
 
 // BulkDelete performs a bulk delete operation
@@ -0,0 +1,78 @@
+// ------------------------------------------------------------
+// Copyright (c) Microsoft Corporation.
+// Licensed under the MIT License.
+// ------------------------------------------------------------
+
+package cosmosdb
+
+import (
+	"testing"
+
+	"github.com/a8m/documentdb"
+	"github.com/dapr/components-contrib/componenterrors"
+	"github.com/dapr/components-contrib/state"
+	fakes "github.com/dapr/components-contrib/testing"
+	"github.com/dapr/dapr/pkg/logger"
+	"github.com/stretchr/testify/assert"
+)
+
+func newTestStateStore(t *testing.T, client *fakes.CosmosDB) *StateStore {
+	store := NewCosmosDBStateStore(logger.NewLogger("test"))
+	store.newClient = func(creds *credentials) documentDB {
+		assert.Equal(t, "https://cosmos", creds.URL)
+		return client
+	}
+	return store
+}
+
+func TestInit(t *testing.T) {
+	client := fakes.NewCosmosDB()
+	client.AddCollection("db", "items")
+
+	properties := map[string]string{"url": "https://cosmos", "database": "db", "collection": "items"}
+	assert.Nil(t, newTestStateStore(t, client).Init(state.Metadata{Properties: properties}))
+
+	properties["collection"] = "missing"
+	assert.NotNil(t, newTestStateStore(t, client).Init(state.Metadata{Properties: properties}))
+	properties["database"] = "missing"
+	assert.NotNil(t, newTestStateStore(t, client).Init(state.Metadata{Properties: properties}))
+}
+
+func TestCRUD(t *testing.T) {
+	client := fakes.NewCosmosDB()
+	client.AddCollection("db", "items")
+	store := newTestStateStore(t, client)
+	err := store.Init(state.Metadata{Properties: map[string]string{"url": "https://cosmos", "database": "db", "collection": "items"}})
+	assert.Nil(t, err)
+
+	assert.Nil(t, store.Set(&state.SetRequest{Key: "key", Value: map[string]string{"name": "value"}}))
+	_, partitionKey, ok := client.Document("db", "items", "key")
+	assert.True(t, ok)
+	assert.Equal(t, "key", partitionKey)
+
+	res, err := store.Get(&state.GetRequest{Key: "key"})
+	assert.Nil(t, err)
+	assert.JSONEq(t, `{"name":"value"}`, string(res.Data))
+	assert.NotEmpty(t, res.ETag)
+
+	t.Run("etags are checked", func(t *testing.T) {
+		err := store.Set(&state.SetRequest{Key: "key", Value: "stale", ETag: "\"stale\""})
+		assert.True(t, componenterrors.IsPermanent(err))
+		err = store.Delete(&state.DeleteRequest{Key: "key", ETag: "\"stale\""})
+		assert.True(t, componenterrors.IsPermanent(err))
+		assert.Nil(t, store.Set(&state.SetRequest{Key: "key", Value: "updated", ETag: res.ETag}))
+	})
+
+	t.Run("throttled requests are classified", func(t *testing.T) {
+		client.FailNext("QueryDocuments", &documentdb.RequestError{Code: "TooManyRequests", Message: "request rate is large"})
+		_, err := store.Get(&state.GetRequest{Key: "key"})
+		assert.True(t, componenterrors.IsThrottled(err))
+	})
+
+	t.Run("deleted keys aren't found", func(t *testing.T) {
+		assert.Nil(t, store.Delete(&state.DeleteRequest{Key: "key"}))
+		res, err := store.Get(&state.GetRequest{Key: "key"})
+		assert.Nil(t, err)
+		assert.Nil(t, res.Data)
+	})
+}
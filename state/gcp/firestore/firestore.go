@@ -12,6 +12,7 @@ import (
 	"fmt"
 
 	"cloud.google.com/go/datastore"
+	"github.com/dapr/components-contrib/componenterrors"
 	"github.com/dapr/components-contrib/state"
 	"github.com/dapr/dapr/pkg/logger"
 	jsoniter "github.com/json-iterator/go"
@@ -22,10 +23,20 @@ const defaultEntityKind = "DaprState"
 
 // Firestore State Store
 type Firestore struct {
-	client     *datastore.Client
+	client     datastoreClient
 	entityKind string
 
 	logger logger.Logger
+
+	// newClient creates the client in Init, tests replace it with a fake
+	newClient func(meta *firestoreMetadata) (datastoreClient, error)
+}
+
+// datastoreClient is the subset of the Datastore client used by the state store
+type datastoreClient interface {
+	Get(ctx context.Context, key *datastore.Key, dst interface{}) error
+	Put(ctx context.Context, key *datastore.Key, src interface{}) (*datastore.Key, error)
+	Delete(ctx context.Context, key *datastore.Key) error
 }
 
 type firestoreMetadata struct {
@@ -47,7 +58,7 @@ type StateEntity struct {
 }
 
 func NewFirestoreStateStore(logger logger.Logger) *Firestore {
-	return &Firestore{logger: logger}
+	return &Firestore{logger: logger, newClient: getClient}
 }
 
 // Init does metadata and connection parsing
@@ -56,14 +67,8 @@ func (f *Firestore) Init(metadata state.Metadata) error {
 	if err != nil {
 		return err
 	}
-	b, err := json.Marshal(meta)
-	if err != nil {
-		return err
-	}
 
-	opt := option.WithCredentialsJSON(b)
-	ctx := context.Background()
-	client, err := datastore.NewClient(ctx, meta.ProjectID, opt)
+	client, err := f.newClient(meta)
 	if err != nil {
 		return err
 	}
@@ -73,6 +78,17 @@ func (f *Firestore) Init(metadata state.Metadata) error {
 	return nil
 }
 
+func getClient(meta *firestoreMetadata) (datastoreClient, error) {
+	b, err := json.Marshal(meta)
+	if err != nil {
+		return nil, err
+	}
+
+	opt := option.WithCredentialsJSON(b)
+	ctx := context.Background()
+	return datastore.NewClient(ctx, meta.ProjectID, opt)
+}
+
 // Get retrieves state from Firestore with a key (Always strong consistency)
 func (f *Firestore) Get(req *state.GetRequest) (*state.GetResponse, error) {
 	key := req.Key
@@ -82,7 +98,7 @@ func (f *Firestore) Get(req *state.GetRequest) (*state.GetResponse, error) {
 	err := f.client.Get(context.Background(), entityKey, &entity)
 
 	if err != nil && !errors.Is(err, datastore.ErrNoSuchEntity) {
-		return nil, err
+		return nil, componenterrors.FromGRPC(err)
 	} else if errors.Is(err, datastore.ErrNoSuchEntity) {
 		return &state.GetResponse{}, nil
 	}
@@ -115,7 +131,7 @@ func (f *Firestore) setValue(req *state.SetRequest) error {
 	_, err = f.client.Put(ctx, key, entity)
 
 	if err != nil {
-		return err
+		return componenterrors.FromGRPC(err)
 	}
 	return nil
 }
@@ -143,7 +159,7 @@ func (f *Firestore) deleteValue(req *state.DeleteRequest) error {
 	err := f.client.Delete(ctx, key)
 
 	if err != nil {
-		return err
+		return componenterrors.FromGRPC(err)
 	}
 	return nil
 }
@@ -6,26 +6,37 @@
 package firestore
 
 import (
+	"errors"
 	"testing"
 
+	"cloud.google.com/go/datastore"
+	"github.com/dapr/components-contrib/componenterrors"
 	"github.com/dapr/components-contrib/state"
+	fakes "github.com/dapr/components-contrib/testing"
+	"github.com/dapr/dapr/pkg/logger"
 	"github.com/stretchr/testify/assert"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
 )
 
+func getTestProperties() map[string]string {
+	return map[string]string{
+		"type":                        "service_account",
+		"project_id":                  "myprojectid",
+		"private_key_id":              "123",
+		"private_key":                 "mykey",
+		"client_email":                "me@123.iam.gserviceaccount.com",
+		"client_id":                   "456",
+		"auth_uri":                    "https://accounts.google.com/o/oauth2/auth",
+		"token_uri":                   "https://oauth2.googleapis.com/token",
+		"auth_provider_x509_cert_url": "https://www.googleapis.com/oauth2/v1/certs",
+		"client_x509_cert_url":        "https://www.googleapis.com/robot/v1/metadata/x509/x",
+	}
+}
+
 func TestGetFirestoreMetadata(t *testing.T) {
 	t.Run("With correct properties", func(t *testing.T) {
-		properties := map[string]string{
-			"type":                        "service_account",
-			"project_id":                  "myprojectid",
-			"private_key_id":              "123",
-			"private_key":                 "mykey",
-			"client_email":                "me@123.iam.gserviceaccount.com",
-			"client_id":                   "456",
-			"auth_uri":                    "https://accounts.google.com/o/oauth2/auth",
-			"token_uri":                   "https://oauth2.googleapis.com/token",
-			"auth_provider_x509_cert_url": "https://www.googleapis.com/oauth2/v1/certs",
-			"client_x509_cert_url":        "https://www.googleapis.com/robot/v1/metadata/x509/x",
-		}
+		properties := getTestProperties()
 		m := state.Metadata{
 			Properties: properties,
 		}
@@ -52,3 +63,56 @@ func TestGetFirestoreMetadata(t *testing.T) {
 		assert.NotNil(t, err)
 	})
 }
+
+func TestCRUD(t *testing.T) {
+	client := fakes.NewDatastore()
+	f := NewFirestoreStateStore(logger.NewLogger("test"))
+	f.newClient = func(meta *firestoreMetadata) (datastoreClient, error) {
+		assert.Equal(t, "myprojectid", meta.ProjectID)
+		return client, nil
+	}
+	properties := getTestProperties()
+	properties["entity_kind"] = "Orders"
+	assert.Nil(t, f.Init(state.Metadata{Properties: properties}))
+
+	assert.Nil(t, f.Set(&state.SetRequest{Key: "key", Value: []byte("value")}))
+	var entity StateEntity
+	assert.True(t, client.Entity(datastore.NameKey("Orders", "key", nil), &entity))
+	assert.Equal(t, "value", entity.Value)
+
+	t.Run("values are stored as JSON", func(t *testing.T) {
+		assert.Nil(t, f.Set(&state.SetRequest{Key: "key", Value: map[string]int{"count": 1}}))
+		res, err := f.Get(&state.GetRequest{Key: "key"})
+		assert.Nil(t, err)
+		assert.JSONEq(t, `{"count":1}`, string(res.Data))
+	})
+
+	t.Run("deleted keys aren't found", func(t *testing.T) {
+		assert.Nil(t, f.Delete(&state.DeleteRequest{Key: "key"}))
+		res, err := f.Get(&state.GetRequest{Key: "key"})
+		assert.Nil(t, err)
+		assert.Nil(t, res.Data)
+	})
+
+	t.Run("client errors are returned", func(t *testing.T) {
+		client.FailNext("Put", errors.New("unavailable"))
+		assert.NotNil(t, f.Set(&state.SetRequest{Key: "key", Value: []byte("value")}))
+		client.FailNext("Get", errors.New("unavailable"))
+		_, err := f.Get(&state.GetRequest{Key: "key"})
+		assert.NotNil(t, err)
+	})
+
+	t.Run("client errors are classified", func(t *testing.T) {
+		client.FailNext("Get", status.Error(codes.Unavailable, "unavailable"))
+		_, err := f.Get(&state.GetRequest{Key: "key"})
+		assert.True(t, componenterrors.IsRetriable(err))
+
+		client.FailNext("Put", status.Error(codes.PermissionDenied, "permission denied"))
+		err = f.Set(&state.SetRequest{Key: "key", Value: []byte("value")})
+		assert.True(t, componenterrors.IsUnauthorized(err))
+
+		client.FailNext("Delete", status.Error(codes.InvalidArgument, "invalid key"))
+		err = f.Delete(&state.DeleteRequest{Key: "key"})
+		assert.True(t, componenterrors.IsPermanent(err))
+	})
+}
@@ -0,0 +1,25 @@
+// ------------------------------------------------------------
+// Copyright (c) Microsoft Corporation.
+// Licensed under the MIT License.
+// ------------------------------------------------------------
+
+package state
+
+import "errors"
+
+// ErrNotReconfigurable is returned by Reconfigure for stores that have to be recreated to change their metadata
+var ErrNotReconfigurable = errors.New("state store doesn't support reconfiguration")
+
+// Reconfigurable is a store that can change its metadata, such as a rotated password, while serving requests
+type Reconfigurable interface {
+	Store
+	Reconfigure(metadata Metadata) error
+}
+
+// Reconfigure applies new metadata to a store that implements Reconfigurable, and returns ErrNotReconfigurable otherwise.
+func Reconfigure(store Store, metadata Metadata) error {
+	if r, ok := store.(Reconfigurable); ok {
+		return r.Reconfigure(metadata)
+	}
+	return ErrNotReconfigurable
+}
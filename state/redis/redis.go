@@ -12,8 +12,10 @@ import (
 	"fmt"
 	"strconv"
 	"strings"
+	"sync"
 	"time"
 
+	"github.com/dapr/components-contrib/componenterrors"
 	"github.com/dapr/components-contrib/state"
 	"github.com/dapr/dapr/pkg/logger"
 
@@ -43,6 +45,8 @@ const (
 
 // StateStore is a Redis state store
 type StateStore struct {
+	// lock is held for reading by operations using the client, and for writing while Reconfigure replaces it
+	lock     sync.RWMutex
 	client   *redis.Client
 	json     jsoniter.API
 	metadata metadata
@@ -108,8 +112,47 @@ func (r *StateStore) Init(metadata state.Metadata) error {
 	if err != nil {
 		return err
 	}
+
+	client, replicas, err := r.connect(m)
+	if err != nil {
+		return err
+	}
+	r.metadata = m
+	r.client = client
+	r.replicas = replicas
+	return nil
+}
+
+// Reconfigure connects with new metadata, such as a rotated password, and replaces the client.
+// Operations in flight complete with the previous client before it is closed.
+func (r *StateStore) Reconfigure(metadata state.Metadata) error {
+	m, err := parseRedisMetadata(metadata)
+	if err != nil {
+		return err
+	}
+
+	client, replicas, err := r.connect(m)
+	if err != nil {
+		return err
+	}
+
+	r.lock.Lock()
+	old := r.client
+	r.client = client
 	r.metadata = m
+	r.replicas = replicas
+	r.lock.Unlock()
 
+	if old != nil {
+		if err := old.Close(); err != nil {
+			r.logger.Warnf("redis store: error closing previous client: %s", err)
+		}
+	}
+	return nil
+}
+
+// connect creates a client for m and returns it with the number of connected replicas
+func (r *StateStore) connect(m metadata) (*redis.Client, int, error) {
 	opts := &redis.Options{
 		Addr:            m.host,
 		Password:        m.password,
@@ -125,19 +168,23 @@ func (r *StateStore) Init(metadata state.Metadata) error {
 		}
 	}
 
-	r.client = redis.NewClient(opts)
-	_, err = r.client.Ping().Result()
+	client := redis.NewClient(opts)
+	_, err := client.Ping().Result()
 	if err != nil {
-		return fmt.Errorf("redis store: error connecting to redis at %s: %s", m.host, err)
+		client.Close()
+		return nil, 0, fmt.Errorf("redis store: error connecting to redis at %s: %s", m.host, err)
 	}
 
-	r.replicas, err = r.getConnectedSlaves()
-
-	return err
+	replicas, err := r.getConnectedSlaves(client)
+	if err != nil {
+		client.Close()
+		return nil, 0, err
+	}
+	return client, replicas, nil
 }
 
-func (r *StateStore) getConnectedSlaves() (int, error) {
-	res, err := r.client.DoContext(context.Background(), "INFO", "replication").Result()
+func (r *StateStore) getConnectedSlaves(client *redis.Client) (int, error) {
+	res, err := client.DoContext(context.Background(), "INFO", "replication").Result()
 	if err != nil {
 		return 0, err
 	}
@@ -168,10 +215,16 @@ func (r *StateStore) deleteValue(req *state.DeleteRequest) error {
 	if req.ETag == "" {
 		req.ETag = "0"
 	}
+
+	r.lock.RLock()
+	defer r.lock.RUnlock()
+
 	_, err := r.client.DoContext(context.Background(), "EVAL", delQuery, 1, req.Key, req.ETag).Result()
 
 	if err != nil {
-		return fmt.Errorf("failed to delete key '%s' due to ETag mismatch", req.Key)
+		// An ETag mismatch is an error reply of the script, which is permanent. The message is kept
+		// as callers match on it, and the reply only decides the class.
+		return componenterrors.Newf(componenterrors.ClassOf(componenterrors.FromRedis(err)), "failed to delete key '%s' due to ETag mismatch", req.Key)
 	}
 
 	return nil
@@ -201,7 +254,7 @@ func (r *StateStore) BulkDelete(req []state.DeleteRequest) error {
 func (r *StateStore) directGet(req *state.GetRequest) (*state.GetResponse, error) {
 	res, err := r.client.DoContext(context.Background(), "GET", req.Key).Result()
 	if err != nil {
-		return nil, err
+		return nil, componenterrors.FromRedis(err)
 	}
 
 	if res == nil {
@@ -216,6 +269,9 @@ func (r *StateStore) directGet(req *state.GetRequest) (*state.GetResponse, error
 
 // Get retrieves state from redis with a key
 func (r *StateStore) Get(req *state.GetRequest) (*state.GetResponse, error) {
+	r.lock.RLock()
+	defer r.lock.RUnlock()
+
 	res, err := r.client.DoContext(context.Background(), "HGETALL", req.Key).Result() // Prefer values with ETags
 	if err != nil {
 		return r.directGet(req) //Falls back to original get
@@ -241,11 +297,11 @@ func (r *StateStore) Get(req *state.GetRequest) (*state.GetResponse, error) {
 func (r *StateStore) setValue(req *state.SetRequest) error {
 	err := state.CheckSetRequestOptions(req)
 	if err != nil {
-		return err
+		return componenterrors.Permanent(err)
 	}
 	ver, err := r.parseETag(req.ETag)
 	if err != nil {
-		return err
+		return componenterrors.Permanent(err)
 	}
 
 	if req.Options.Concurrency == state.LastWrite {
@@ -260,15 +316,18 @@ func (r *StateStore) setValue(req *state.SetRequest) error {
 		bt, _ = r.json.Marshal(req.Value)
 	}
 
+	r.lock.RLock()
+	defer r.lock.RUnlock()
+
 	_, err = r.client.DoContext(context.Background(), "EVAL", setQuery, 1, req.Key, ver, bt).Result()
 	if err != nil {
-		return fmt.Errorf("failed to set key %s: %s", req.Key, err)
+		return componenterrors.FromRedis(fmt.Errorf("failed to set key %s: %w", req.Key, err))
 	}
 
 	if req.Options.Consistency == state.Strong && r.replicas > 0 {
 		_, err = r.client.DoContext(context.Background(), "WAIT", r.replicas, 1000).Result()
 		if err != nil {
-			return fmt.Errorf("timed out while waiting for %v replicas to acknowledge write", r.replicas)
+			return componenterrors.Retriable(fmt.Errorf("timed out while waiting for %v replicas to acknowledge write", r.replicas))
 		}
 	}
 
@@ -294,6 +353,9 @@ func (r *StateStore) BulkSet(req []state.SetRequest) error {
 
 // Multi performs a transactional operation. succeeds only if all operations succeed, and fails if one or more operations fail
 func (r *StateStore) Multi(operations []state.TransactionalRequest) error {
+	r.lock.RLock()
+	defer r.lock.RUnlock()
+
 	pipe := r.client.TxPipeline()
 	for _, o := range operations {
 		if o.Operation == state.Upsert {
@@ -307,7 +369,7 @@ func (r *StateStore) Multi(operations []state.TransactionalRequest) error {
 	}
 
 	_, err := pipe.Exec()
-	return err
+	return componenterrors.FromRedis(err)
 }
 
 func (r *StateStore) getKeyVersion(vals []interface{}) (data string, version string, err error) {
@@ -8,6 +8,9 @@ package redis
 import (
 	"testing"
 
+	"github.com/alicebob/miniredis/v2"
+	"github.com/alicebob/miniredis/v2/server"
+	"github.com/dapr/components-contrib/state"
 	"github.com/dapr/dapr/pkg/logger"
 	"github.com/stretchr/testify/assert"
 )
@@ -85,3 +88,46 @@ func TestParseConnectedSlavs(t *testing.T) {
 		assert.Equal(t, 1, slaves, "connected slaves must be 1")
 	})
 }
+
+// runMiniredis starts a miniredis server, closed when the test ends, that answers the replication
+// INFO section, which it doesn't support
+func runMiniredis(t *testing.T) *miniredis.Miniredis {
+	s, err := miniredis.Run()
+	assert.Nil(t, err)
+	t.Cleanup(s.Close)
+	s.Server().SetPreHook(func(c *server.Peer, cmd string, args ...string) bool {
+		if cmd != "INFO" {
+			return false
+		}
+		c.WriteBulk("# Replication\r\nrole:master\r\nconnected_slaves:0\r\n")
+		return true
+	})
+	return s
+}
+
+func TestReconfigure(t *testing.T) {
+	s := runMiniredis(t)
+
+	store := NewRedisStateStore(logger.NewLogger("test"))
+	assert.Nil(t, store.Init(state.Metadata{Properties: map[string]string{host: s.Addr()}}))
+	assert.Nil(t, store.Set(&state.SetRequest{Key: "key", Value: []byte("value")}))
+
+	// The password is rotated on the server
+	s.RequireAuth("rotated")
+
+	t.Run("invalid metadata keeps the current client", func(t *testing.T) {
+		assert.NotNil(t, store.Reconfigure(state.Metadata{Properties: map[string]string{}}))
+		assert.NotNil(t, store.Reconfigure(state.Metadata{Properties: map[string]string{host: s.Addr(), password: "wrong"}}))
+		assert.Equal(t, "", store.metadata.password)
+	})
+
+	t.Run("new password replaces the client", func(t *testing.T) {
+		assert.Nil(t, state.Reconfigure(store, state.Metadata{Properties: map[string]string{host: s.Addr(), password: "rotated"}}))
+		assert.Equal(t, "rotated", store.metadata.password)
+
+		res, err := store.Get(&state.GetRequest{Key: "key"})
+		assert.Nil(t, err)
+		assert.Equal(t, "value", string(res.Data))
+		assert.Nil(t, store.Set(&state.SetRequest{Key: "key", Value: []byte("updated"), ETag: res.ETag}))
+	})
+}
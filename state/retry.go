@@ -8,6 +8,8 @@ package state
 import (
 	"fmt"
 	"time"
+
+	"github.com/dapr/components-contrib/componenterrors"
 )
 
 const (
@@ -51,17 +53,24 @@ func SetWithRetries(method func(req *SetRequest) error, req *SetRequest) error {
 	case Exponential:
 		if req.Options.RetryPolicy.Threshold > 0 {
 			duration := req.Options.RetryPolicy.Interval
+			var err error
 			for i := 0; i < req.Options.RetryPolicy.Threshold; i++ {
-				err := method(req)
+				err = method(req)
 				if err == nil {
 					return nil
 				}
-				time.Sleep(duration)
+				if !componenterrors.ShouldRetry(err) {
+					return err
+				}
+				time.Sleep(retryDelay(duration, err))
 				if req.Options.RetryPolicy.Pattern == Exponential {
 					duration *= 2
 				}
 			}
-			return fmt.Errorf("failed to set value after %d retries", req.Options.RetryPolicy.Threshold)
+			return &retriesExhaustedError{
+				message: fmt.Sprintf("failed to set value after %d retries", req.Options.RetryPolicy.Threshold),
+				err:     err,
+			}
 		}
 		return method(req)
 	default:
@@ -79,20 +88,49 @@ func DeleteWithRetries(method func(req *DeleteRequest) error, req *DeleteRequest
 	case Exponential:
 		if req.Options.RetryPolicy.Threshold > 0 {
 			duration := req.Options.RetryPolicy.Interval
+			var err error
 			for i := 0; i < req.Options.RetryPolicy.Threshold; i++ {
-				err := method(req)
+				err = method(req)
 				if err == nil {
 					return nil
 				}
-				time.Sleep(duration)
+				if !componenterrors.ShouldRetry(err) {
+					return err
+				}
+				time.Sleep(retryDelay(duration, err))
 				if req.Options.RetryPolicy.Pattern == Exponential {
 					duration *= 2
 				}
 			}
-			return fmt.Errorf("failed to set value after %d retries", req.Options.RetryPolicy.Threshold)
+			return &retriesExhaustedError{
+				message: fmt.Sprintf("failed to delete value after %d retries", req.Options.RetryPolicy.Threshold),
+				err:     err,
+			}
 		}
 		return method(req)
 	default:
 		return fmt.Errorf("unrecognized retry patter '%s'", req.Options.RetryPolicy.Pattern)
 	}
 }
+
+// retriesExhaustedError keeps the last error of an operation that ran out of retries
+type retriesExhaustedError struct {
+	message string
+	err     error
+}
+
+func (e *retriesExhaustedError) Error() string {
+	return e.message
+}
+
+func (e *retriesExhaustedError) Unwrap() error {
+	return e.err
+}
+
+// retryDelay returns the retry interval, or the delay a throttled error asks for when it is longer
+func retryDelay(interval time.Duration, err error) time.Duration {
+	if after := componenterrors.RetryAfter(err); after > interval {
+		return after
+	}
+	return interval
+}
@@ -9,6 +9,7 @@ import (
 	"fmt"
 	"testing"
 
+	"github.com/dapr/components-contrib/componenterrors"
 	"github.com/stretchr/testify/assert"
 )
 
@@ -85,4 +86,38 @@ func TestRetryPolicies(t *testing.T) {
 		})
 		assert.Equal(t, 3, counter, "should execute 3 times")
 	})
+
+	t.Run("permanent errors aren't retried", func(t *testing.T) {
+		counter := 0
+		err := SetWithRetries(func(req *SetRequest) error {
+			counter++
+			return componenterrors.Permanent(fmt.Errorf("BAD"))
+		}, &SetRequest{
+			Options: SetStateOption{
+				RetryPolicy: RetryPolicy{
+					Interval:  100,
+					Threshold: 3,
+					Pattern:   "linear",
+				},
+			},
+		})
+		assert.Equal(t, 1, counter, "should execute only once")
+		assert.True(t, componenterrors.IsPermanent(err))
+	})
+
+	t.Run("exhausted retries keep the last error", func(t *testing.T) {
+		err := DeleteWithRetries(func(req *DeleteRequest) error {
+			return componenterrors.Retriable(fmt.Errorf("BAD"))
+		}, &DeleteRequest{
+			Options: DeleteStateOption{
+				RetryPolicy: RetryPolicy{
+					Interval:  100,
+					Threshold: 2,
+					Pattern:   "linear",
+				},
+			},
+		})
+		assert.EqualError(t, err, "failed to delete value after 2 retries")
+		assert.True(t, componenterrors.IsRetriable(err))
+	})
 }
@@ -157,7 +157,7 @@ func TestDelete(t *testing.T) {
 				Interval:  time.Millisecond,
 			}},
 		})
-		assert.EqualError(t, err, "failed to set value after 3 retries")
+		assert.EqualError(t, err, "failed to delete value after 3 retries")
 	})
 }
 
@@ -0,0 +1,129 @@
+// ------------------------------------------------------------
+// Copyright (c) Microsoft Corporation.
+// Licensed under the MIT License.
+// ------------------------------------------------------------
+
+package tenancy
+
+import (
+	"encoding/json"
+	"fmt"
+	"path"
+	"strings"
+
+	"github.com/dapr/components-contrib/bindings"
+	"github.com/dapr/components-contrib/componenterrors"
+	"github.com/google/uuid"
+)
+
+// NewStorageBinding wraps a storage output binding so the objects of a tenant are
+// stored under the <tenant>/ prefix. objectKey is the request metadata key holding
+// the object name, such as fileName. Object names are returned without the prefix,
+// and lists only contain the objects of the tenant.
+func NewStorageBinding(binding bindings.OutputBinding, objectKey string) bindings.OutputBinding {
+	return &storageBinding{binding: binding, objectKey: objectKey}
+}
+
+type storageBinding struct {
+	binding   bindings.OutputBinding
+	objectKey string
+}
+
+// Init initializes the wrapped binding
+func (s *storageBinding) Init(metadata bindings.Metadata) error {
+	return s.binding.Init(metadata)
+}
+
+// Operations returns the operations of the wrapped binding
+func (s *storageBinding) Operations() []bindings.OperationKind {
+	return s.binding.Operations()
+}
+
+// Invoke invokes the wrapped binding on the objects of the tenant of req
+func (s *storageBinding) Invoke(req *bindings.InvokeRequest) (*bindings.InvokeResponse, error) {
+	tenant, err := FromMetadata(req.Metadata)
+	if err != nil {
+		return nil, err
+	}
+	prefix := tenant + "/"
+
+	name := req.Metadata[s.objectKey]
+	if name == "" {
+		switch req.Operation {
+		case bindings.ListOperation:
+			// Lists are filtered in the response
+		case bindings.CreateOperation:
+			// The binding would generate a name outside of the prefix
+			name = uuid.New().String()
+		default:
+			return nil, componenterrors.Permanent(fmt.Errorf("tenancy: missing %s metadata", s.objectKey))
+		}
+	}
+
+	scoped := *req
+	scoped.Metadata = WithTenant(req.Metadata, tenant)
+	if name != "" {
+		// Names such as ../other/file could reach the objects of another tenant once prefixed
+		object := path.Clean(prefix + name)
+		if !strings.HasPrefix(object, prefix) {
+			return nil, crossTenant("object %s of tenant %s is outside of its prefix", name, tenant)
+		}
+		scoped.Metadata[s.objectKey] = object
+	}
+
+	res, err := s.binding.Invoke(&scoped)
+	if err != nil || res == nil {
+		return res, err
+	}
+	return s.scopeResponse(req.Operation, res, prefix)
+}
+
+// scopeResponse removes the tenant prefix from the object names in a response. The objects
+// of other tenants are removed from lists, which have to be JSON arrays of names.
+func (s *storageBinding) scopeResponse(operation bindings.OperationKind, res *bindings.InvokeResponse, prefix string) (*bindings.InvokeResponse, error) {
+	scoped := *res
+	if name, ok := res.Metadata[s.objectKey]; ok {
+		scoped.Metadata = make(map[string]string, len(res.Metadata))
+		for k, v := range res.Metadata {
+			scoped.Metadata[k] = v
+		}
+		scoped.Metadata[s.objectKey] = strings.TrimPrefix(name, prefix)
+	}
+
+	switch operation {
+	case bindings.ListOperation:
+		var names []string
+		if err := json.Unmarshal(res.Data, &names); err != nil {
+			return nil, fmt.Errorf("tenancy: list response isn't a JSON array of names: %s", err)
+		}
+		tenantNames := []string{}
+		for _, name := range names {
+			if strings.HasPrefix(name, prefix) {
+				tenantNames = append(tenantNames, strings.TrimPrefix(name, prefix))
+			}
+		}
+		b, err := json.Marshal(tenantNames)
+		if err != nil {
+			return nil, err
+		}
+		scoped.Data = b
+	case bindings.CreateOperation:
+		// Create responses can name the created object
+		var created map[string]json.RawMessage
+		if json.Unmarshal(res.Data, &created) != nil {
+			break
+		}
+		var name string
+		if raw, ok := created[s.objectKey]; ok && json.Unmarshal(raw, &name) == nil {
+			b, err := json.Marshal(strings.TrimPrefix(name, prefix))
+			if err != nil {
+				return nil, err
+			}
+			created[s.objectKey] = b
+			if scoped.Data, err = json.Marshal(created); err != nil {
+				return nil, err
+			}
+		}
+	}
+	return &scoped, nil
+}
@@ -0,0 +1,71 @@
+// ------------------------------------------------------------
+// Copyright (c) Microsoft Corporation.
+// Licensed under the MIT License.
+// ------------------------------------------------------------
+
+package tenancy
+
+import (
+	"encoding/json"
+	"errors"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/dapr/components-contrib/bindings"
+	"github.com/dapr/components-contrib/bindings/localstorage"
+	"github.com/dapr/dapr/pkg/logger"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestStorageBinding(t *testing.T) {
+	dir, err := ioutil.TempDir("", "tenancy")
+	assert.Nil(t, err)
+	defer os.RemoveAll(dir)
+
+	binding := NewStorageBinding(localstorage.NewLocalStorage(logger.NewLogger("test")), "fileName")
+	assert.Nil(t, binding.Init(bindings.Metadata{Properties: map[string]string{"rootPath": dir}}))
+
+	invoke := func(operation bindings.OperationKind, tenant string, fileName string, data string) (*bindings.InvokeResponse, error) {
+		metadata := tenantMetadata(tenant)
+		if fileName != "" {
+			metadata["fileName"] = fileName
+		}
+		return binding.Invoke(&bindings.InvokeRequest{Operation: operation, Metadata: metadata, Data: []byte(data)})
+	}
+
+	res, err := invoke(bindings.CreateOperation, "acme", "report.txt", "acme")
+	assert.Nil(t, err)
+	assert.JSONEq(t, `{"fileName":"report.txt"}`, string(res.Data))
+	assert.FileExists(t, filepath.Join(dir, "acme", "report.txt"))
+	_, err = invoke(bindings.CreateOperation, "globex", "report.txt", "globex")
+	assert.Nil(t, err)
+
+	t.Run("tenants get their own objects", func(t *testing.T) {
+		res, err := invoke(bindings.GetOperation, "globex", "report.txt", "")
+		assert.Nil(t, err)
+		assert.Equal(t, "globex", string(res.Data))
+	})
+
+	t.Run("created objects without a name are prefixed", func(t *testing.T) {
+		res, err := invoke(bindings.CreateOperation, "acme", "", "generated")
+		assert.Nil(t, err)
+		var created map[string]string
+		assert.Nil(t, json.Unmarshal(res.Data, &created))
+		assert.FileExists(t, filepath.Join(dir, "acme", created["fileName"]))
+	})
+
+	t.Run("lists only contain the objects of the tenant", func(t *testing.T) {
+		res, err := invoke(bindings.ListOperation, "globex", "", "")
+		assert.Nil(t, err)
+		assert.JSONEq(t, `["report.txt"]`, string(res.Data))
+	})
+
+	t.Run("names can't reach the objects of another tenant", func(t *testing.T) {
+		_, err := invoke(bindings.GetOperation, "acme", "../globex/report.txt", "")
+		assert.True(t, errors.Is(err, ErrCrossTenant))
+		_, err = invoke(bindings.DeleteOperation, "acme", "", "")
+		assert.NotNil(t, err)
+	})
+}
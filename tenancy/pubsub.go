@@ -0,0 +1,184 @@
+// ------------------------------------------------------------
+// Copyright (c) Microsoft Corporation.
+// Licensed under the MIT License.
+// ------------------------------------------------------------
+
+package tenancy
+
+import (
+	"encoding/json"
+	"fmt"
+	"sync"
+
+	"github.com/dapr/components-contrib/componenterrors"
+	"github.com/dapr/components-contrib/pubsub"
+)
+
+// PubSubMode is how a tenant scoped pub sub keeps the messages of tenants apart
+type PubSubMode string
+
+const (
+	// TopicSuffix publishes the messages of a tenant to its own topic, named <topic>.<tenant>
+	TopicSuffix PubSubMode = "topicSuffix"
+	// AttributeStamp publishes the messages of all tenants to the shared topic, with the tenant
+	// stamped in the TenantAttribute of their Cloud Events envelope. The tenants subscribed to a
+	// topic share one subscription, which delivers each message to the handler of its tenant.
+	AttributeStamp PubSubMode = "attributeStamp"
+
+	// TopicSeparator separates the topic from the tenant in TopicSuffix mode
+	TopicSeparator = "."
+	// TenantAttribute is the Cloud Events extension attribute holding the tenant in AttributeStamp mode
+	TenantAttribute = "tenantid"
+)
+
+// NewPubSub wraps a pub sub so publish and subscribe requests are scoped to their tenant.
+// The returned pub sub implements pubsub.Reconfigurable when ps does.
+func NewPubSub(ps pubsub.PubSub, mode PubSubMode) pubsub.PubSub {
+	p := &tenantPubSub{pubsub: ps, mode: mode, handlers: map[string]map[string]messageHandler{}}
+	if r, ok := ps.(pubsub.Reconfigurable); ok {
+		return &reconfigurablePubSub{tenantPubSub: p, reconfigurable: r}
+	}
+	return p
+}
+
+type messageHandler func(msg *pubsub.NewMessage) error
+
+type tenantPubSub struct {
+	pubsub pubsub.PubSub
+	mode   PubSubMode
+
+	// lock guards handlers, the handlers of the tenants subscribed to each topic
+	lock     sync.RWMutex
+	handlers map[string]map[string]messageHandler
+}
+
+type reconfigurablePubSub struct {
+	*tenantPubSub
+	reconfigurable pubsub.Reconfigurable
+}
+
+// Reconfigure applies new metadata to the wrapped pub sub, the subscriptions of the tenants are kept
+func (p *reconfigurablePubSub) Reconfigure(metadata pubsub.Metadata) error {
+	return p.reconfigurable.Reconfigure(metadata)
+}
+
+// Init initializes the wrapped pub sub
+func (p *tenantPubSub) Init(metadata pubsub.Metadata) error {
+	if p.mode != TopicSuffix && p.mode != AttributeStamp {
+		return fmt.Errorf("tenancy: unknown pub sub mode %s", p.mode)
+	}
+	return p.pubsub.Init(metadata)
+}
+
+// Publish publishes the message to the topic of its tenant, or stamps its tenant
+func (p *tenantPubSub) Publish(req *pubsub.PublishRequest) error {
+	tenant, err := FromMetadata(req.Metadata)
+	if err != nil {
+		return err
+	}
+
+	scoped := *req
+	if p.mode == TopicSuffix {
+		scoped.Topic = req.Topic + TopicSeparator + tenant
+	} else if scoped.Data, err = stampTenant(req.Data, tenant); err != nil {
+		return err
+	}
+	return p.pubsub.Publish(&scoped)
+}
+
+// Subscribe delivers the messages of the tenant of req to handler. A tenant can subscribe to a topic once.
+func (p *tenantPubSub) Subscribe(req pubsub.SubscribeRequest, handler func(msg *pubsub.NewMessage) error) error {
+	tenant, err := FromMetadata(req.Metadata)
+	if err != nil {
+		return err
+	}
+
+	p.lock.Lock()
+	tenants, subscribed := p.handlers[req.Topic]
+	if _, ok := tenants[tenant]; ok {
+		p.lock.Unlock()
+		return componenterrors.Permanent(fmt.Errorf("tenancy: tenant %s is already subscribed to topic %s", tenant, req.Topic))
+	}
+	if !subscribed {
+		tenants = map[string]messageHandler{}
+		p.handlers[req.Topic] = tenants
+	}
+	tenants[tenant] = handler
+	p.lock.Unlock()
+
+	topic := req.Topic
+	if p.mode == TopicSuffix {
+		req.Topic = topic + TopicSeparator + tenant
+		err = p.pubsub.Subscribe(req, func(msg *pubsub.NewMessage) error {
+			scoped := *msg
+			scoped.Topic = topic
+			return handler(&scoped)
+		})
+	} else if !subscribed {
+		// The messages of all tenants are received by the first subscription to the topic
+		err = p.pubsub.Subscribe(req, func(msg *pubsub.NewMessage) error {
+			return p.dispatch(topic, msg)
+		})
+	}
+
+	if err != nil {
+		p.lock.Lock()
+		delete(tenants, tenant)
+		if len(tenants) == 0 {
+			delete(p.handlers, topic)
+		}
+		p.lock.Unlock()
+	}
+	return err
+}
+
+// dispatch delivers a message of a shared topic to the handler of its tenant. Messages of
+// tenants that aren't subscribed are dropped, their subscribers receive them in their own
+// consumer group.
+func (p *tenantPubSub) dispatch(topic string, msg *pubsub.NewMessage) error {
+	tenant := stampedTenant(msg.Data)
+	if tenant == "" {
+		return componenterrors.Permanent(fmt.Errorf("tenancy: message of topic %s isn't stamped with a tenant", topic))
+	}
+
+	p.lock.RLock()
+	handler, ok := p.handlers[topic][tenant]
+	p.lock.RUnlock()
+	if !ok {
+		return nil
+	}
+	return handler(msg)
+}
+
+// stampTenant sets the tenant attribute of a Cloud Events envelope
+func stampTenant(data []byte, tenant string) ([]byte, error) {
+	// Raw values are kept as they are, so numbers don't lose precision
+	var envelope map[string]json.RawMessage
+	if err := json.Unmarshal(data, &envelope); err != nil || envelope == nil {
+		return nil, componenterrors.Permanent(fmt.Errorf("tenancy: %s mode requires Cloud Events envelopes", AttributeStamp))
+	}
+	if stamped, ok := envelope[TenantAttribute]; ok {
+		var existing string
+		if err := json.Unmarshal(stamped, &existing); err != nil || existing != tenant {
+			return nil, crossTenant("message of tenant %s is stamped with tenant %s", tenant, stamped)
+		}
+	}
+
+	b, err := json.Marshal(tenant)
+	if err != nil {
+		return nil, err
+	}
+	envelope[TenantAttribute] = b
+	return json.Marshal(envelope)
+}
+
+// stampedTenant returns the tenant attribute of a Cloud Events envelope, or an empty string
+func stampedTenant(data []byte) string {
+	var envelope struct {
+		Tenant string `json:"tenantid"`
+	}
+	if err := json.Unmarshal(data, &envelope); err != nil {
+		return ""
+	}
+	return envelope.Tenant
+}
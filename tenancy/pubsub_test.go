@@ -0,0 +1,182 @@
+// ------------------------------------------------------------
+// Copyright (c) Microsoft Corporation.
+// Licensed under the MIT License.
+// ------------------------------------------------------------
+
+package tenancy
+
+import (
+	"encoding/json"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/dapr/components-contrib/pubsub"
+	"github.com/dapr/components-contrib/pubsub/inmemory"
+	"github.com/dapr/dapr/pkg/logger"
+	"github.com/stretchr/testify/assert"
+)
+
+// subscribe subscribes a new consumer group of bus to topic for tenant
+func subscribe(t *testing.T, bus *inmemory.Bus, mode PubSubMode, topic string, tenant string) (pubsub.PubSub, chan *pubsub.NewMessage) {
+	ps := NewPubSub(bus.NewPubSub(logger.NewLogger("test")), mode)
+	assert.Nil(t, ps.Init(pubsub.Metadata{Properties: map[string]string{"consumerID": tenant}}))
+
+	received := make(chan *pubsub.NewMessage, 10)
+	err := ps.Subscribe(pubsub.SubscribeRequest{Topic: topic, Metadata: tenantMetadata(tenant)}, func(msg *pubsub.NewMessage) error {
+		received <- msg
+		return nil
+	})
+	assert.Nil(t, err)
+	return ps, received
+}
+
+func waitForMessage(received chan *pubsub.NewMessage) *pubsub.NewMessage {
+	select {
+	case msg := <-received:
+		return msg
+	case <-time.After(time.Millisecond * 200):
+		return nil
+	}
+}
+
+func TestTopicSuffix(t *testing.T) {
+	bus := inmemory.NewBus()
+	acme, acmeReceived := subscribe(t, bus, TopicSuffix, "orders", "acme")
+	_, globexReceived := subscribe(t, bus, TopicSuffix, "orders", "globex")
+
+	assert.Nil(t, acme.Publish(&pubsub.PublishRequest{Topic: "orders", Data: []byte("order"), Metadata: tenantMetadata("acme")}))
+
+	msg := waitForMessage(acmeReceived)
+	assert.NotNil(t, msg)
+	assert.Equal(t, "orders", msg.Topic)
+	assert.Equal(t, "order", string(msg.Data))
+	assert.Nil(t, waitForMessage(globexReceived))
+
+	// The messages are published to the topic of the tenant
+	raw := bus.NewPubSub(logger.NewLogger("test"))
+	assert.Nil(t, raw.Init(pubsub.Metadata{}))
+	rawReceived := make(chan string, 1)
+	assert.Nil(t, raw.Subscribe(pubsub.SubscribeRequest{Topic: "orders.acme"}, func(msg *pubsub.NewMessage) error {
+		rawReceived <- msg.Topic
+		return nil
+	}))
+	assert.Nil(t, acme.Publish(&pubsub.PublishRequest{Topic: "orders", Data: []byte("order"), Metadata: tenantMetadata("acme")}))
+	assert.Equal(t, "orders.acme", <-rawReceived)
+}
+
+func TestAttributeStamp(t *testing.T) {
+	bus := inmemory.NewBus()
+	acme, acmeReceived := subscribe(t, bus, AttributeStamp, "orders", "acme")
+	_, globexReceived := subscribe(t, bus, AttributeStamp, "orders", "globex")
+
+	envelope := []byte(`{"id":"1","specversion":"0.3","data":{"amount":12345678901234567}}`)
+	assert.Nil(t, acme.Publish(&pubsub.PublishRequest{Topic: "orders", Data: envelope, Metadata: tenantMetadata("acme")}))
+
+	msg := waitForMessage(acmeReceived)
+	assert.NotNil(t, msg)
+	var stamped map[string]json.RawMessage
+	assert.Nil(t, json.Unmarshal(msg.Data, &stamped))
+	assert.Equal(t, `"acme"`, string(stamped[TenantAttribute]))
+	assert.Equal(t, `{"amount":12345678901234567}`, string(stamped["data"]))
+	assert.Nil(t, waitForMessage(globexReceived))
+
+	t.Run("messages stamped with another tenant are rejected", func(t *testing.T) {
+		spoofed := []byte(`{"id":"2","tenantid":"globex"}`)
+		err := acme.Publish(&pubsub.PublishRequest{Topic: "orders", Data: spoofed, Metadata: tenantMetadata("acme")})
+		assert.True(t, errors.Is(err, ErrCrossTenant))
+	})
+
+	t.Run("messages without an envelope are rejected", func(t *testing.T) {
+		err := acme.Publish(&pubsub.PublishRequest{Topic: "orders", Data: []byte("order"), Metadata: tenantMetadata("acme")})
+		assert.NotNil(t, err)
+	})
+}
+
+func TestTenantsSharingAnInstance(t *testing.T) {
+	for _, mode := range []PubSubMode{TopicSuffix, AttributeStamp} {
+		t.Run(string(mode), func(t *testing.T) {
+			ps := NewPubSub(inmemory.NewBus().NewPubSub(logger.NewLogger("test")), mode)
+			assert.Nil(t, ps.Init(pubsub.Metadata{Properties: map[string]string{"consumerID": "app"}}))
+
+			received := map[string]chan string{"acme": make(chan string, 10), "globex": make(chan string, 10)}
+			for tenant, ch := range received {
+				ch := ch
+				err := ps.Subscribe(pubsub.SubscribeRequest{Topic: "orders", Metadata: tenantMetadata(tenant)}, func(msg *pubsub.NewMessage) error {
+					assert.Equal(t, "orders", msg.Topic)
+					ch <- stampedTenant(msg.Data)
+					return nil
+				})
+				assert.Nil(t, err)
+			}
+
+			err := ps.Subscribe(pubsub.SubscribeRequest{Topic: "orders", Metadata: tenantMetadata("acme")}, func(msg *pubsub.NewMessage) error {
+				return nil
+			})
+			assert.NotNil(t, err)
+
+			// Every message reaches the handler of its tenant
+			for i := 0; i < 4; i++ {
+				for _, tenant := range []string{"acme", "globex"} {
+					data := []byte(`{"id":"1","tenantid":"` + tenant + `"}`)
+					assert.Nil(t, ps.Publish(&pubsub.PublishRequest{Topic: "orders", Data: data, Metadata: tenantMetadata(tenant)}))
+				}
+			}
+			for _, tenant := range []string{"acme", "globex"} {
+				for i := 0; i < 4; i++ {
+					select {
+					case got := <-received[tenant]:
+						assert.Equal(t, tenant, got)
+					case <-time.After(time.Second):
+						t.Fatalf("timeout waiting for the messages of %s", tenant)
+					}
+				}
+			}
+		})
+	}
+}
+
+// fakeReconfigurablePubSub records the metadata it is reconfigured with
+type fakeReconfigurablePubSub struct {
+	pubsub.PubSub
+	metadata pubsub.Metadata
+}
+
+func (f *fakeReconfigurablePubSub) Reconfigure(metadata pubsub.Metadata) error {
+	f.metadata = metadata
+	return nil
+}
+
+func TestPubSubReconfigure(t *testing.T) {
+	metadata := pubsub.Metadata{Properties: map[string]string{"password": "rotated"}}
+
+	ps := NewPubSub(inmemory.NewInMemoryPubSub(logger.NewLogger("test")), AttributeStamp)
+	assert.Equal(t, pubsub.ErrNotReconfigurable, pubsub.Reconfigure(ps, metadata))
+
+	fake := &fakeReconfigurablePubSub{PubSub: inmemory.NewInMemoryPubSub(logger.NewLogger("test"))}
+	ps = NewPubSub(fake, AttributeStamp)
+	assert.Nil(t, ps.Init(pubsub.Metadata{}))
+	received := make(chan *pubsub.NewMessage, 1)
+	err := ps.Subscribe(pubsub.SubscribeRequest{Topic: "orders", Metadata: tenantMetadata("acme")}, func(msg *pubsub.NewMessage) error {
+		received <- msg
+		return nil
+	})
+	assert.Nil(t, err)
+
+	assert.Nil(t, pubsub.Reconfigure(ps, metadata))
+	assert.Equal(t, metadata, fake.metadata)
+
+	// Subscriptions are kept
+	err = ps.Publish(&pubsub.PublishRequest{Topic: "orders", Data: []byte(`{"id":"1"}`), Metadata: tenantMetadata("acme")})
+	assert.Nil(t, err)
+	assert.NotNil(t, waitForMessage(received))
+}
+
+func TestPubSubRequiresTenant(t *testing.T) {
+	ps := NewPubSub(inmemory.NewInMemoryPubSub(logger.NewLogger("test")), TopicSuffix)
+	assert.Nil(t, ps.Init(pubsub.Metadata{}))
+	assert.True(t, errors.Is(ps.Publish(&pubsub.PublishRequest{Topic: "orders"}), ErrMissingTenant))
+	assert.True(t, errors.Is(ps.Subscribe(pubsub.SubscribeRequest{Topic: "orders"}, nil), ErrMissingTenant))
+
+	assert.NotNil(t, NewPubSub(inmemory.NewInMemoryPubSub(logger.NewLogger("test")), "unknown").Init(pubsub.Metadata{}))
+}
@@ -0,0 +1,173 @@
+// ------------------------------------------------------------
+// Copyright (c) Microsoft Corporation.
+// Licensed under the MIT License.
+// ------------------------------------------------------------
+
+package tenancy
+
+import (
+	"fmt"
+
+	"github.com/dapr/components-contrib/componenterrors"
+	"github.com/dapr/components-contrib/state"
+)
+
+// KeySeparator separates the tenant from the key in the keys of a tenant scoped state store
+const KeySeparator = "||"
+
+// NewStateStore wraps a state store so the key of each request is prefixed with
+// the tenant of the request. The returned store implements state.TransactionalStore
+// and state.Reconfigurable when store does, and a transaction can only contain
+// requests of a single tenant.
+func NewStateStore(store state.Store) state.Store {
+	s := &stateStore{store: store}
+	r, reconfigurable := store.(state.Reconfigurable)
+	if t, ok := store.(state.TransactionalStore); ok {
+		ts := &transactionalStateStore{stateStore: s, transactional: t}
+		if reconfigurable {
+			return &reconfigurableTransactionalStateStore{transactionalStateStore: ts, reconfigurable: r}
+		}
+		return ts
+	}
+	if reconfigurable {
+		return &reconfigurableStateStore{stateStore: s, reconfigurable: r}
+	}
+	return s
+}
+
+type stateStore struct {
+	store state.Store
+}
+
+type transactionalStateStore struct {
+	*stateStore
+	transactional state.TransactionalStore
+}
+
+type reconfigurableStateStore struct {
+	*stateStore
+	reconfigurable state.Reconfigurable
+}
+
+type reconfigurableTransactionalStateStore struct {
+	*transactionalStateStore
+	reconfigurable state.Reconfigurable
+}
+
+// Init initializes the wrapped store
+func (s *stateStore) Init(metadata state.Metadata) error {
+	return s.store.Init(metadata)
+}
+
+// Reconfigure applies new metadata to the wrapped store
+func (s *reconfigurableStateStore) Reconfigure(metadata state.Metadata) error {
+	return s.reconfigurable.Reconfigure(metadata)
+}
+
+// Reconfigure applies new metadata to the wrapped store
+func (s *reconfigurableTransactionalStateStore) Reconfigure(metadata state.Metadata) error {
+	return s.reconfigurable.Reconfigure(metadata)
+}
+
+// Get retrieves the key of the tenant of req
+func (s *stateStore) Get(req *state.GetRequest) (*state.GetResponse, error) {
+	tenant, err := FromMetadata(req.Metadata)
+	if err != nil {
+		return nil, err
+	}
+
+	scoped := *req
+	scoped.Key = tenant + KeySeparator + req.Key
+	return s.store.Get(&scoped)
+}
+
+// Set saves the key of the tenant of req
+func (s *stateStore) Set(req *state.SetRequest) error {
+	scoped, _, err := scopeSetRequest(*req)
+	if err != nil {
+		return err
+	}
+	return s.store.Set(&scoped)
+}
+
+// Delete deletes the key of the tenant of req
+func (s *stateStore) Delete(req *state.DeleteRequest) error {
+	scoped, _, err := scopeDeleteRequest(*req)
+	if err != nil {
+		return err
+	}
+	return s.store.Delete(&scoped)
+}
+
+// BulkSet saves the keys of the tenants of the requests
+func (s *stateStore) BulkSet(req []state.SetRequest) error {
+	scoped := make([]state.SetRequest, len(req))
+	for i := range req {
+		r, _, err := scopeSetRequest(req[i])
+		if err != nil {
+			return err
+		}
+		scoped[i] = r
+	}
+	return s.store.BulkSet(scoped)
+}
+
+// BulkDelete deletes the keys of the tenants of the requests
+func (s *stateStore) BulkDelete(req []state.DeleteRequest) error {
+	scoped := make([]state.DeleteRequest, len(req))
+	for i := range req {
+		r, _, err := scopeDeleteRequest(req[i])
+		if err != nil {
+			return err
+		}
+		scoped[i] = r
+	}
+	return s.store.BulkDelete(scoped)
+}
+
+// Multi performs the operations of a single tenant in a transaction
+func (s *transactionalStateStore) Multi(reqs []state.TransactionalRequest) error {
+	scoped := make([]state.TransactionalRequest, len(reqs))
+	transactionTenant := ""
+	for i, o := range reqs {
+		var tenant string
+		var err error
+		switch r := o.Request.(type) {
+		case state.SetRequest:
+			scoped[i].Request, tenant, err = scopeSetRequest(r)
+		case state.DeleteRequest:
+			scoped[i].Request, tenant, err = scopeDeleteRequest(r)
+		default:
+			err = componenterrors.Permanent(fmt.Errorf("tenancy: unsupported transactional request %T", o.Request))
+		}
+		if err != nil {
+			return err
+		}
+
+		if transactionTenant == "" {
+			transactionTenant = tenant
+		} else if tenant != transactionTenant {
+			return crossTenant("transaction of tenant %s contains a request of tenant %s", transactionTenant, tenant)
+		}
+		scoped[i].Operation = o.Operation
+	}
+	return s.transactional.Multi(scoped)
+}
+
+func scopeSetRequest(req state.SetRequest) (state.SetRequest, string, error) {
+	tenant, err := FromMetadata(req.Metadata)
+	if err != nil {
+		return req, "", err
+	}
+	req.Key = tenant + KeySeparator + req.Key
+	return req, tenant, nil
+}
+
+func scopeDeleteRequest(req state.DeleteRequest) (state.DeleteRequest, string, error) {
+	tenant, err := FromMetadata(req.Metadata)
+	if err != nil {
+		return req, "", err
+	}
+	req.Key = tenant + KeySeparator + req.Key
+	return req, tenant, nil
+}
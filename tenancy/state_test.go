@@ -0,0 +1,190 @@
+// ------------------------------------------------------------
+// Copyright (c) Microsoft Corporation.
+// Licensed under the MIT License.
+// ------------------------------------------------------------
+
+package tenancy
+
+import (
+	"errors"
+	"sync"
+	"testing"
+
+	"github.com/dapr/components-contrib/componenterrors"
+	"github.com/dapr/components-contrib/state"
+	"github.com/stretchr/testify/assert"
+)
+
+// fakeStore is a transactional state store backed by a map
+type fakeStore struct {
+	lock  sync.Mutex
+	items map[string]interface{}
+}
+
+func newFakeStore() *fakeStore {
+	return &fakeStore{items: map[string]interface{}{}}
+}
+
+func (f *fakeStore) Init(metadata state.Metadata) error {
+	return nil
+}
+
+func (f *fakeStore) Get(req *state.GetRequest) (*state.GetResponse, error) {
+	f.lock.Lock()
+	defer f.lock.Unlock()
+
+	if v, ok := f.items[req.Key]; ok {
+		return &state.GetResponse{Data: v.([]byte)}, nil
+	}
+	return &state.GetResponse{}, nil
+}
+
+func (f *fakeStore) Set(req *state.SetRequest) error {
+	f.lock.Lock()
+	defer f.lock.Unlock()
+
+	f.items[req.Key] = req.Value
+	return nil
+}
+
+func (f *fakeStore) Delete(req *state.DeleteRequest) error {
+	f.lock.Lock()
+	defer f.lock.Unlock()
+
+	delete(f.items, req.Key)
+	return nil
+}
+
+func (f *fakeStore) BulkSet(req []state.SetRequest) error {
+	for i := range req {
+		f.Set(&req[i]) //nolint:errcheck
+	}
+	return nil
+}
+
+func (f *fakeStore) BulkDelete(req []state.DeleteRequest) error {
+	for i := range req {
+		f.Delete(&req[i]) //nolint:errcheck
+	}
+	return nil
+}
+
+func (f *fakeStore) Multi(reqs []state.TransactionalRequest) error {
+	for _, o := range reqs {
+		switch r := o.Request.(type) {
+		case state.SetRequest:
+			f.Set(&r) //nolint:errcheck
+		case state.DeleteRequest:
+			f.Delete(&r) //nolint:errcheck
+		}
+	}
+	return nil
+}
+
+// fakeReconfigurableStore is a store that isn't transactional and records the metadata it is reconfigured with
+type fakeReconfigurableStore struct {
+	state.Store
+	metadata state.Metadata
+}
+
+func (f *fakeReconfigurableStore) Reconfigure(metadata state.Metadata) error {
+	f.metadata = metadata
+	return nil
+}
+
+// fakeReconfigurableTransactionalStore is a transactional store that records the metadata it is reconfigured with
+type fakeReconfigurableTransactionalStore struct {
+	*fakeStore
+	metadata state.Metadata
+}
+
+func (f *fakeReconfigurableTransactionalStore) Reconfigure(metadata state.Metadata) error {
+	f.metadata = metadata
+	return nil
+}
+
+func tenantMetadata(tenant string) map[string]string {
+	return map[string]string{MetadataKey: tenant}
+}
+
+func TestStateStore(t *testing.T) {
+	fake := newFakeStore()
+	store := NewStateStore(fake)
+
+	assert.Nil(t, store.Set(&state.SetRequest{Key: "key", Value: []byte("acme"), Metadata: tenantMetadata("acme")}))
+	assert.Nil(t, store.BulkSet([]state.SetRequest{{Key: "key", Value: []byte("globex"), Metadata: tenantMetadata("globex")}}))
+	assert.Equal(t, []byte("acme"), fake.items["acme||key"])
+	assert.Equal(t, []byte("globex"), fake.items["globex||key"])
+
+	t.Run("tenants read their own keys", func(t *testing.T) {
+		res, err := store.Get(&state.GetRequest{Key: "key", Metadata: tenantMetadata("globex")})
+		assert.Nil(t, err)
+		assert.Equal(t, "globex", string(res.Data))
+	})
+
+	t.Run("keys can't reach the keys of another tenant", func(t *testing.T) {
+		res, err := store.Get(&state.GetRequest{Key: "globex||key", Metadata: tenantMetadata("acme")})
+		assert.Nil(t, err)
+		assert.Nil(t, res.Data)
+
+		_, err = store.Get(&state.GetRequest{Key: "key", Metadata: tenantMetadata("acme||globex")})
+		assert.True(t, errors.Is(err, ErrInvalidTenant))
+	})
+
+	t.Run("requests without a tenant fail", func(t *testing.T) {
+		_, err := store.Get(&state.GetRequest{Key: "key"})
+		assert.True(t, errors.Is(err, ErrMissingTenant))
+		assert.NotNil(t, store.Delete(&state.DeleteRequest{Key: "key"}))
+		assert.NotNil(t, store.BulkDelete([]state.DeleteRequest{{Key: "key"}}))
+	})
+
+	t.Run("requests aren't modified", func(t *testing.T) {
+		req := &state.DeleteRequest{Key: "key", Metadata: tenantMetadata("acme")}
+		assert.Nil(t, store.Delete(req))
+		assert.Equal(t, "key", req.Key)
+		assert.NotContains(t, fake.items, "acme||key")
+		assert.Contains(t, fake.items, "globex||key")
+	})
+}
+
+func TestStateStoreTransactions(t *testing.T) {
+	fake := newFakeStore()
+	store, ok := NewStateStore(fake).(state.TransactionalStore)
+	assert.True(t, ok)
+
+	err := store.Multi([]state.TransactionalRequest{
+		{Operation: state.Upsert, Request: state.SetRequest{Key: "a", Value: []byte("1"), Metadata: tenantMetadata("acme")}},
+		{Operation: state.Delete, Request: state.DeleteRequest{Key: "b", Metadata: tenantMetadata("acme")}},
+	})
+	assert.Nil(t, err)
+	assert.Contains(t, fake.items, "acme||a")
+
+	err = store.Multi([]state.TransactionalRequest{
+		{Operation: state.Upsert, Request: state.SetRequest{Key: "a", Value: []byte("2"), Metadata: tenantMetadata("acme")}},
+		{Operation: state.Upsert, Request: state.SetRequest{Key: "a", Value: []byte("2"), Metadata: tenantMetadata("globex")}},
+	})
+	assert.True(t, errors.Is(err, ErrCrossTenant))
+	assert.True(t, componenterrors.IsUnauthorized(err))
+	assert.Equal(t, []byte("1"), fake.items["acme||a"])
+}
+
+func TestStateStoreReconfigure(t *testing.T) {
+	metadata := state.Metadata{Properties: map[string]string{"password": "rotated"}}
+
+	err := state.Reconfigure(NewStateStore(newFakeStore()), metadata)
+	assert.Equal(t, state.ErrNotReconfigurable, err)
+
+	fake := &fakeReconfigurableStore{Store: newFakeStore()}
+	store := NewStateStore(fake)
+	_, transactional := store.(state.TransactionalStore)
+	assert.False(t, transactional)
+	assert.Nil(t, state.Reconfigure(store, metadata))
+	assert.Equal(t, metadata, fake.metadata)
+
+	transactionalFake := &fakeReconfigurableTransactionalStore{fakeStore: newFakeStore()}
+	store = NewStateStore(transactionalFake)
+	_, transactional = store.(state.TransactionalStore)
+	assert.True(t, transactional)
+	assert.Nil(t, state.Reconfigure(store, metadata))
+	assert.Equal(t, metadata, transactionalFake.metadata)
+}
@@ -0,0 +1,59 @@
+// ------------------------------------------------------------
+// Copyright (c) Microsoft Corporation.
+// Licensed under the MIT License.
+// ------------------------------------------------------------
+
+// Package tenancy isolates the data of tenants sharing a component. Its decorators
+// read the tenant of each request from its metadata and scope the request to it:
+// state keys are prefixed, pub sub topics are suffixed or messages stamped, and
+// storage binding objects are prefixed.
+package tenancy
+
+import (
+	"errors"
+	"fmt"
+	"regexp"
+
+	"github.com/dapr/components-contrib/componenterrors"
+)
+
+// MetadataKey is the request metadata key holding the tenant of a request
+const MetadataKey = "tenantID"
+
+var (
+	// ErrMissingTenant is returned for requests without a tenant
+	ErrMissingTenant = errors.New("tenancy: request has no tenant")
+	// ErrInvalidTenant is returned for tenants that could be confused with the scope of another tenant
+	ErrInvalidTenant = errors.New("tenancy: invalid tenant")
+	// ErrCrossTenant is returned for requests that would access the data of another tenant
+	ErrCrossTenant = errors.New("tenancy: request crosses tenant boundary")
+)
+
+// Tenants are restricted to characters none of the decorators use as separators
+var tenantRegex = regexp.MustCompile(`^[a-zA-Z0-9_-]+$`)
+
+// FromMetadata returns the tenant in the metadata of a request.
+func FromMetadata(metadata map[string]string) (string, error) {
+	tenant := metadata[MetadataKey]
+	if tenant == "" {
+		return "", componenterrors.Permanent(ErrMissingTenant)
+	}
+	if !tenantRegex.MatchString(tenant) {
+		return "", componenterrors.Permanent(fmt.Errorf("%w %q, only letters, digits, '-' and '_' are allowed", ErrInvalidTenant, tenant))
+	}
+	return tenant, nil
+}
+
+// WithTenant returns a copy of metadata with the tenant set.
+func WithTenant(metadata map[string]string, tenant string) map[string]string {
+	result := make(map[string]string, len(metadata)+1)
+	for k, v := range metadata {
+		result[k] = v
+	}
+	result[MetadataKey] = tenant
+	return result
+}
+
+func crossTenant(format string, args ...interface{}) error {
+	return componenterrors.Unauthorized(fmt.Errorf("%w: %s", ErrCrossTenant, fmt.Sprintf(format, args...)))
+}
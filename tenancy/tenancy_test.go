@@ -0,0 +1,36 @@
+// ------------------------------------------------------------
+// Copyright (c) Microsoft Corporation.
+// Licensed under the MIT License.
+// ------------------------------------------------------------
+
+package tenancy
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/dapr/components-contrib/componenterrors"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestFromMetadata(t *testing.T) {
+	tenant, err := FromMetadata(map[string]string{MetadataKey: "acme-1"})
+	assert.Nil(t, err)
+	assert.Equal(t, "acme-1", tenant)
+
+	_, err = FromMetadata(nil)
+	assert.True(t, errors.Is(err, ErrMissingTenant))
+	assert.True(t, componenterrors.IsPermanent(err))
+
+	for _, invalid := range []string{"acme||other", "acme.other", "acme/other", "../acme"} {
+		_, err = FromMetadata(map[string]string{MetadataKey: invalid})
+		assert.True(t, errors.Is(err, ErrInvalidTenant), invalid)
+	}
+}
+
+func TestWithTenant(t *testing.T) {
+	metadata := map[string]string{"key": "value"}
+	scoped := WithTenant(metadata, "acme")
+	assert.Equal(t, map[string]string{"key": "value", MetadataKey: "acme"}, scoped)
+	assert.Equal(t, map[string]string{"key": "value"}, metadata)
+}
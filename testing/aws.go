@@ -0,0 +1,305 @@
+// ------------------------------------------------------------
+// Copyright (c) Microsoft Corporation.
+// Licensed under the MIT License.
+// ------------------------------------------------------------
+
+package testing
+
+import (
+	"fmt"
+	"io/ioutil"
+	"sync"
+	"time"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/aws/awserr"
+	"github.com/aws/aws-sdk-go/service/s3"
+	"github.com/aws/aws-sdk-go/service/s3/s3manager"
+	"github.com/aws/aws-sdk-go/service/s3/s3manager/s3manageriface"
+	"github.com/aws/aws-sdk-go/service/sns"
+	"github.com/aws/aws-sdk-go/service/sns/snsiface"
+	"github.com/aws/aws-sdk-go/service/sqs"
+	"github.com/aws/aws-sdk-go/service/sqs/sqsiface"
+	"github.com/google/uuid"
+)
+
+const (
+	sqsQueueURLPrefix = "https://sqs.fake.amazonaws.com/000000000000/"
+
+	// Defaults
+	defaultVisibilityTimeout = time.Second * 30
+	sqsPollInterval          = time.Millisecond * 10
+)
+
+// SQS is a fake SQS client. Messages that are received but not deleted become
+// visible again after VisibilityTimeout. Methods not used by components panic.
+type SQS struct {
+	sqsiface.SQSAPI
+	Failures
+
+	// VisibilityTimeout defaults to 30 seconds
+	VisibilityTimeout time.Duration
+
+	lock   sync.Mutex
+	queues map[string][]*sqsMessage
+}
+
+type sqsMessage struct {
+	message      *sqs.Message
+	invisibleTil time.Time
+}
+
+var _ sqsiface.SQSAPI = (*SQS)(nil)
+
+// NewSQS returns a fake SQS client with the queues
+func NewSQS(queueNames ...string) *SQS {
+	s := &SQS{VisibilityTimeout: defaultVisibilityTimeout, queues: map[string][]*sqsMessage{}}
+	for _, name := range queueNames {
+		s.queues[sqsQueueURLPrefix+name] = nil
+	}
+	return s
+}
+
+// GetQueueUrl returns the URL of a queue
+func (s *SQS) GetQueueUrl(input *sqs.GetQueueUrlInput) (*sqs.GetQueueUrlOutput, error) { //nolint:golint,stylecheck
+	if err := s.failure("GetQueueUrl"); err != nil {
+		return nil, err
+	}
+
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	url := sqsQueueURLPrefix + aws.StringValue(input.QueueName)
+	if _, ok := s.queues[url]; !ok {
+		return nil, awserr.New(sqs.ErrCodeQueueDoesNotExist, fmt.Sprintf("queue %s does not exist", aws.StringValue(input.QueueName)), nil)
+	}
+	return &sqs.GetQueueUrlOutput{QueueUrl: aws.String(url)}, nil
+}
+
+// SendMessage adds a message to a queue
+func (s *SQS) SendMessage(input *sqs.SendMessageInput) (*sqs.SendMessageOutput, error) {
+	if err := s.failure("SendMessage"); err != nil {
+		return nil, err
+	}
+
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	url := aws.StringValue(input.QueueUrl)
+	messages, ok := s.queues[url]
+	if !ok {
+		return nil, awserr.New(sqs.ErrCodeQueueDoesNotExist, fmt.Sprintf("queue %s does not exist", url), nil)
+	}
+	id := uuid.New().String()
+	s.queues[url] = append(messages, &sqsMessage{message: &sqs.Message{
+		MessageId:         aws.String(id),
+		Body:              input.MessageBody,
+		MessageAttributes: input.MessageAttributes,
+	}})
+	return &sqs.SendMessageOutput{MessageId: aws.String(id)}, nil
+}
+
+// ReceiveMessage waits up to WaitTimeSeconds for visible messages of a queue
+func (s *SQS) ReceiveMessage(input *sqs.ReceiveMessageInput) (*sqs.ReceiveMessageOutput, error) {
+	if err := s.failure("ReceiveMessage"); err != nil {
+		return nil, err
+	}
+
+	max := int(aws.Int64Value(input.MaxNumberOfMessages))
+	if max == 0 {
+		max = 1
+	}
+	visibilityTimeout := s.VisibilityTimeout
+	if input.VisibilityTimeout != nil {
+		visibilityTimeout = time.Second * time.Duration(*input.VisibilityTimeout)
+	}
+
+	deadline := time.Now().Add(time.Second * time.Duration(aws.Int64Value(input.WaitTimeSeconds)))
+	for {
+		messages, err := s.receive(aws.StringValue(input.QueueUrl), max, visibilityTimeout)
+		if err != nil || len(messages) > 0 || !time.Now().Before(deadline) {
+			return &sqs.ReceiveMessageOutput{Messages: messages}, err
+		}
+		time.Sleep(sqsPollInterval)
+	}
+}
+
+func (s *SQS) receive(url string, max int, visibilityTimeout time.Duration) ([]*sqs.Message, error) {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	messages, ok := s.queues[url]
+	if !ok {
+		return nil, awserr.New(sqs.ErrCodeQueueDoesNotExist, fmt.Sprintf("queue %s does not exist", url), nil)
+	}
+
+	now := time.Now()
+	received := []*sqs.Message{}
+	for _, m := range messages {
+		if len(received) == max {
+			break
+		}
+		if m.invisibleTil.After(now) {
+			continue
+		}
+		m.invisibleTil = now.Add(visibilityTimeout)
+		m.message.ReceiptHandle = aws.String(uuid.New().String())
+		copied := *m.message
+		received = append(received, &copied)
+	}
+	return received, nil
+}
+
+// DeleteMessage deletes a received message from a queue
+func (s *SQS) DeleteMessage(input *sqs.DeleteMessageInput) (*sqs.DeleteMessageOutput, error) {
+	if err := s.failure("DeleteMessage"); err != nil {
+		return nil, err
+	}
+
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	url := aws.StringValue(input.QueueUrl)
+	messages := s.queues[url]
+	for i, m := range messages {
+		if aws.StringValue(m.message.ReceiptHandle) == aws.StringValue(input.ReceiptHandle) {
+			s.queues[url] = append(messages[:i:i], messages[i+1:]...)
+			return &sqs.DeleteMessageOutput{}, nil
+		}
+	}
+	return nil, awserr.New(sqs.ErrCodeReceiptHandleIsInvalid, "receipt handle is invalid", nil)
+}
+
+// Send adds a message with body to the queue named queueName
+func (s *SQS) Send(queueName string, body string) {
+	s.SendMessage(&sqs.SendMessageInput{QueueUrl: aws.String(sqsQueueURLPrefix + queueName), MessageBody: aws.String(body)}) //nolint:errcheck
+}
+
+// Messages returns the bodies of the messages in the queue named queueName that haven't been deleted
+func (s *SQS) Messages(queueName string) []string {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	bodies := []string{}
+	for _, m := range s.queues[sqsQueueURLPrefix+queueName] {
+		bodies = append(bodies, aws.StringValue(m.message.Body))
+	}
+	return bodies
+}
+
+// SNS is a fake SNS client recording the messages published to its topics.
+// Methods not used by components panic.
+type SNS struct {
+	snsiface.SNSAPI
+	Failures
+
+	lock      sync.Mutex
+	published map[string][]*sns.PublishInput
+}
+
+var _ snsiface.SNSAPI = (*SNS)(nil)
+
+// NewSNS returns a fake SNS client with the topics
+func NewSNS(topicARNs ...string) *SNS {
+	s := &SNS{published: map[string][]*sns.PublishInput{}}
+	for _, arn := range topicARNs {
+		s.published[arn] = []*sns.PublishInput{}
+	}
+	return s
+}
+
+// Publish records a message published to a topic
+func (s *SNS) Publish(input *sns.PublishInput) (*sns.PublishOutput, error) {
+	if err := s.failure("Publish"); err != nil {
+		return nil, err
+	}
+
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	arn := aws.StringValue(input.TopicArn)
+	published, ok := s.published[arn]
+	if !ok {
+		return nil, awserr.New(sns.ErrCodeNotFoundException, fmt.Sprintf("topic %s does not exist", arn), nil)
+	}
+	s.published[arn] = append(published, input)
+	return &sns.PublishOutput{MessageId: aws.String(uuid.New().String())}, nil
+}
+
+// Published returns the messages published to a topic
+func (s *SNS) Published(topicARN string) []*sns.PublishInput {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	return append([]*sns.PublishInput{}, s.published[topicARN]...)
+}
+
+// S3Uploader is a fake S3 uploader storing the uploaded objects in memory
+type S3Uploader struct {
+	Failures
+
+	lock    sync.Mutex
+	buckets map[string]map[string][]byte
+}
+
+var _ s3manageriface.UploaderAPI = (*S3Uploader)(nil)
+
+// NewS3Uploader returns a fake S3 uploader with the buckets
+func NewS3Uploader(buckets ...string) *S3Uploader {
+	u := &S3Uploader{buckets: map[string]map[string][]byte{}}
+	for _, bucket := range buckets {
+		u.buckets[bucket] = map[string][]byte{}
+	}
+	return u
+}
+
+// Upload stores an object
+func (u *S3Uploader) Upload(input *s3manager.UploadInput, options ...func(*s3manager.Uploader)) (*s3manager.UploadOutput, error) {
+	return u.UploadWithContext(aws.BackgroundContext(), input, options...)
+}
+
+// UploadWithContext stores an object
+func (u *S3Uploader) UploadWithContext(ctx aws.Context, input *s3manager.UploadInput, options ...func(*s3manager.Uploader)) (*s3manager.UploadOutput, error) {
+	if err := u.failure("Upload"); err != nil {
+		return nil, err
+	}
+
+	b, err := ioutil.ReadAll(input.Body)
+	if err != nil {
+		return nil, err
+	}
+
+	u.lock.Lock()
+	defer u.lock.Unlock()
+
+	bucket := aws.StringValue(input.Bucket)
+	objects, ok := u.buckets[bucket]
+	if !ok {
+		return nil, awserr.New(s3.ErrCodeNoSuchBucket, fmt.Sprintf("bucket %s does not exist", bucket), nil)
+	}
+	objects[aws.StringValue(input.Key)] = b
+	return &s3manager.UploadOutput{
+		Location: fmt.Sprintf("https://%s.s3.fake.amazonaws.com/%s", bucket, aws.StringValue(input.Key)),
+	}, nil
+}
+
+// Object returns the content of an uploaded object
+func (u *S3Uploader) Object(bucket string, key string) ([]byte, bool) {
+	u.lock.Lock()
+	defer u.lock.Unlock()
+
+	b, ok := u.buckets[bucket][key]
+	return b, ok
+}
+
+// Objects returns the keys of the objects uploaded to a bucket
+func (u *S3Uploader) Objects(bucket string) []string {
+	u.lock.Lock()
+	defer u.lock.Unlock()
+
+	keys := []string{}
+	for key := range u.buckets[bucket] {
+		keys = append(keys, key)
+	}
+	return keys
+}
@@ -0,0 +1,288 @@
+// ------------------------------------------------------------
+// Copyright (c) Microsoft Corporation.
+// Licensed under the MIT License.
+// ------------------------------------------------------------
+
+package testing
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/a8m/documentdb"
+)
+
+// CosmosDB is a fake CosmosDB client storing documents in memory. Queries are limited
+// to lookups by id: queries with an @id parameter select the resources with that id,
+// and queries without parameters select all of them. Documents are written with an
+// etag, and the If-Match option is honoured.
+type CosmosDB struct {
+	Failures
+
+	lock      sync.Mutex
+	databases map[string]map[string]map[string]*cosmosDocument
+	etag      int
+}
+
+type cosmosDocument struct {
+	body         map[string]interface{}
+	partitionKey interface{}
+}
+
+// NewCosmosDB returns a fake CosmosDB client without databases
+func NewCosmosDB() *CosmosDB {
+	return &CosmosDB{databases: map[string]map[string]map[string]*cosmosDocument{}}
+}
+
+// AddCollection adds a collection, and its database if needed
+func (c *CosmosDB) AddCollection(database string, collection string) {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+
+	if c.databases[database] == nil {
+		c.databases[database] = map[string]map[string]*cosmosDocument{}
+	}
+	c.databases[database][collection] = map[string]*cosmosDocument{}
+}
+
+// QueryDatabases returns the databases selected by query
+func (c *CosmosDB) QueryDatabases(query *documentdb.Query, opts ...documentdb.CallOption) (documentdb.Databases, error) {
+	if err := c.failure("QueryDatabases"); err != nil {
+		return nil, err
+	}
+
+	c.lock.Lock()
+	defer c.lock.Unlock()
+
+	dbs := documentdb.Databases{}
+	for id := range c.databases {
+		if selectsID(query, id) {
+			self := "dbs/" + id + "/"
+			dbs = append(dbs, documentdb.Database{Resource: documentdb.Resource{Id: id, Self: self}, Colls: self + "colls/"})
+		}
+	}
+	return dbs, nil
+}
+
+// QueryCollections returns the collections of the database selected by query
+func (c *CosmosDB) QueryCollections(db string, query *documentdb.Query, opts ...documentdb.CallOption) ([]documentdb.Collection, error) {
+	if err := c.failure("QueryCollections"); err != nil {
+		return nil, err
+	}
+
+	c.lock.Lock()
+	defer c.lock.Unlock()
+
+	database := linkParts(db)
+	if len(database) != 2 || c.databases[database[1]] == nil {
+		return nil, cosmosError("NotFound", "database %s does not exist", db)
+	}
+	colls := []documentdb.Collection{}
+	for id := range c.databases[database[1]] {
+		if selectsID(query, id) {
+			self := fmt.Sprintf("dbs/%s/colls/%s/", database[1], id)
+			colls = append(colls, documentdb.Collection{Resource: documentdb.Resource{Id: id, Self: self}, Docs: self + "docs/"})
+		}
+	}
+	return colls, nil
+}
+
+// QueryDocuments unmarshals the documents of the collection selected by query into docs
+func (c *CosmosDB) QueryDocuments(coll string, query *documentdb.Query, docs interface{}, opts ...documentdb.CallOption) (*documentdb.Response, error) {
+	if err := c.failure("QueryDocuments"); err != nil {
+		return nil, err
+	}
+	if _, err := cosmosHeaders(opts); err != nil {
+		return nil, err
+	}
+
+	c.lock.Lock()
+	defer c.lock.Unlock()
+
+	documents, err := c.collection(coll)
+	if err != nil {
+		return nil, err
+	}
+	selected := []map[string]interface{}{}
+	for id, doc := range documents {
+		if selectsID(query, id) {
+			selected = append(selected, doc.body)
+		}
+	}
+
+	b, err := json.Marshal(selected)
+	if err != nil {
+		return nil, err
+	}
+	return &documentdb.Response{Header: http.Header{}}, json.Unmarshal(b, docs)
+}
+
+// CreateDocument adds a document to a collection, it fails if the document exists
+func (c *CosmosDB) CreateDocument(coll string, doc interface{}, opts ...documentdb.CallOption) (*documentdb.Response, error) {
+	if err := c.failure("CreateDocument"); err != nil {
+		return nil, err
+	}
+	return c.write(coll, doc, false, opts)
+}
+
+// UpsertDocument adds or replaces a document of a collection
+func (c *CosmosDB) UpsertDocument(coll string, doc interface{}, opts ...documentdb.CallOption) (*documentdb.Response, error) {
+	if err := c.failure("UpsertDocument"); err != nil {
+		return nil, err
+	}
+	return c.write(coll, doc, true, opts)
+}
+
+func (c *CosmosDB) write(coll string, doc interface{}, upsert bool, opts []documentdb.CallOption) (*documentdb.Response, error) {
+	header, err := cosmosHeaders(opts)
+	if err != nil {
+		return nil, err
+	}
+	b, err := json.Marshal(doc)
+	if err != nil {
+		return nil, err
+	}
+	var body map[string]interface{}
+	if err = json.Unmarshal(b, &body); err != nil {
+		return nil, err
+	}
+	id, ok := body["id"].(string)
+	if !ok || id == "" {
+		return nil, cosmosError("BadRequest", "document has no id")
+	}
+
+	c.lock.Lock()
+	defer c.lock.Unlock()
+
+	documents, err := c.collection(coll)
+	if err != nil {
+		return nil, err
+	}
+	existing, exists := documents[id]
+	if exists && !upsert {
+		return nil, cosmosError("Conflict", "document %s exists", id)
+	}
+	if err = checkIfMatch(header, existing); err != nil {
+		return nil, err
+	}
+
+	c.etag++
+	etag := strconv.Quote(strconv.Itoa(c.etag))
+	body["_etag"] = etag
+	body["_self"] = coll + "docs/" + id + "/"
+	body["_ts"] = time.Now().Unix()
+	documents[id] = &cosmosDocument{body: body, partitionKey: partitionKey(header)}
+	return &documentdb.Response{Header: http.Header{"Etag": []string{etag}}}, nil
+}
+
+// DeleteDocument deletes the document with the link dbs/{db}/colls/{coll}/docs/{id}
+func (c *CosmosDB) DeleteDocument(link string, opts ...documentdb.CallOption) (*documentdb.Response, error) {
+	if err := c.failure("DeleteDocument"); err != nil {
+		return nil, err
+	}
+	header, err := cosmosHeaders(opts)
+	if err != nil {
+		return nil, err
+	}
+
+	c.lock.Lock()
+	defer c.lock.Unlock()
+
+	parts := linkParts(link)
+	if len(parts) != 6 {
+		return nil, cosmosError("BadRequest", "invalid document link %s", link)
+	}
+	documents, err := c.collection(strings.Join(parts[:4], "/"))
+	if err != nil {
+		return nil, err
+	}
+	existing, ok := documents[parts[5]]
+	if !ok {
+		return nil, cosmosError("NotFound", "document %s does not exist", link)
+	}
+	if err = checkIfMatch(header, existing); err != nil {
+		return nil, err
+	}
+	delete(documents, parts[5])
+	return &documentdb.Response{Header: http.Header{}}, nil
+}
+
+// Document returns a document of a collection and the partition key it was written with
+func (c *CosmosDB) Document(database string, collection string, id string) (map[string]interface{}, interface{}, bool) {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+
+	doc, ok := c.databases[database][collection][id]
+	if !ok {
+		return nil, nil, false
+	}
+	return doc.body, doc.partitionKey, true
+}
+
+// collection returns the documents of the collection with the link dbs/{db}/colls/{coll}
+func (c *CosmosDB) collection(link string) (map[string]*cosmosDocument, error) {
+	parts := linkParts(link)
+	if len(parts) != 4 || c.databases[parts[1]][parts[3]] == nil {
+		return nil, cosmosError("NotFound", "collection %s does not exist", link)
+	}
+	return c.databases[parts[1]][parts[3]], nil
+}
+
+func linkParts(link string) []string {
+	return strings.Split(strings.Trim(link, "/"), "/")
+}
+
+func selectsID(query *documentdb.Query, id string) bool {
+	if query == nil {
+		return true
+	}
+	for _, p := range query.Parameters {
+		if p.Name == "@id" {
+			return p.Value == id
+		}
+	}
+	return true
+}
+
+// cosmosHeaders returns the headers set by the options of a call
+func cosmosHeaders(opts []documentdb.CallOption) (http.Header, error) {
+	r := &documentdb.Request{Request: &http.Request{Header: http.Header{}}}
+	for _, opt := range opts {
+		if err := opt(r); err != nil {
+			return nil, err
+		}
+	}
+	return r.Header, nil
+}
+
+func checkIfMatch(header http.Header, existing *cosmosDocument) error {
+	etag := header.Get(documentdb.HeaderIfMatch)
+	if etag == "" {
+		return nil
+	}
+	if existing == nil || existing.body["_etag"] != etag {
+		return cosmosError("PreconditionFailed", "etag %s does not match", etag)
+	}
+	return nil
+}
+
+func partitionKey(header http.Header) interface{} {
+	values, ok := header[documentdb.HeaderPartitionKey]
+	if !ok || len(values) == 0 {
+		return nil
+	}
+	var keys []interface{}
+	if json.Unmarshal([]byte(values[0]), &keys) != nil || len(keys) == 0 {
+		return nil
+	}
+	return keys[0]
+}
+
+func cosmosError(code string, format string, args ...interface{}) error {
+	return &documentdb.RequestError{Code: code, Message: fmt.Sprintf(format, args...)}
+}
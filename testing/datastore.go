@@ -0,0 +1,83 @@
+// ------------------------------------------------------------
+// Copyright (c) Microsoft Corporation.
+// Licensed under the MIT License.
+// ------------------------------------------------------------
+
+package testing
+
+import (
+	"context"
+	"encoding/json"
+	"sync"
+
+	"cloud.google.com/go/datastore"
+)
+
+// Datastore is a fake Datastore client, as used by the Firestore state store. Entities
+// are stored in memory as JSON, so only their exported fields are kept.
+type Datastore struct {
+	Failures
+
+	lock     sync.Mutex
+	entities map[string][]byte
+}
+
+// NewDatastore returns an empty fake Datastore client
+func NewDatastore() *Datastore {
+	return &Datastore{entities: map[string][]byte{}}
+}
+
+// Get loads the entity of key into dst
+func (d *Datastore) Get(ctx context.Context, key *datastore.Key, dst interface{}) error {
+	if err := d.failure("Get"); err != nil {
+		return err
+	}
+
+	d.lock.Lock()
+	b, ok := d.entities[key.String()]
+	d.lock.Unlock()
+	if !ok {
+		return datastore.ErrNoSuchEntity
+	}
+	return json.Unmarshal(b, dst)
+}
+
+// Put saves src as the entity of key
+func (d *Datastore) Put(ctx context.Context, key *datastore.Key, src interface{}) (*datastore.Key, error) {
+	if err := d.failure("Put"); err != nil {
+		return nil, err
+	}
+
+	b, err := json.Marshal(src)
+	if err != nil {
+		return nil, err
+	}
+
+	d.lock.Lock()
+	defer d.lock.Unlock()
+
+	d.entities[key.String()] = b
+	return key, nil
+}
+
+// Delete deletes the entity of key, deleting a missing entity succeeds
+func (d *Datastore) Delete(ctx context.Context, key *datastore.Key) error {
+	if err := d.failure("Delete"); err != nil {
+		return err
+	}
+
+	d.lock.Lock()
+	defer d.lock.Unlock()
+
+	delete(d.entities, key.String())
+	return nil
+}
+
+// Entity loads the entity of key into dst, and returns false if it doesn't exist
+func (d *Datastore) Entity(key *datastore.Key, dst interface{}) bool {
+	d.lock.Lock()
+	b, ok := d.entities[key.String()]
+	d.lock.Unlock()
+
+	return ok && json.Unmarshal(b, dst) == nil
+}
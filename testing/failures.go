@@ -0,0 +1,42 @@
+// ------------------------------------------------------------
+// Copyright (c) Microsoft Corporation.
+// Licensed under the MIT License.
+// ------------------------------------------------------------
+
+// Package testing provides in-memory fakes of the AWS, Azure and GCP clients used by
+// components, so the logic of the components can be unit tested without the cloud.
+// Components take the fakes through their client interfaces.
+package testing
+
+import "sync"
+
+// Failures makes the methods of a fake return errors. It is embedded in every fake.
+type Failures struct {
+	lock sync.Mutex
+	errs map[string][]error
+}
+
+// FailNext makes the next call of the method return err. Calling it again queues
+// more errors, which are returned by the following calls.
+func (f *Failures) FailNext(method string, err error) {
+	f.lock.Lock()
+	defer f.lock.Unlock()
+
+	if f.errs == nil {
+		f.errs = map[string][]error{}
+	}
+	f.errs[method] = append(f.errs[method], err)
+}
+
+// failure returns the next error queued for method, or nil
+func (f *Failures) failure(method string) error {
+	f.lock.Lock()
+	defer f.lock.Unlock()
+
+	errs := f.errs[method]
+	if len(errs) == 0 {
+		return nil
+	}
+	f.errs[method] = errs[1:]
+	return errs[0]
+}
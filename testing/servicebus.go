@@ -0,0 +1,370 @@
+// ------------------------------------------------------------
+// Copyright (c) Microsoft Corporation.
+// Licensed under the MIT License.
+// ------------------------------------------------------------
+
+package testing
+
+import (
+	"context"
+	"fmt"
+	"sync"
+
+	servicebus "github.com/Azure/azure-service-bus-go"
+	"github.com/google/uuid"
+)
+
+// serviceBus holds the messages delivered by the Service Bus fakes until they are settled.
+// Abandoned messages are delivered again.
+type serviceBus struct {
+	Failures
+
+	lock      sync.Mutex
+	inFlight  map[*servicebus.Message]*serviceBusEntity
+	completed []*servicebus.Message
+	abandoned []*servicebus.Message
+}
+
+// serviceBusEntity is a queue or a topic subscription
+type serviceBusEntity struct {
+	pending []*servicebus.Message
+	// available is closed when a message is added
+	available chan struct{}
+}
+
+func newServiceBusEntity() *serviceBusEntity {
+	return &serviceBusEntity{available: make(chan struct{})}
+}
+
+// add adds a message to an entity, the lock must be held
+func (e *serviceBusEntity) add(msg *servicebus.Message) {
+	e.pending = append(e.pending, msg)
+	close(e.available)
+	e.available = make(chan struct{})
+}
+
+// receive waits for the next message of an entity and passes it to handler
+func (s *serviceBus) receive(ctx context.Context, e *serviceBusEntity, handler servicebus.Handler) error {
+	for {
+		s.lock.Lock()
+		if len(e.pending) > 0 {
+			msg := e.pending[0]
+			e.pending = e.pending[1:]
+			s.inFlight[msg] = e
+			s.lock.Unlock()
+			return handler.Handle(ctx, msg)
+		}
+		available := e.available
+		s.lock.Unlock()
+
+		select {
+		case <-available:
+		case <-ctx.Done():
+			return ctx.Err()
+		}
+	}
+}
+
+// Complete settles a received message
+func (s *serviceBus) Complete(ctx context.Context, msg *servicebus.Message) error {
+	if err := s.failure("Complete"); err != nil {
+		return err
+	}
+
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	if _, ok := s.inFlight[msg]; !ok {
+		return fmt.Errorf("message %s isn't in flight", msg.ID)
+	}
+	delete(s.inFlight, msg)
+	s.completed = append(s.completed, msg)
+	return nil
+}
+
+// Abandon releases a received message, which is delivered again
+func (s *serviceBus) Abandon(ctx context.Context, msg *servicebus.Message) error {
+	if err := s.failure("Abandon"); err != nil {
+		return err
+	}
+
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	e, ok := s.inFlight[msg]
+	if !ok {
+		return fmt.Errorf("message %s isn't in flight", msg.ID)
+	}
+	delete(s.inFlight, msg)
+	s.abandoned = append(s.abandoned, msg)
+	e.add(msg)
+	return nil
+}
+
+// Completed returns the completed messages
+func (s *serviceBus) Completed() []*servicebus.Message {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	return append([]*servicebus.Message{}, s.completed...)
+}
+
+// Abandoned returns the abandoned messages, a message is returned once per abandon
+func (s *serviceBus) Abandoned() []*servicebus.Message {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	return append([]*servicebus.Message{}, s.abandoned...)
+}
+
+// ServiceBusQueue is a fake Service Bus queue
+type ServiceBusQueue struct {
+	serviceBus
+
+	queue *serviceBusEntity
+}
+
+// NewServiceBusQueue returns an empty fake Service Bus queue
+func NewServiceBusQueue() *ServiceBusQueue {
+	return &ServiceBusQueue{
+		serviceBus: serviceBus{inFlight: map[*servicebus.Message]*serviceBusEntity{}},
+		queue:      newServiceBusEntity(),
+	}
+}
+
+// Send adds a message to the queue
+func (q *ServiceBusQueue) Send(ctx context.Context, msg *servicebus.Message) error {
+	if err := q.failure("Send"); err != nil {
+		return err
+	}
+
+	q.lock.Lock()
+	defer q.lock.Unlock()
+
+	if msg.ID == "" {
+		msg.ID = uuid.New().String()
+	}
+	q.queue.add(msg)
+	return nil
+}
+
+// Receive passes the messages of the queue to handler until ctx is done or handler fails
+func (q *ServiceBusQueue) Receive(ctx context.Context, handler servicebus.Handler) error {
+	for {
+		if err := q.failure("Receive"); err != nil {
+			return err
+		}
+		if err := q.receive(ctx, q.queue, handler); err != nil {
+			return err
+		}
+	}
+}
+
+// Messages returns the messages waiting in the queue
+func (q *ServiceBusQueue) Messages() []*servicebus.Message {
+	q.lock.Lock()
+	defer q.lock.Unlock()
+
+	return append([]*servicebus.Message{}, q.queue.pending...)
+}
+
+// ServiceBusNamespace is a fake Service Bus namespace with topics and subscriptions.
+// Get methods return nil entities for missing topics and subscriptions.
+type ServiceBusNamespace struct {
+	serviceBus
+
+	topics map[string]*serviceBusTopic
+}
+
+type serviceBusTopic struct {
+	subscriptions map[string]*serviceBusSubscription
+}
+
+type serviceBusSubscription struct {
+	*serviceBusEntity
+	description  *servicebus.SubscriptionDescription
+	renewedLocks int
+}
+
+// NewServiceBusNamespace returns a fake Service Bus namespace without topics
+func NewServiceBusNamespace() *ServiceBusNamespace {
+	return &ServiceBusNamespace{
+		serviceBus: serviceBus{inFlight: map[*servicebus.Message]*serviceBusEntity{}},
+		topics:     map[string]*serviceBusTopic{},
+	}
+}
+
+// GetTopic returns a topic, or nil if it doesn't exist
+func (n *ServiceBusNamespace) GetTopic(ctx context.Context, topic string) (*servicebus.TopicEntity, error) {
+	if err := n.failure("GetTopic"); err != nil {
+		return nil, err
+	}
+
+	n.lock.Lock()
+	defer n.lock.Unlock()
+
+	if _, ok := n.topics[topic]; !ok {
+		return nil, nil
+	}
+	return &servicebus.TopicEntity{}, nil
+}
+
+// PutTopic creates a topic
+func (n *ServiceBusNamespace) PutTopic(ctx context.Context, topic string) (*servicebus.TopicEntity, error) {
+	if err := n.failure("PutTopic"); err != nil {
+		return nil, err
+	}
+
+	n.lock.Lock()
+	defer n.lock.Unlock()
+
+	if _, ok := n.topics[topic]; !ok {
+		n.topics[topic] = &serviceBusTopic{subscriptions: map[string]*serviceBusSubscription{}}
+	}
+	return &servicebus.TopicEntity{}, nil
+}
+
+// GetSubscription returns a subscription, or nil if it doesn't exist
+func (n *ServiceBusNamespace) GetSubscription(ctx context.Context, topic string, subscription string) (*servicebus.SubscriptionEntity, error) {
+	if err := n.failure("GetSubscription"); err != nil {
+		return nil, err
+	}
+
+	n.lock.Lock()
+	defer n.lock.Unlock()
+
+	if s, _ := n.subscription(topic, subscription); s == nil {
+		return nil, nil
+	}
+	return &servicebus.SubscriptionEntity{}, nil
+}
+
+// PutSubscription creates a subscription of an existing topic
+func (n *ServiceBusNamespace) PutSubscription(ctx context.Context, topic string, subscription string, opts ...servicebus.SubscriptionManagementOption) (*servicebus.SubscriptionEntity, error) {
+	if err := n.failure("PutSubscription"); err != nil {
+		return nil, err
+	}
+
+	description := &servicebus.SubscriptionDescription{}
+	for _, opt := range opts {
+		if err := opt(description); err != nil {
+			return nil, err
+		}
+	}
+
+	n.lock.Lock()
+	defer n.lock.Unlock()
+
+	t, ok := n.topics[topic]
+	if !ok {
+		return nil, fmt.Errorf("topic %s does not exist", topic)
+	}
+	if s, ok := t.subscriptions[subscription]; ok {
+		s.description = description
+	} else {
+		t.subscriptions[subscription] = &serviceBusSubscription{serviceBusEntity: newServiceBusEntity(), description: description}
+	}
+	return &servicebus.SubscriptionEntity{}, nil
+}
+
+// Send adds a copy of a message to every subscription of a topic
+func (n *ServiceBusNamespace) Send(ctx context.Context, topic string, msg *servicebus.Message) error {
+	if err := n.failure("Send"); err != nil {
+		return err
+	}
+
+	n.lock.Lock()
+	defer n.lock.Unlock()
+
+	t, ok := n.topics[topic]
+	if !ok {
+		return fmt.Errorf("topic %s does not exist", topic)
+	}
+	if msg.ID == "" {
+		msg.ID = uuid.New().String()
+	}
+	for _, s := range t.subscriptions {
+		copied := servicebus.NewMessage(msg.Data)
+		copied.ID = msg.ID
+		s.add(copied)
+	}
+	return nil
+}
+
+// OpenSubscription opens the receiver of a subscription, the subscription doesn't have to exist yet
+func (n *ServiceBusNamespace) OpenSubscription(topic string, subscription string) error {
+	return n.failure("OpenSubscription")
+}
+
+// ReceiveOne waits for the next message of a subscription and passes it to handler
+func (n *ServiceBusNamespace) ReceiveOne(ctx context.Context, topic string, subscription string, handler servicebus.Handler) error {
+	if err := n.failure("ReceiveOne"); err != nil {
+		return err
+	}
+
+	n.lock.Lock()
+	s, err := n.subscription(topic, subscription)
+	n.lock.Unlock()
+	if err != nil {
+		return err
+	}
+	return n.receive(ctx, s.serviceBusEntity, handler)
+}
+
+// RenewLocks counts the lock renewals of a subscription
+func (n *ServiceBusNamespace) RenewLocks(ctx context.Context, topic string, subscription string, msgs ...*servicebus.Message) error {
+	if err := n.failure("RenewLocks"); err != nil {
+		return err
+	}
+
+	n.lock.Lock()
+	defer n.lock.Unlock()
+
+	s, err := n.subscription(topic, subscription)
+	if err != nil {
+		return err
+	}
+	for _, msg := range msgs {
+		if _, ok := n.inFlight[msg]; !ok {
+			return fmt.Errorf("message %s isn't in flight", msg.ID)
+		}
+	}
+	s.renewedLocks += len(msgs)
+	return nil
+}
+
+// CloseSubscription closes the receiver of a subscription, it is reopened by the next receive
+func (n *ServiceBusNamespace) CloseSubscription(ctx context.Context, topic string, subscription string) error {
+	return n.failure("CloseSubscription")
+}
+
+// AddSubscription creates a topic and a subscription
+func (n *ServiceBusNamespace) AddSubscription(topic string, subscription string) {
+	n.PutTopic(context.Background(), topic)                      //nolint:errcheck
+	n.PutSubscription(context.Background(), topic, subscription) //nolint:errcheck
+}
+
+// Subscription returns the description of a subscription and the number of renewed message locks
+func (n *ServiceBusNamespace) Subscription(topic string, subscription string) (*servicebus.SubscriptionDescription, int, bool) {
+	n.lock.Lock()
+	defer n.lock.Unlock()
+
+	s, err := n.subscription(topic, subscription)
+	if err != nil {
+		return nil, 0, false
+	}
+	return s.description, s.renewedLocks, true
+}
+
+// subscription returns a subscription, the lock must be held
+func (n *ServiceBusNamespace) subscription(topic string, subscription string) (*serviceBusSubscription, error) {
+	t, ok := n.topics[topic]
+	if !ok {
+		return nil, fmt.Errorf("topic %s does not exist", topic)
+	}
+	s, ok := t.subscriptions[subscription]
+	if !ok {
+		return nil, fmt.Errorf("subscription %s of topic %s does not exist", subscription, topic)
+	}
+	return s, nil
+}